@@ -28,9 +28,12 @@ type Filler struct {
 	tasks                []string
 	done                 chan struct{}
 	report               *schedule.Reporter
+	budget               *schedule.TaskBudget
 }
 
-func NewFiller(node lens.API, db *storage.Database, name string, minHeight, maxHeight int64, tasks []string, r *schedule.Reporter) *Filler {
+// NewFiller returns a Filler that indexes the gaps found between `minHeight` and `maxHeight`. If `budget` is not nil
+// the tasks of the filler are executed within it.
+func NewFiller(node lens.API, db *storage.Database, name string, minHeight, maxHeight int64, tasks []string, r *schedule.Reporter, budget *schedule.TaskBudget) *Filler {
 	return &Filler{
 		DB:        db,
 		node:      node,
@@ -39,6 +42,7 @@ func NewFiller(node lens.API, db *storage.Database, name string, minHeight, maxH
 		minHeight: minHeight,
 		tasks:     tasks,
 		report:    r,
+		budget:    budget,
 	}
 }
 
@@ -59,7 +63,11 @@ func (g *Filler) Run(ctx context.Context) error {
 		return err
 	}
 
-	index, err := integrated.NewManager(g.DB, tipset.NewBuilder(taskAPI, g.name))
+	builder := tipset.NewBuilder(taskAPI, g.name)
+	if g.budget != nil {
+		builder = builder.WithTaskLimiter(g.budget)
+	}
+	index, err := integrated.NewManager(g.DB, builder)
 	if err != nil {
 		return err
 	}
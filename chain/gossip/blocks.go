@@ -0,0 +1,95 @@
+package gossip
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/gossip")
+
+// subscriberBuffer is the number of blocks buffered for each subscriber, blocks are dropped for subscribers that fall
+// further behind so validation of the blocks topic is never held up.
+const subscriberBuffer = 256
+
+// Block is a block received over the blocks topic.
+type Block struct {
+	Header *types.BlockHeader
+	// ReceivedFrom is the peer the block was first received from.
+	ReceivedFrom peer.ID
+	// ReceivedAt is the time the block was received, before it was validated.
+	ReceivedAt time.Time
+}
+
+// BlockTracker observes the blocks received over the blocks topic by wrapping the topic's validator. Pubsub validates a
+// message once, when it is first received, so each block is observed once along with the peer that relayed it first.
+type BlockTracker struct {
+	mu   sync.Mutex
+	next int
+	subs map[int]chan *Block
+}
+
+func NewBlockTracker() *BlockTracker {
+	return &BlockTracker{
+		subs: map[int]chan *Block{},
+	}
+}
+
+// Subscribe returns a channel of the blocks that pass validation from now on. The channel is closed when `ctx` is done.
+func (t *BlockTracker) Subscribe(ctx context.Context) <-chan *Block {
+	ch := make(chan *Block, subscriberBuffer)
+	t.mu.Lock()
+	id := t.next
+	t.next++
+	t.subs[id] = ch
+	t.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		t.mu.Lock()
+		delete(t.subs, id)
+		t.mu.Unlock()
+		close(ch)
+	}()
+	return ch
+}
+
+// Validator wraps `validate`, the validator of the blocks topic, publishing the blocks it accepts to the subscribers.
+func (t *BlockTracker) Validator(validate pubsub.ValidatorEx) pubsub.ValidatorEx {
+	return func(ctx context.Context, pid peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		received := time.Now()
+		res := validate(ctx, pid, msg)
+		if res != pubsub.ValidationAccept {
+			return res
+		}
+		// the blocks validator leaves the decoded block on the message for the subscribers of the topic.
+		blk, ok := msg.ValidatorData.(*types.BlockMsg)
+		if !ok {
+			return res
+		}
+		t.publish(&Block{
+			Header:       blk.Header,
+			ReceivedFrom: msg.ReceivedFrom,
+			ReceivedAt:   received,
+		})
+		return res
+	}
+}
+
+func (t *BlockTracker) publish(blk *Block) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ch := range t.subs {
+		select {
+		case ch <- blk:
+		default:
+			log.Warnw("dropping gossiped block for slow subscriber", "cid", blk.Header.Cid())
+		}
+	}
+}
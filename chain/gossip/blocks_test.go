@@ -0,0 +1,70 @@
+package gossip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func testBlockMsg(t *testing.T, height abi.ChainEpoch) *types.BlockMsg {
+	c := cid.MustParse("bafy2bzacedrunl6pmqwxtmhr5cyjiy3p4qx5qqffjdwjzouyyzrirrycrabwe")
+	addr, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	return &types.BlockMsg{Header: &types.BlockHeader{
+		Miner:                 addr,
+		Height:                height,
+		ParentStateRoot:       c,
+		ParentMessageReceipts: c,
+		Messages:              c,
+		Ticket:                &types.Ticket{VRFProof: []byte{1}},
+		ParentBaseFee:         abi.NewTokenAmount(100),
+	}}
+}
+
+func TestBlockTrackerReportsAcceptedBlocks(t *testing.T) {
+	tracker := NewBlockTracker()
+	ctx, cancel := context.WithCancel(context.Background())
+	blocks := tracker.Subscribe(ctx)
+
+	accepted, rejected := testBlockMsg(t, 10), testBlockMsg(t, 11)
+	validate := tracker.Validator(func(_ context.Context, _ peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		blk := msg.ValidatorData.(*types.BlockMsg)
+		if blk.Header.Height == 11 {
+			return pubsub.ValidationReject
+		}
+		return pubsub.ValidationAccept
+	})
+
+	before := time.Now()
+	for _, blk := range []*types.BlockMsg{rejected, accepted} {
+		msg := &pubsub.Message{Message: &pb.Message{}, ReceivedFrom: peer.ID("relay"), ValidatorData: blk}
+		validate(ctx, peer.ID("relay"), msg)
+	}
+
+	// only blocks that pass validation are reported, along with the peer they were received from
+	got := <-blocks
+	require.Equal(t, accepted.Header, got.Header)
+	require.Equal(t, peer.ID("relay"), got.ReceivedFrom)
+	require.False(t, got.ReceivedAt.Before(before))
+	require.Empty(t, blocks)
+
+	// the subscription is closed once its context is done
+	cancel()
+	select {
+	case _, ok := <-blocks:
+		require.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription was not closed when the context was canceled")
+	}
+}
@@ -1,8 +1,11 @@
 package distributed
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/hibiken/asynq"
 	logging "github.com/ipfs/go-log/v2"
@@ -16,9 +19,13 @@ var log = logging.Logger("lily/distributed")
 // config.QueueConfig contains a duplicate queue name.
 func NewCatalog(cfg config.QueueConfig) (*Catalog, error) {
 	c := &Catalog{
-		servers: map[string]*TipSetWorker{},
-		clients: map[string]*asynq.Client{},
+		servers:    map[string]*TipSetWorker{},
+		clients:    map[string]*asynq.Client{},
+		inspectors: map[string]*asynq.Inspector{},
+		redisOpts:  map[string]asynq.RedisClientOpt{},
+		reporting:  map[string]bool{},
 	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
 
 	for name, sc := range cfg.Workers {
 		if _, exists := c.servers[name]; exists {
@@ -47,15 +54,17 @@ func NewCatalog(cfg config.QueueConfig) (*Catalog, error) {
 			redisPassword = sc.RedisConfig.Password
 		}
 
+		redisOpt := asynq.RedisClientOpt{
+			Network:  sc.RedisConfig.Network,
+			Addr:     redisAddr,
+			Username: redisUser,
+			Password: redisPassword,
+			DB:       sc.RedisConfig.DB,
+			PoolSize: sc.RedisConfig.PoolSize,
+		}
+		c.inspectors[name] = asynq.NewInspector(redisOpt)
 		c.servers[name] = &TipSetWorker{
-			RedisConfig: asynq.RedisClientOpt{
-				Network:  sc.RedisConfig.Network,
-				Addr:     redisAddr,
-				Username: redisUser,
-				Password: redisPassword,
-				DB:       sc.RedisConfig.DB,
-				PoolSize: sc.RedisConfig.PoolSize,
-			},
+			RedisConfig: redisOpt,
 			ServerConfig: asynq.Config{
 				LogLevel:        sc.WorkerConfig.LogLevel(),
 				Queues:          sc.WorkerConfig.Queues(),
@@ -63,6 +72,7 @@ func NewCatalog(cfg config.QueueConfig) (*Catalog, error) {
 				Concurrency:     sc.WorkerConfig.Concurrency,
 				StrictPriority:  sc.WorkerConfig.StrictPriority,
 			},
+			StatsInterval: sc.WorkerConfig.QueueStatsInterval,
 		}
 	}
 
@@ -93,16 +103,17 @@ func NewCatalog(cfg config.QueueConfig) (*Catalog, error) {
 			redisPassword = cc.Password
 		}
 
-		c.clients[name] = asynq.NewClient(
-			asynq.RedisClientOpt{
-				Network:  cc.Network,
-				Addr:     redisAddr,
-				Username: redisUser,
-				Password: redisPassword,
-				DB:       cc.DB,
-				PoolSize: cc.PoolSize,
-			},
-		)
+		redisOpt := asynq.RedisClientOpt{
+			Network:  cc.Network,
+			Addr:     redisAddr,
+			Username: redisUser,
+			Password: redisPassword,
+			DB:       cc.DB,
+			PoolSize: cc.PoolSize,
+		}
+		c.inspectors[name] = asynq.NewInspector(redisOpt)
+		c.redisOpts[name] = redisOpt
+		c.clients[name] = asynq.NewClient(redisOpt)
 	}
 	return c, nil
 }
@@ -110,6 +121,8 @@ func NewCatalog(cfg config.QueueConfig) (*Catalog, error) {
 type TipSetWorker struct {
 	RedisConfig  asynq.RedisClientOpt
 	ServerConfig asynq.Config
+	// StatsInterval is how often the worker records the depth of its queues as metrics.
+	StatsInterval time.Duration
 }
 
 // Catalog contains a map of workers and clients
@@ -117,6 +130,36 @@ type TipSetWorker struct {
 type Catalog struct {
 	servers map[string]*TipSetWorker
 	clients map[string]*asynq.Client
+	// inspectors contains an inspector for the redis instance of every worker and notifier.
+	inspectors map[string]*asynq.Inspector
+	// redisOpts contains the redis options of every notifier.
+	redisOpts map[string]asynq.RedisClientOpt
+
+	// reportNotifier reports the stats of the queues of a notifier until its context is canceled, may be nil.
+	reportNotifier NotifierReporter
+	// reporting contains the names of the notifiers whose stats are being reported.
+	reporting map[string]bool
+	mu        sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// A NotifierReporter reports the stats of the queues of the redis instance `instance` using `inspector` until `ctx` is
+// canceled.
+type NotifierReporter func(ctx context.Context, instance string, inspector *asynq.Inspector)
+
+// SetNotifierReporter sets the function reporting the stats of the queues of each notifier. Reporting starts the first
+// time a notifier is used and stops when the catalog is closed.
+func (c *Catalog) SetNotifierReporter(r NotifierReporter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reportNotifier = r
+}
+
+// RedisInstance returns the name identifying the redis instance and database of `opt`.
+func RedisInstance(opt asynq.RedisClientOpt) string {
+	return fmt.Sprintf("%s/%d", opt.Addr, opt.DB)
 }
 
 // Worker returns a runnable *asynq.Server by `name`. An error is returned if name is empty or if a
@@ -144,5 +187,48 @@ func (c *Catalog) Notifier(name string) (*asynq.Client, error) {
 	if !exists {
 		return nil, fmt.Errorf("unknown client: %q", name)
 	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reportNotifier != nil && !c.reporting[name] && c.ctx.Err() == nil {
+		c.reporting[name] = true
+		report, inspector, instance := c.reportNotifier, c.inspectors[name], RedisInstance(c.redisOpts[name])
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			report(c.ctx, instance, inspector)
+		}()
+	}
 	return client, nil
 }
+
+// Inspector returns an *asynq.Inspector for the worker or notifier named `name`. An error is returned if name is empty
+// or if neither a worker nor a notifier exists for `name`.
+func (c *Catalog) Inspector(name string) (*asynq.Inspector, error) {
+	if name == "" {
+		return nil, fmt.Errorf("queue config name required")
+	}
+
+	inspector, exists := c.inspectors[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown queue: %q", name)
+	}
+	return inspector, nil
+}
+
+// Close stops reporting the stats of the notifiers and closes the inspectors of the catalog.
+func (c *Catalog) Close() error {
+	c.mu.Lock()
+	c.cancel()
+	c.mu.Unlock()
+	c.wg.Wait()
+
+	var err error
+	for name, inspector := range c.inspectors {
+		if cerr := inspector.Close(); cerr != nil {
+			log.Errorw("closing queue inspector", "name", name, "error", cerr)
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+)
+
+// DefaultStatsInterval is the interval at which a worker records queue stats when none is configured.
+const DefaultStatsInterval = 10 * time.Second
+
+// QueueStats is a snapshot of the tasks held by a single queue.
+type QueueStats struct {
+	// Queue is the name of the queue, e.g. watch, walk, index or fill.
+	Queue string
+	// Size is the total number of tasks in the queue.
+	Size int
+	// Pending is the number of tasks waiting to be processed.
+	Pending int
+	// Active is the number of tasks currently being processed.
+	Active int
+	// Scheduled is the number of tasks scheduled to be processed in the future.
+	Scheduled int
+	// Retry is the number of tasks that failed and are waiting to be retried.
+	Retry int
+	// Archived is the number of tasks that exhausted their retries.
+	Archived int
+	// Completed is the number of completed tasks retained by the queue.
+	Completed int
+	// Latency is the age of the oldest pending task in the queue.
+	Latency time.Duration
+	// Paused is true when tasks in the queue are not being processed.
+	Paused bool
+	// Timestamp is the time the snapshot was taken.
+	Timestamp time.Time
+}
+
+// Inspector reads the state of the queues of a redis instance, it is satisfied by *asynq.Inspector.
+type Inspector interface {
+	Queues() ([]string, error)
+	GetQueueInfo(queue string) (*asynq.QueueInfo, error)
+}
+
+// Stats returns a snapshot of each queue in `queues`. When `queues` is empty a snapshot of every queue known to the
+// inspector is returned. Queues that have never had a task enqueued are reported as empty.
+func Stats(inspector Inspector, queues ...string) ([]*QueueStats, error) {
+	known, err := inspector.Queues()
+	if err != nil {
+		return nil, err
+	}
+	if len(queues) == 0 {
+		queues = known
+	}
+	exists := make(map[string]struct{}, len(known))
+	for _, q := range known {
+		exists[q] = struct{}{}
+	}
+
+	out := make([]*QueueStats, 0, len(queues))
+	for _, q := range queues {
+		if _, ok := exists[q]; !ok {
+			out = append(out, &QueueStats{Queue: q, Timestamp: time.Now()})
+			continue
+		}
+		info, err := inspector.GetQueueInfo(q)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &QueueStats{
+			Queue:     info.Queue,
+			Size:      info.Size,
+			Pending:   info.Pending,
+			Active:    info.Active,
+			Scheduled: info.Scheduled,
+			Retry:     info.Retry,
+			Archived:  info.Archived,
+			Completed: info.Completed,
+			Latency:   info.Latency,
+			Paused:    info.Paused,
+			Timestamp: info.Timestamp,
+		})
+	}
+	return out, nil
+}
+
+// RecordStats records each snapshot in `qs` as metrics tagged with the queue name.
+func RecordStats(ctx context.Context, qs []*QueueStats) error {
+	for _, s := range qs {
+		if err := stats.RecordWithTags(ctx,
+			[]tag.Mutator{tag.Upsert(metrics.QueueName, s.Queue)},
+			metrics.TipSetQueuePending.M(int64(s.Pending)),
+			metrics.TipSetQueueActive.M(int64(s.Active)),
+			metrics.TipSetQueueScheduled.M(int64(s.Scheduled)),
+			metrics.TipSetQueueRetry.M(int64(s.Retry)),
+			metrics.TipSetQueueArchived.M(int64(s.Archived)),
+			metrics.TipSetQueueLatency.M(float64(s.Latency.Milliseconds())),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statsOwners maps each queue of a redis instance to the reporter recording its stats, so that a queue fed by a
+// notifier and consumed by a worker of the same process is reported once.
+var statsOwners = struct {
+	sync.Mutex
+	next   int64
+	owners map[string]int64
+}{owners: map[string]int64{}}
+
+func newStatsOwner() int64 {
+	statsOwners.Lock()
+	defer statsOwners.Unlock()
+	statsOwners.next++
+	return statsOwners.next
+}
+
+// ownedStats returns the snapshots of `qs` of the queues of `instance` reported by `owner`, which takes over the
+// queues no other reporter records.
+func ownedStats(owner int64, instance string, qs []*QueueStats) []*QueueStats {
+	statsOwners.Lock()
+	defer statsOwners.Unlock()
+	out := make([]*QueueStats, 0, len(qs))
+	for _, s := range qs {
+		key := instance + "/" + s.Queue
+		if o, ok := statsOwners.owners[key]; ok && o != owner {
+			continue
+		}
+		statsOwners.owners[key] = owner
+		out = append(out, s)
+	}
+	return out
+}
+
+// releaseStats gives up the queues reported by `owner` so other reporters may take them over.
+func releaseStats(owner int64) {
+	statsOwners.Lock()
+	defer statsOwners.Unlock()
+	for key, o := range statsOwners.owners {
+		if o == owner {
+			delete(statsOwners.owners, key)
+		}
+	}
+}
+
+// ReportStats records the stats of `queues` of the redis instance `instance` every `interval` until `ctx` is
+// canceled, every known queue is reported when `queues` is empty. Queues already reported by another reporter of the
+// process are skipped. Failures to read stats are logged and do not stop reporting.
+func ReportStats(ctx context.Context, inspector Inspector, instance string, interval time.Duration, queues ...string) {
+	if interval <= 0 {
+		interval = DefaultStatsInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	owner := newStatsOwner()
+	defer releaseStats(owner)
+
+	for {
+		qs, err := Stats(inspector, queues...)
+		if err != nil {
+			log.Warnw("failed to read queue stats", "instance", instance, "error", err)
+		} else if err := RecordStats(ctx, ownedStats(owner, instance, qs)); err != nil {
+			log.Warnw("failed to record queue stats", "instance", instance, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+)
+
+type fakeInspector struct {
+	mu     sync.Mutex
+	queues map[string]*asynq.QueueInfo
+	err    error
+	reads  int
+}
+
+func (i *fakeInspector) Queues() ([]string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.reads++
+	if i.err != nil {
+		return nil, i.err
+	}
+	out := make([]string, 0, len(i.queues))
+	for q := range i.queues {
+		out = append(out, q)
+	}
+	return out, nil
+}
+
+func (i *fakeInspector) GetQueueInfo(queue string) (*asynq.QueueInfo, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.queues[queue], nil
+}
+
+func (i *fakeInspector) readCount() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.reads
+}
+
+func TestStats(t *testing.T) {
+	inspector := &fakeInspector{queues: map[string]*asynq.QueueInfo{
+		"watch": {Queue: "watch", Size: 3, Pending: 2, Active: 1, Latency: time.Second},
+	}}
+
+	// queues that were never enqueued to are reported as empty
+	qs, err := Stats(inspector, "watch", "fill")
+	require.NoError(t, err)
+	require.Len(t, qs, 2)
+	require.Equal(t, "watch", qs[0].Queue)
+	require.Equal(t, 3, qs[0].Size)
+	require.Equal(t, 2, qs[0].Pending)
+	require.Equal(t, 1, qs[0].Active)
+	require.Equal(t, time.Second, qs[0].Latency)
+	require.Equal(t, "fill", qs[1].Queue)
+	require.Zero(t, qs[1].Size)
+
+	// every known queue is reported when none are requested
+	qs, err = Stats(inspector)
+	require.NoError(t, err)
+	require.Len(t, qs, 1)
+	require.Equal(t, "watch", qs[0].Queue)
+
+	inspector.err = errors.New("redis unavailable")
+	_, err = Stats(inspector)
+	require.Error(t, err)
+}
+
+func TestRecordStats(t *testing.T) {
+	pending := &view.View{
+		Name:        "test_tipset_queue_pending",
+		Measure:     metrics.TipSetQueuePending,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{metrics.QueueName},
+	}
+	require.NoError(t, view.Register(pending))
+	defer view.Unregister(pending)
+
+	require.NoError(t, RecordStats(context.Background(), []*QueueStats{
+		{Queue: "watch", Pending: 5},
+		{Queue: "fill", Pending: 7},
+	}))
+
+	rows, err := view.RetrieveData(pending.Name)
+	require.NoError(t, err)
+	got := map[string]float64{}
+	for _, row := range rows {
+		require.Len(t, row.Tags, 1)
+		got[row.Tags[0].Value] = row.Data.(*view.LastValueData).Value
+	}
+	require.Equal(t, map[string]float64{"watch": 5, "fill": 7}, got)
+}
+
+func TestReportStatsStopsWithContext(t *testing.T) {
+	inspector := &fakeInspector{queues: map[string]*asynq.QueueInfo{"watch": {Queue: "watch"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ReportStats(ctx, inspector, "redis/0", time.Millisecond, "watch")
+	}()
+
+	require.Eventually(t, func() bool { return inspector.readCount() > 1 }, 5*time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reporting did not stop when the context was canceled")
+	}
+}
+
+func TestOwnedStats(t *testing.T) {
+	worker, notifier := newStatsOwner(), newStatsOwner()
+	defer releaseStats(worker)
+	defer releaseStats(notifier)
+	qs := []*QueueStats{{Queue: "watch"}, {Queue: "index"}}
+
+	// the worker reports the queue it consumes, the notifier the remaining queues of the same instance
+	require.Equal(t, qs[:1], ownedStats(worker, "redis/0", qs[:1]))
+	require.Equal(t, qs[1:], ownedStats(notifier, "redis/0", qs))
+	require.Equal(t, qs[:1], ownedStats(worker, "redis/0", qs))
+
+	// queues of other instances are reported separately
+	require.Equal(t, qs, ownedStats(notifier, "redis/1", qs))
+
+	// queues are taken over once their reporter stops
+	releaseStats(worker)
+	require.Equal(t, qs, ownedStats(notifier, "redis/0", qs))
+}
@@ -64,8 +64,23 @@ func (t *AsynqWorker) Run(ctx context.Context) error {
 	if err := server.Start(mux); err != nil {
 		return err
 	}
+
+	// report the depth of the queues consumed by this worker so it may be scaled on backlog.
+	queues := make([]string, 0, len(t.server.ServerConfig.Queues))
+	for queueName := range t.server.ServerConfig.Queues {
+		queues = append(queues, queueName)
+	}
+	inspector := asynq.NewInspector(t.server.RedisConfig)
+	defer inspector.Close() //nolint:errcheck
+	reporting := make(chan struct{})
+	go func() {
+		defer close(reporting)
+		ReportStats(metrics.WithTagValue(ctx, metrics.Job, t.name), inspector, distributed.RedisInstance(t.server.RedisConfig), t.server.StatsInterval, queues...)
+	}()
+
 	<-ctx.Done()
 	server.Shutdown()
+	<-reporting
 	return nil
 }
 
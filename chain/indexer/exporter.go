@@ -54,6 +54,12 @@ func (me *ModelExporter) ExportResult(ctx context.Context, strg model.Storage, h
 		}
 	}()
 
+	if ps, ok := strg.(model.PartitionedStorage); ok {
+		if err := ps.EnsurePartitions(ctx, height); err != nil {
+			return fmt.Errorf("ensure partitions at height %d: %w", height, err)
+		}
+	}
+
 	grp, ctx := errgroup.WithContext(ctx)
 	for _, res := range results {
 		res := res
@@ -0,0 +1,109 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opencensus.io/stats"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// TaskLimiter bounds the execution of the tasks run by a StateProcessor. A single TaskLimiter may be shared by the
+// processors of many jobs to bound the number of tasks executing across all of them.
+type TaskLimiter interface {
+	// Acquire blocks until a task may start executing or `ctx` is done, in which case the context's error is returned.
+	Acquire(ctx context.Context) error
+	// Release frees the slot taken by a successful call to Acquire.
+	Release()
+	// Timeout returns the maximum duration a single task may execute for, zero means tasks are never timed out.
+	Timeout() time.Duration
+}
+
+type StateProcessorOpt func(sp *StateProcessor)
+
+// WithTaskLimiter sets the TaskLimiter that admits the processor's tasks and bounds their duration.
+func WithTaskLimiter(l TaskLimiter) StateProcessorOpt {
+	return func(sp *StateProcessor) {
+		sp.limiter = l
+	}
+}
+
+// taskFunc executes a task with the context it must abort processing on.
+type taskFunc func(ctx context.Context) (model.Persistable, *visormodel.ProcessingReport, error)
+
+// runTask waits for the processor's TaskLimiter to admit `task` and executes it. A non-empty reason is returned when
+// the task should be reported as skipped: when it was not admitted or as soon as it exceeds its timeout, without
+// waiting for it to return. The slot of a task that exceeded its timeout is released once the task returns.
+func (sp *StateProcessor) runTask(ctx context.Context, task taskFunc) (model.Persistable, *visormodel.ProcessingReport, string, error) {
+	if sp.limiter == nil {
+		data, report, err := task(ctx)
+		return data, report, "", err
+	}
+
+	if err := sp.limiter.Acquire(ctx); err != nil {
+		return nil, nil, fmt.Sprintf("task not admitted: %s", err), nil
+	}
+
+	timeout := sp.limiter.Timeout()
+	if timeout <= 0 {
+		defer sp.limiter.Release()
+		data, report, err := task(ctx)
+		return data, report, "", err
+	}
+
+	type outcome struct {
+		data   model.Persistable
+		report *visormodel.ProcessingReport
+		err    error
+	}
+	taskCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan outcome, 1)
+	go func() {
+		defer sp.limiter.Release()
+		data, report, err := task(taskCtx)
+		done <- outcome{data: data, report: report, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		// a task observing its deadline may return before the deadline is seen here.
+		if ctx.Err() == nil && errors.Is(taskCtx.Err(), context.DeadlineExceeded) {
+			break
+		}
+		return out.data, out.report, "", out.err
+	case <-taskCtx.Done():
+		// only the task's own deadline is a reason to skip it, cancellation of the parent is handled by the indexer.
+		if ctx.Err() != nil {
+			return nil, nil, "", ctx.Err()
+		}
+	}
+	stats.Record(ctx, metrics.TaskTimeout.M(1))
+	return nil, nil, fmt.Sprintf("task exceeded timeout of %s", timeout), nil
+}
+
+// skipResult returns a Result reporting that task `name` was skipped for `reason`.
+func (sp *StateProcessor) skipResult(name string, current *types.TipSet, start time.Time, reason string) *Result {
+	return &Result{
+		Task: name,
+		Report: visormodel.ProcessingReportList{&visormodel.ProcessingReport{
+			Height:            int64(current.Height()),
+			StateRoot:         current.ParentState().String(),
+			Reporter:          sp.name,
+			Task:              name,
+			StartedAt:         start,
+			CompletedAt:       time.Now(),
+			Status:            visormodel.ProcessingStatusSkip,
+			StatusInformation: reason,
+		}},
+		StartedAt:   start,
+		CompletedAt: time.Now(),
+	}
+}
@@ -0,0 +1,130 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lily/model"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+type fakeLimiter struct {
+	acquireErr error
+	timeout    time.Duration
+	released   chan struct{}
+}
+
+func (l *fakeLimiter) Acquire(context.Context) error { return l.acquireErr }
+func (l *fakeLimiter) Release()                      { l.released <- struct{}{} }
+func (l *fakeLimiter) Timeout() time.Duration        { return l.timeout }
+
+// blockingProcessor ignores its context and returns once unblock is closed.
+type blockingProcessor struct {
+	called  chan struct{}
+	unblock chan struct{}
+}
+
+func (p *blockingProcessor) ProcessTipSet(context.Context, *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	close(p.called)
+	<-p.unblock
+	return nil, &visormodel.ProcessingReport{}, nil
+}
+
+func limitTestTipSet(t *testing.T) *types.TipSet {
+	c := cid.MustParse("bafy2bzacedrunl6pmqwxtmhr5cyjiy3p4qx5qqffjdwjzouyyzrirrycrabwe")
+	miner, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	ts, err := types.NewTipSet([]*types.BlockHeader{{
+		Miner:                 miner,
+		Height:                10,
+		ParentStateRoot:       c,
+		ParentMessageReceipts: c,
+		Messages:              c,
+		Ticket:                &types.Ticket{VRFProof: []byte{1}},
+		ParentBaseFee:         abi.NewTokenAmount(100),
+	}})
+	require.NoError(t, err)
+	return ts
+}
+
+func TestTaskExceedingTimeoutIsSkipped(t *testing.T) {
+	limiter := &fakeLimiter{timeout: 10 * time.Millisecond, released: make(chan struct{}, 1)}
+	proc := &blockingProcessor{called: make(chan struct{}), unblock: make(chan struct{})}
+	sp := &StateProcessor{
+		tipsetProcessors: map[string]TipSetProcessor{"blocking": proc},
+		name:             t.Name(),
+		limiter:          limiter,
+	}
+
+	ts := limitTestTipSet(t)
+	results, names := sp.State(context.Background(), ts, ts, 0)
+	require.Equal(t, []string{"blocking"}, names)
+
+	// the skip is reported while the task is still executing
+	select {
+	case res := <-results:
+		require.Equal(t, "blocking", res.Task)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Report, 1)
+		require.Equal(t, visormodel.ProcessingStatusSkip, res.Report[0].Status)
+		require.Equal(t, "task exceeded timeout of 10ms", res.Report[0].StatusInformation)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out task was not reported")
+	}
+	<-proc.called
+
+	// the task's slot is held until the task returns
+	select {
+	case <-limiter.released:
+		t.Fatal("slot released before the task returned")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(proc.unblock)
+	select {
+	case <-limiter.released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slot was not released once the task returned")
+	}
+
+	_, open := <-results
+	require.False(t, open)
+}
+
+func TestTaskNotAdmittedIsSkipped(t *testing.T) {
+	limiter := &fakeLimiter{acquireErr: errors.New("too many tasks"), timeout: time.Minute, released: make(chan struct{}, 1)}
+	proc := &blockingProcessor{called: make(chan struct{}), unblock: make(chan struct{})}
+	sp := &StateProcessor{
+		tipsetProcessors: map[string]TipSetProcessor{"blocking": proc},
+		name:             t.Name(),
+		limiter:          limiter,
+	}
+
+	ts := limitTestTipSet(t)
+	results, _ := sp.State(context.Background(), ts, ts, 0)
+
+	var got []*Result
+	for res := range results {
+		got = append(got, res)
+	}
+	require.Len(t, got, 1)
+	require.Equal(t, visormodel.ProcessingStatusSkip, got[0].Report[0].Status)
+	require.Equal(t, "task not admitted: too many tasks", got[0].Report[0].StatusInformation)
+
+	// the task was never executed and no slot was released
+	select {
+	case <-proc.called:
+		t.Fatal("task executed without being admitted")
+	default:
+	}
+	require.Empty(t, limiter.released)
+}
@@ -12,6 +12,7 @@ import (
 	"go.opencensus.io/tag"
 	"go.opentelemetry.io/otel"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	actorstypes "github.com/filecoin-project/go-state-types/actors"
 	"github.com/filecoin-project/go-state-types/manifest"
@@ -29,6 +30,8 @@ import (
 	rewardactors "github.com/filecoin-project/lily/chain/actors/builtin/reward"
 	verifregactors "github.com/filecoin-project/lily/chain/actors/builtin/verifreg"
 	"github.com/filecoin-project/lily/tasks"
+	"github.com/filecoin-project/lily/tasks/messageexecutions/filledger"
+	"github.com/filecoin-project/lily/tasks/messageexecutions/minerblockreward"
 	"github.com/filecoin-project/lily/tasks/messageexecutions/vm"
 	"github.com/filecoin-project/lily/tasks/messages/actorevent"
 	"github.com/filecoin-project/lily/tasks/messages/builtinactorevent"
@@ -53,11 +56,14 @@ import (
 	parentstask "github.com/filecoin-project/lily/tasks/blocks/parents"
 	chainecontask "github.com/filecoin-project/lily/tasks/chaineconomics"
 	consensustask "github.com/filecoin-project/lily/tasks/consensus"
+	economicsprojectiontask "github.com/filecoin-project/lily/tasks/economicsprojection"
 	indexertask "github.com/filecoin-project/lily/tasks/indexer"
 	imtask "github.com/filecoin-project/lily/tasks/messageexecutions/internalmessage"
 	ipmtask "github.com/filecoin-project/lily/tasks/messageexecutions/internalparsedmessage"
 	bmtask "github.com/filecoin-project/lily/tasks/messages/blockmessage"
+	ddopiecestask "github.com/filecoin-project/lily/tasks/messages/ddopieces"
 	gasecontask "github.com/filecoin-project/lily/tasks/messages/gaseconomy"
+	gasfeepercentilestask "github.com/filecoin-project/lily/tasks/messages/gasfeepercentiles"
 	gasouttask "github.com/filecoin-project/lily/tasks/messages/gasoutput"
 	messagetask "github.com/filecoin-project/lily/tasks/messages/message"
 	parentmessagetask "github.com/filecoin-project/lily/tasks/messages/parsedmessage"
@@ -77,10 +83,13 @@ import (
 	"github.com/filecoin-project/lily/metrics"
 	"github.com/filecoin-project/lily/model"
 	visormodel "github.com/filecoin-project/lily/model/visor"
+	datacapbalancedumptask "github.com/filecoin-project/lily/tasks/periodic_actor_dump/data_cap_balance"
 	fevmactordumptask "github.com/filecoin-project/lily/tasks/periodic_actor_dump/fevm_actor"
+	marketdealdumptask "github.com/filecoin-project/lily/tasks/periodic_actor_dump/market_deal"
 	mineractordumptask "github.com/filecoin-project/lily/tasks/periodic_actor_dump/miner_actor"
-
-	builtin "github.com/filecoin-project/lotus/chain/actors/builtin"
+	multisigactordumptask "github.com/filecoin-project/lily/tasks/periodic_actor_dump/multisig_actor"
+	powerclaimdumptask "github.com/filecoin-project/lily/tasks/periodic_actor_dump/power_claim"
+	verifiedregistrydumptask "github.com/filecoin-project/lily/tasks/periodic_actor_dump/verified_registry"
 )
 
 type TipSetProcessor interface {
@@ -121,14 +130,14 @@ var log = logging.Logger("lily/index/processor")
 
 const BuiltinTaskName = "builtin"
 
-func New(api tasks.DataSource, name string, taskNames []string) (*StateProcessor, error) {
+func New(api tasks.DataSource, name string, taskNames []string, opts ...StateProcessorOpt) (*StateProcessor, error) {
 	taskNames = append(taskNames, BuiltinTaskName)
 
 	processors, err := MakeProcessors(api, taskNames)
 	if err != nil {
 		return nil, err
 	}
-	return &StateProcessor{
+	sp := &StateProcessor{
 		builtinProcessors:           processors.ReportProcessors,
 		tipsetProcessors:            processors.TipsetProcessors,
 		tipsetsProcessors:           processors.TipsetsProcessors,
@@ -136,7 +145,11 @@ func New(api tasks.DataSource, name string, taskNames []string) (*StateProcessor
 		periodicActorDumpProcessors: processors.PeriodicActorDumpProcessors,
 		api:                         api,
 		name:                        name,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(sp)
+	}
+	return sp, nil
 }
 
 type StateProcessor struct {
@@ -154,6 +167,20 @@ type StateProcessor struct {
 
 	// name of the processor
 	name string
+
+	// limiter admits tasks for execution and bounds their duration, may be nil.
+	limiter TaskLimiter
+
+	// actorDumpIntervals maps periodic actor dump task names to the interval in epochs they run at.
+	actorDumpIntervals map[string]int
+}
+
+// WithActorDumpIntervals sets the interval in epochs at which each periodic actor dump task runs. Tasks without an
+// interval run at the interval of the job.
+func WithActorDumpIntervals(intervals map[string]int) StateProcessorOpt {
+	return func(sp *StateProcessor) {
+		sp.actorDumpIntervals = intervals
+	}
 }
 
 // A Result is either some data to persist or an error which indicates that the task did not complete. Partial
@@ -263,7 +290,14 @@ func (sp *StateProcessor) startTipSet(ctx context.Context, current *types.TipSet
 				sp.pwg.Done()
 			}()
 
-			data, report, err := p.ProcessTipSet(ctx, current)
+			data, report, reason, err := sp.runTask(ctx, func(ctx context.Context) (model.Persistable, *visormodel.ProcessingReport, error) {
+				return p.ProcessTipSet(ctx, current)
+			})
+			if reason != "" {
+				pl.Warnw("processor skipped", "reason", reason)
+				results <- sp.skipResult(name, current, start, reason)
+				return
+			}
 			if err != nil {
 				stats.Record(ctx, metrics.ProcessingFailure.M(1))
 				results <- &Result{
@@ -311,7 +345,14 @@ func (sp *StateProcessor) startTipSets(ctx context.Context, current, executed *t
 				sp.pwg.Done()
 			}()
 
-			data, report, err := p.ProcessTipSets(ctx, current, executed)
+			data, report, reason, err := sp.runTask(ctx, func(ctx context.Context) (model.Persistable, *visormodel.ProcessingReport, error) {
+				return p.ProcessTipSets(ctx, current, executed)
+			})
+			if reason != "" {
+				pl.Warnw("processor skipped", "reason", reason)
+				results <- sp.skipResult(name, current, start, reason)
+				return
+			}
 			if err != nil {
 				stats.Record(ctx, metrics.ProcessingFailure.M(1))
 				results <- &Result{
@@ -394,7 +435,14 @@ func (sp *StateProcessor) startActor(ctx context.Context, current, executed *typ
 					sp.pwg.Done()
 				}()
 
-				data, report, err := p.ProcessActors(ctx, current, executed, changes)
+				data, report, reason, err := sp.runTask(ctx, func(ctx context.Context) (model.Persistable, *visormodel.ProcessingReport, error) {
+					return p.ProcessActors(ctx, current, executed, changes)
+				})
+				if reason != "" {
+					pl.Warnw("processor skipped", "reason", reason)
+					results <- sp.skipResult(name, current, start, reason)
+					return
+				}
 				if err != nil {
 					stats.Record(ctx, metrics.ProcessingFailure.M(1))
 					results <- &Result{
@@ -419,50 +467,76 @@ func (sp *StateProcessor) startActor(ctx context.Context, current, executed *typ
 	return taskNames
 }
 
-// isStoragePowerActor will check if the actor is storage power or not
-func isStoragePowerActor(c cid.Cid) bool {
+// dumpedActorFamilies are the manifest keys of the actor families collected for the PeriodicActorDumpProcessor's.
+var dumpedActorFamilies = map[string]struct{}{
+	manifest.EvmKey:         {},
+	manifest.EthAccountKey:  {},
+	manifest.PlaceholderKey: {},
+	manifest.PowerKey:       {},
+	manifest.MarketKey:      {},
+	manifest.VerifregKey:    {},
+	manifest.DatacapKey:     {},
+	manifest.MultisigKey:    {},
+}
+
+// dumpedActorFamily returns the manifest key of the actor family with code `c` and true if its states are collected
+// for the PeriodicActorDumpProcessor's.
+func dumpedActorFamily(c cid.Cid) (string, bool) {
 	name, _, ok := actors.GetActorMetaByCode(c)
-	if ok {
-		return name == manifest.PowerKey
+	if !ok {
+		return "", false
 	}
-	return false
+	_, ok = dumpedActorFamilies[name]
+	return name, ok
 }
 
-// startPeriodicActorDump starts all TipSetsProcessor's in parallel, their results are emitted on the `results` channel.
+// actorDumpInterval returns the interval in epochs at which the periodic actor dump task `name` runs, the interval
+// configured for the task takes precedence over the interval of the job.
+func (sp *StateProcessor) actorDumpInterval(name string, interval int) int {
+	if taskInterval, ok := sp.actorDumpIntervals[name]; ok {
+		return taskInterval
+	}
+	return interval
+}
+
+// startPeriodicActorDump starts all PeriodicActorDumpProcessor's due at the height of `current` in parallel, their
+// results are emitted on the `results` channel. A processor is due when the height is a multiple of its interval.
 // A list containing all executed task names is returned.
 func (sp *StateProcessor) startPeriodicActorDump(ctx context.Context, current *types.TipSet, interval int, results chan *Result) []string {
 	start := time.Now()
 	var taskNames []string
 
-	if len(sp.periodicActorDumpProcessors) == 0 {
-		return taskNames
+	due := make(map[string]PeriodicActorDumpProcessor)
+	for taskName, proc := range sp.periodicActorDumpProcessors {
+		taskInterval := sp.actorDumpInterval(taskName, interval)
+		if taskInterval > 0 && current.Height()%abi.ChainEpoch(taskInterval) != 0 {
+			continue
+		}
+		due[taskName] = proc
 	}
 
-	if interval > 0 && current.Height()%abi.ChainEpoch(interval) != 0 {
-		logger := log.With("processor", "PeriodicActorDump")
-		logger.Infow("Skip this epoch", current.Height())
+	if len(due) == 0 {
+		if len(sp.periodicActorDumpProcessors) > 0 {
+			logger := log.With("processor", "PeriodicActorDump")
+			logger.Infow("Skip this epoch", "height", current.Height())
+		}
 		return taskNames
 	}
 
-	actors := make(map[string][]*types.ActorV5)
+	actorStates := make(tasks.ActorStatesByType)
 	addrssArr, _ := sp.api.StateListActors(ctx, current.Key())
 
-	for _, address := range addrssArr {
-		actor, err := sp.api.Actor(ctx, address, current.Key())
+	for _, addr := range addrssArr {
+		actor, err := sp.api.Actor(ctx, addr, current.Key())
 		if err != nil {
 			continue
 		}
 
-		// EVM Actor
-		if builtin.IsEvmActor(actor.Code) {
-			actors[manifest.EvmKey] = append(actors[manifest.EvmKey], actor)
-		} else if builtin.IsEthAccountActor(actor.Code) {
-			actors[manifest.EthAccountKey] = append(actors[manifest.EthAccountKey], actor)
-		} else if builtin.IsPlaceholderActor(actor.Code) {
-			actors[manifest.PlaceholderKey] = append(actors[manifest.PlaceholderKey], actor)
-		} else if isStoragePowerActor(actor.Code) {
-			// Power Actor
-			actors[manifest.PowerKey] = append(actors[manifest.PowerKey], actor)
+		if family, ok := dumpedActorFamily(actor.Code); ok {
+			if actorStates[family] == nil {
+				actorStates[family] = make(map[address.Address]*types.ActorV5)
+			}
+			actorStates[family][addr] = actor
 		}
 	}
 
@@ -472,14 +546,14 @@ func (sp *StateProcessor) startPeriodicActorDump(ctx context.Context, current *t
 		log.Errorf("Error at setting IdRobustAddressMap: %v", err)
 	}
 
-	for taskName, proc := range sp.periodicActorDumpProcessors {
+	for taskName, proc := range due {
 		name := taskName
 		p := proc
 		taskNames = append(taskNames, name)
 
 		sp.pwg.Add(1)
 		go func() {
-			ctx, _ = tag.New(ctx, tag.Upsert(metrics.TaskType, name))
+			ctx, _ := tag.New(ctx, tag.Upsert(metrics.TaskType, name))
 			stats.Record(ctx, metrics.TipsetHeight.M(int64(current.Height())))
 			stop := metrics.Timer(ctx, metrics.ProcessingDuration)
 			defer stop()
@@ -491,7 +565,14 @@ func (sp *StateProcessor) startPeriodicActorDump(ctx context.Context, current *t
 				sp.pwg.Done()
 			}()
 
-			data, report, err := p.ProcessPeriodicActorDump(ctx, current, actors)
+			data, report, reason, err := sp.runTask(ctx, func(ctx context.Context) (model.Persistable, *visormodel.ProcessingReport, error) {
+				return p.ProcessPeriodicActorDump(ctx, current, actorStates)
+			})
+			if reason != "" {
+				pl.Warnw("processor skipped", "reason", reason)
+				results <- sp.skipResult(name, current, start, reason)
+				return
+			}
 			if err != nil {
 				stats.Record(ctx, metrics.ProcessingFailure.M(1))
 				results <- &Result{
@@ -713,6 +794,11 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 				multisigactors.AllCodes(),
 				multisigtask.MultiSigActorExtractor{},
 			))
+		case tasktype.MultisigInfo:
+			out.ActorProcessors[t] = actorstate.NewTask(api, actorstate.NewTypedActorExtractorMap(
+				multisigactors.AllCodes(),
+				multisigtask.InfoExtractor{},
+			))
 
 			//
 			// Verified Registry
@@ -777,6 +863,8 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 
 		case tasktype.GasOutputs:
 			out.TipsetsProcessors[t] = gasouttask.NewTask(api)
+		case tasktype.GasFeePercentiles:
+			out.TipsetsProcessors[t] = gasfeepercentilestask.NewTask(api)
 		case tasktype.ParsedMessage:
 			out.TipsetsProcessors[t] = parentmessagetask.NewTask(api)
 		case tasktype.Receipt:
@@ -789,10 +877,16 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 			out.TipsetsProcessors[t] = msapprovaltask.NewTask(api)
 		case tasktype.VMMessage:
 			out.TipsetsProcessors[t] = vm.NewTask(api)
+		case tasktype.FilLedgerEntry:
+			out.TipsetsProcessors[t] = filledger.NewTask(api)
+		case tasktype.MinerBlockReward:
+			out.TipsetsProcessors[t] = minerblockreward.NewTask(api)
 		case tasktype.ActorEvent:
 			out.TipsetsProcessors[t] = actorevent.NewTask(api)
 		case tasktype.BuiltInActorEvent:
-			out.TipsetsProcessors[t] = builtinactorevent.NewTask(api)
+			out.TipsetsProcessors[t] = builtinactorevent.NewTask(api, indexerTasks...)
+		case tasktype.DDOPiece:
+			out.TipsetsProcessors[t] = ddopiecestask.NewTask(api)
 		case tasktype.ReceiptReturn:
 			out.TipsetsProcessors[t] = receiptreturn.NewTask(api)
 
@@ -810,6 +904,8 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 			out.TipsetProcessors[t] = chainecontask.NewTask(api, 0)
 		case tasktype.ChainEconomicsV2:
 			out.TipsetProcessors[t] = chainecontask.NewTask(api, 2)
+		case tasktype.ChainEconomicsProjection:
+			out.TipsetProcessors[t] = economicsprojectiontask.NewTask(api)
 		case tasktype.ChainConsensus:
 			out.TipsetProcessors[t] = consensustask.NewTask(api)
 
@@ -836,10 +932,26 @@ func MakeProcessors(api tasks.DataSource, indexerTasks []string) (*IndexerProces
 			out.PeriodicActorDumpProcessors[t] = fevmactordumptask.NewTask(api)
 		case tasktype.MinerActorDump:
 			out.PeriodicActorDumpProcessors[t] = mineractordumptask.NewTask(api)
+		case tasktype.MarketDealDump:
+			out.PeriodicActorDumpProcessors[t] = marketdealdumptask.NewTask(api)
+		case tasktype.PowerClaimDump:
+			out.PeriodicActorDumpProcessors[t] = powerclaimdumptask.NewTask(api)
+		case tasktype.MultisigActorDump:
+			out.PeriodicActorDumpProcessors[t] = multisigactordumptask.NewTask(api)
+		case tasktype.VerifiedRegistryDump:
+			out.PeriodicActorDumpProcessors[t] = verifiedregistrydumptask.NewTask(api)
+		case tasktype.DataCapBalanceDump:
+			out.PeriodicActorDumpProcessors[t] = datacapbalancedumptask.NewTask(api)
 
 		case BuiltinTaskName:
 			out.ReportProcessors[t] = indexertask.NewTask(api)
 		default:
+			// the tasks of the typed builtin actor event tables are declared by the tables registered with
+			// builtinactorevent.
+			if _, ok := builtinactorevent.LookupEventTable(t); ok {
+				out.TipsetsProcessors[t] = builtinactorevent.NewTypedTask(api, t)
+				continue
+			}
 			return nil, fmt.Errorf("unknown task: %s", t)
 		}
 	}
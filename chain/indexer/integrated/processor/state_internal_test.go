@@ -1,11 +1,14 @@
 package processor
 
 import (
+	"context"
+	"sort"
 	"testing"
 
 	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-address"
 	actorstypes "github.com/filecoin-project/go-state-types/actors"
 	"github.com/filecoin-project/lily/chain/actors/builtin/datacap"
 	init_ "github.com/filecoin-project/lily/chain/actors/builtin/init"
@@ -16,6 +19,9 @@ import (
 	"github.com/filecoin-project/lily/chain/actors/builtin/reward"
 	"github.com/filecoin-project/lily/chain/actors/builtin/verifreg"
 	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+	"github.com/filecoin-project/lily/model"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
 	"github.com/filecoin-project/lily/tasks/actorstate"
 	datacaptask "github.com/filecoin-project/lily/tasks/actorstate/datacap"
 	inittask "github.com/filecoin-project/lily/tasks/actorstate/init_"
@@ -31,12 +37,18 @@ import (
 	"github.com/filecoin-project/lily/tasks/blocks/parents"
 	"github.com/filecoin-project/lily/tasks/chaineconomics"
 	"github.com/filecoin-project/lily/tasks/consensus"
+	"github.com/filecoin-project/lily/tasks/economicsprojection"
+	"github.com/filecoin-project/lily/tasks/messageexecutions/filledger"
 	"github.com/filecoin-project/lily/tasks/messageexecutions/internalmessage"
 	"github.com/filecoin-project/lily/tasks/messageexecutions/internalparsedmessage"
+	"github.com/filecoin-project/lily/tasks/messageexecutions/minerblockreward"
 	"github.com/filecoin-project/lily/tasks/messageexecutions/vm"
 	"github.com/filecoin-project/lily/tasks/messages/actorevent"
 	"github.com/filecoin-project/lily/tasks/messages/blockmessage"
+	"github.com/filecoin-project/lily/tasks/messages/builtinactorevent"
+	"github.com/filecoin-project/lily/tasks/messages/ddopieces"
 	"github.com/filecoin-project/lily/tasks/messages/gaseconomy"
+	"github.com/filecoin-project/lily/tasks/messages/gasfeepercentiles"
 	"github.com/filecoin-project/lily/tasks/messages/gasoutput"
 	"github.com/filecoin-project/lily/tasks/messages/message"
 	"github.com/filecoin-project/lily/tasks/messages/messageparam"
@@ -44,24 +56,35 @@ import (
 	"github.com/filecoin-project/lily/tasks/messages/receipt"
 	"github.com/filecoin-project/lily/tasks/messages/receiptreturn"
 	"github.com/filecoin-project/lily/tasks/msapprovals"
+
+	"github.com/filecoin-project/lotus/chain/types"
 )
 
 func TestNewProcessor(t *testing.T) {
 	proc, err := New(nil, t.Name(), tasktype.AllTableTasks)
 	require.NoError(t, err)
 	require.Equal(t, t.Name(), proc.name)
-	require.Len(t, proc.actorProcessors, 26)
-	require.Len(t, proc.tipsetProcessors, 11)
-	require.Len(t, proc.tipsetsProcessors, 15)
+	require.Len(t, proc.actorProcessors, 27)
+	require.Len(t, proc.tipsetProcessors, 12)
+	require.Len(t, proc.tipsetsProcessors, 24)
 	require.Len(t, proc.builtinProcessors, 1)
 
 	require.Equal(t, gasoutput.NewTask(nil), proc.tipsetsProcessors[tasktype.GasOutputs])
+	require.Equal(t, gasfeepercentiles.NewTask(nil), proc.tipsetsProcessors[tasktype.GasFeePercentiles])
+	require.Equal(t, ddopieces.NewTask(nil), proc.tipsetsProcessors[tasktype.DDOPiece])
+	require.Equal(t, builtinactorevent.NewTypedTask(nil, tasktype.BuiltInVerifierBalanceEvent), proc.tipsetsProcessors[tasktype.BuiltInVerifierBalanceEvent])
+	require.Equal(t, builtinactorevent.NewTypedTask(nil, tasktype.BuiltInAllocationEvent), proc.tipsetsProcessors[tasktype.BuiltInAllocationEvent])
+	require.Equal(t, builtinactorevent.NewTypedTask(nil, tasktype.BuiltInClaimEvent), proc.tipsetsProcessors[tasktype.BuiltInClaimEvent])
+	require.Equal(t, builtinactorevent.NewTypedTask(nil, tasktype.BuiltInDealEvent), proc.tipsetsProcessors[tasktype.BuiltInDealEvent])
+	require.Equal(t, builtinactorevent.NewTypedTask(nil, tasktype.BuiltInSectorEvent), proc.tipsetsProcessors[tasktype.BuiltInSectorEvent])
 	require.Equal(t, parsedmessage.NewTask(nil), proc.tipsetsProcessors[tasktype.ParsedMessage])
 	require.Equal(t, receipt.NewTask(nil), proc.tipsetsProcessors[tasktype.Receipt])
 	require.Equal(t, internalmessage.NewTask(nil), proc.tipsetsProcessors[tasktype.InternalMessage])
 	require.Equal(t, internalparsedmessage.NewTask(nil), proc.tipsetsProcessors[tasktype.InternalParsedMessage])
 	require.Equal(t, msapprovals.NewTask(nil), proc.tipsetsProcessors[tasktype.MultisigApproval])
 	require.Equal(t, vm.NewTask(nil), proc.tipsetsProcessors[tasktype.VMMessage])
+	require.Equal(t, filledger.NewTask(nil), proc.tipsetsProcessors[tasktype.FilLedgerEntry])
+	require.Equal(t, minerblockreward.NewTask(nil), proc.tipsetsProcessors[tasktype.MinerBlockReward])
 	require.Equal(t, actorevent.NewTask(nil), proc.tipsetsProcessors[tasktype.ActorEvent])
 	require.Equal(t, receiptreturn.NewTask(nil), proc.tipsetsProcessors[tasktype.ReceiptReturn])
 
@@ -71,6 +94,7 @@ func TestNewProcessor(t *testing.T) {
 	require.Equal(t, parents.NewTask(), proc.tipsetProcessors[tasktype.BlockParent])
 	require.Equal(t, drand.NewTask(), proc.tipsetProcessors[tasktype.DrandBlockEntrie])
 	require.Equal(t, chaineconomics.NewTask(nil, 0), proc.tipsetProcessors[tasktype.ChainEconomics])
+	require.Equal(t, economicsprojection.NewTask(nil), proc.tipsetProcessors[tasktype.ChainEconomicsProjection])
 	require.Equal(t, consensus.NewTask(nil), proc.tipsetProcessors[tasktype.ChainConsensus])
 	require.Equal(t, gaseconomy.NewTask(nil), proc.tipsetProcessors[tasktype.MessageGasEconomy])
 	require.Equal(t, messageparam.NewTask(nil), proc.tipsetProcessors[tasktype.MessageParam])
@@ -118,6 +142,7 @@ func TestNewProcessor(t *testing.T) {
 	require.Equal(t, actorstate.NewTask(nil, actorstate.NewTypedActorExtractorMap(market.AllCodes(), markettask.DealStateExtractor{})), proc.actorProcessors[tasktype.MarketDealState])
 	require.Equal(t, actorstate.NewTask(nil, actorstate.NewTypedActorExtractorMap(market.AllCodes(), markettask.DealProposalExtractor{})), proc.actorProcessors[tasktype.MarketDealProposal])
 	require.Equal(t, actorstate.NewTask(nil, actorstate.NewTypedActorExtractorMap(multisig.AllCodes(), multisigtask.MultiSigActorExtractor{})), proc.actorProcessors[tasktype.MultisigTransaction])
+	require.Equal(t, actorstate.NewTask(nil, actorstate.NewTypedActorExtractorMap(multisig.AllCodes(), multisigtask.InfoExtractor{})), proc.actorProcessors[tasktype.MultisigInfo])
 	require.Equal(t, actorstate.NewTask(nil, actorstate.NewTypedActorExtractorMap(verifreg.AllCodes(), verifregtask.VerifierExtractor{})), proc.actorProcessors[tasktype.VerifiedRegistryVerifier])
 
 	require.Equal(t, actorstate.NewTaskWithTransformer(
@@ -172,3 +197,69 @@ func TestNewProcessor(t *testing.T) {
 	rat1 := &rawtask.RawActorStateExtractor{}
 	require.Equal(t, actorstate.NewTaskWithTransformer(nil, rae1, rat1), proc.actorProcessors[tasktype.ActorState])
 }
+
+func TestActorDumpInterval(t *testing.T) {
+	sp := &StateProcessor{}
+	require.Equal(t, 120, sp.actorDumpInterval(tasktype.MarketDealDump, 120))
+
+	WithActorDumpIntervals(map[string]int{tasktype.MarketDealDump: 2880, tasktype.PowerClaimDump: 0})(sp)
+	require.Equal(t, 2880, sp.actorDumpInterval(tasktype.MarketDealDump, 120))
+	require.Equal(t, 0, sp.actorDumpInterval(tasktype.PowerClaimDump, 120))
+	require.Equal(t, 120, sp.actorDumpInterval(tasktype.MinerActorDump, 120))
+}
+
+// dumpTestDataSource is a DataSource of a state without actors.
+type dumpTestDataSource struct {
+	tasks.DataSource
+}
+
+func (dumpTestDataSource) StateListActors(context.Context, types.TipSetKey) ([]address.Address, error) {
+	return nil, nil
+}
+
+func (dumpTestDataSource) SetIdRobustAddressMap(context.Context, types.TipSetKey) error {
+	return nil
+}
+
+type dumpTestProcessor struct{}
+
+func (dumpTestProcessor) ProcessPeriodicActorDump(context.Context, *types.TipSet, tasks.ActorStatesByType) (model.Persistable, *visormodel.ProcessingReport, error) {
+	return nil, &visormodel.ProcessingReport{}, nil
+}
+
+func TestStartPeriodicActorDumpRunsDueTasks(t *testing.T) {
+	sp := &StateProcessor{
+		api:  dumpTestDataSource{},
+		name: t.Name(),
+		periodicActorDumpProcessors: map[string]PeriodicActorDumpProcessor{
+			tasktype.MinerActorDump: dumpTestProcessor{},
+			tasktype.MarketDealDump: dumpTestProcessor{},
+			tasktype.PowerClaimDump: dumpTestProcessor{},
+		},
+	}
+	WithActorDumpIntervals(map[string]int{tasktype.MarketDealDump: 4, tasktype.PowerClaimDump: 0})(sp)
+
+	// at height 10 with a job interval of 5 the miner dump (job interval) and power claim dump (every epoch) are due,
+	// the market deal dump (every 4 epochs) is not.
+	ts := limitTestTipSet(t)
+	results := make(chan *Result, len(sp.periodicActorDumpProcessors))
+	names := sp.startPeriodicActorDump(context.Background(), ts, 5, results)
+	sp.pwg.Wait()
+	close(results)
+
+	var ran []string
+	for res := range results {
+		require.NoError(t, res.Error)
+		ran = append(ran, res.Task)
+	}
+	sort.Strings(names)
+	sort.Strings(ran)
+	require.Equal(t, []string{tasktype.MinerActorDump, tasktype.PowerClaimDump}, names)
+	require.Equal(t, names, ran)
+
+	// no task is due at height 10 with a job interval of 3 but the power claim dump
+	results = make(chan *Result, len(sp.periodicActorDumpProcessors))
+	names = sp.startPeriodicActorDump(context.Background(), ts, 3, results)
+	sp.pwg.Wait()
+	require.Equal(t, []string{tasktype.PowerClaimDump}, names)
+}
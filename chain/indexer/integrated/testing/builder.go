@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/filecoin-project/lily/chain/indexer"
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/chain/indexer/integrated/tipset"
 	"github.com/filecoin-project/lily/model"
 
@@ -30,6 +31,14 @@ func (t *MockIndexBuilder) WithInterval(_ int) tipset.IndexerBuilder {
 	return t
 }
 
+func (t *MockIndexBuilder) WithTaskLimiter(_ processor.TaskLimiter) tipset.IndexerBuilder {
+	return t
+}
+
+func (t *MockIndexBuilder) WithActorDumpIntervals(_ map[string]int) tipset.IndexerBuilder {
+	return t
+}
+
 func (t *MockIndexBuilder) Build() (tipset.Indexer, error) {
 	return t.MockIndexer, nil
 }
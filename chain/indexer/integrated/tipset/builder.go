@@ -3,6 +3,7 @@ package tipset
 import (
 	"context"
 
+	"github.com/filecoin-project/lily/chain/indexer/integrated/processor"
 	"github.com/filecoin-project/lily/tasks"
 
 	"github.com/filecoin-project/lotus/chain/types"
@@ -11,6 +12,8 @@ import (
 type IndexerBuilder interface {
 	WithTasks(tasks []string) IndexerBuilder
 	WithInterval(interval int) IndexerBuilder
+	WithTaskLimiter(limiter processor.TaskLimiter) IndexerBuilder
+	WithActorDumpIntervals(intervals map[string]int) IndexerBuilder
 	Build() (Indexer, error)
 	Name() string
 }
@@ -53,6 +56,22 @@ func (b *Builder) WithInterval(interval int) IndexerBuilder {
 	return b
 }
 
+// WithTaskLimiter sets the TaskLimiter that admits the indexer's tasks for execution and bounds their duration.
+func (b *Builder) WithTaskLimiter(limiter processor.TaskLimiter) IndexerBuilder {
+	b.add(func(ti *TipSetIndexer) {
+		ti.limiter = limiter
+	})
+	return b
+}
+
+// WithActorDumpIntervals sets the interval in epochs at which each of the indexer's periodic actor dump tasks runs.
+func (b *Builder) WithActorDumpIntervals(intervals map[string]int) IndexerBuilder {
+	b.add(func(ti *TipSetIndexer) {
+		ti.actorDumpIntervals = intervals
+	})
+	return b
+}
+
 func (b *Builder) Build() (Indexer, error) {
 	ti := &TipSetIndexer{
 		name: b.name,
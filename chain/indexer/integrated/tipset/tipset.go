@@ -31,6 +31,9 @@ type TipSetIndexer struct {
 	node      taskapi.DataSource
 	taskNames []string
 	Interval  int
+	limiter   processor.TaskLimiter
+
+	actorDumpIntervals map[string]int
 
 	processor *processor.StateProcessor
 }
@@ -41,7 +44,14 @@ func (ti *TipSetIndexer) init() error {
 		return err
 	}
 
-	ti.processor, err = processor.New(ti.node, ti.name, indexerTasks)
+	var opts []processor.StateProcessorOpt
+	if ti.limiter != nil {
+		opts = append(opts, processor.WithTaskLimiter(ti.limiter))
+	}
+	if len(ti.actorDumpIntervals) > 0 {
+		opts = append(opts, processor.WithActorDumpIntervals(ti.actorDumpIntervals))
+	}
+	ti.processor, err = processor.New(ti.node, ti.name, indexerTasks, opts...)
 	if err != nil {
 		return err
 	}
@@ -163,7 +173,10 @@ func (ti *TipSetIndexer) TipSet(ctx context.Context, ts *types.TipSet) (chan *Re
 					res.Report[idx].StartedAt = res.StartedAt
 					res.Report[idx].CompletedAt = res.CompletedAt
 
-					if err := res.Report[idx].ErrorsDetected; err != nil {
+					if res.Report[idx].Status == visormodel.ProcessingStatusSkip {
+						// the task was skipped by the processor, keep the reason it was given.
+						llt.Warnw("task skipped", "info", res.Report[idx].StatusInformation)
+					} else if err := res.Report[idx].ErrorsDetected; err != nil {
 						// because error is just an interface it may hold a value of any concrete type that implements it, and if
 						// said type has unexported fields json marshaling will fail when persisting.
 						e, ok := err.(error)
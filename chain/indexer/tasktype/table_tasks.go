@@ -56,6 +56,22 @@ const (
 	FEVMActorDump                  = "fevm_actor_dumps"
 	MinerActorDump                 = "miner_actor_dumps"
 	BuiltInActorEvent              = "builtin_actor_event"
+	FilLedgerEntry                 = "fil_ledger"
+	MinerBlockReward               = "miner_block_rewards"
+	GasFeePercentiles              = "gas_fee_percentiles"
+	MarketDealDump                 = "market_deal_dumps"
+	PowerClaimDump                 = "power_claim_dumps"
+	MultisigActorDump              = "multisig_actor_dumps"
+	VerifiedRegistryDump           = "verified_registry_dumps"
+	DataCapBalanceDump             = "data_cap_balance_dumps"
+	DDOPiece                       = "ddo_pieces"
+	BuiltInVerifierBalanceEvent    = "builtin_verifier_balance_events"
+	BuiltInAllocationEvent         = "builtin_allocation_events"
+	BuiltInClaimEvent              = "builtin_claim_events"
+	BuiltInDealEvent               = "builtin_deal_events"
+	BuiltInSectorEvent             = "builtin_sector_events"
+	ChainEconomicsProjection       = "chain_economics_projections"
+	MultisigInfo                   = "multisig_info"
 )
 
 var AllTableTasks = []string{
@@ -113,6 +129,22 @@ var AllTableTasks = []string{
 	MinerActorDump,
 	BuiltInActorEvent,
 	MinerSectorDealV2,
+	FilLedgerEntry,
+	MinerBlockReward,
+	GasFeePercentiles,
+	MarketDealDump,
+	PowerClaimDump,
+	MultisigActorDump,
+	VerifiedRegistryDump,
+	DataCapBalanceDump,
+	DDOPiece,
+	BuiltInVerifierBalanceEvent,
+	BuiltInAllocationEvent,
+	BuiltInClaimEvent,
+	BuiltInDealEvent,
+	BuiltInSectorEvent,
+	ChainEconomicsProjection,
+	MultisigInfo,
 }
 
 var TableLookup = map[string]struct{}{
@@ -170,6 +202,22 @@ var TableLookup = map[string]struct{}{
 	MinerActorDump:                 {},
 	BuiltInActorEvent:              {},
 	MinerSectorDealV2:              {},
+	FilLedgerEntry:                 {},
+	MinerBlockReward:               {},
+	GasFeePercentiles:              {},
+	MarketDealDump:                 {},
+	PowerClaimDump:                 {},
+	MultisigActorDump:              {},
+	VerifiedRegistryDump:           {},
+	DataCapBalanceDump:             {},
+	DDOPiece:                       {},
+	BuiltInVerifierBalanceEvent:    {},
+	BuiltInAllocationEvent:         {},
+	BuiltInClaimEvent:              {},
+	BuiltInDealEvent:               {},
+	BuiltInSectorEvent:             {},
+	ChainEconomicsProjection:       {},
+	MultisigInfo:                   {},
 }
 
 var TableComment = map[string]string{
@@ -225,8 +273,24 @@ var TableComment = map[string]string{
 	FEVMTrace:                      ``,
 	FEVMActorDump:                  ``,
 	MinerActorDump:                 ``,
-	BuiltInActorEvent:              ``,
+	BuiltInActorEvent:              `BuiltInActorEvent is a builtin actor event stored with its entries as json. Events of a type with a typed table are not stored when the task of the typed table runs in the same job.`,
 	MinerSectorDealV2:              ``,
+	FilLedgerEntry:                 `FilLedgerEntry is a debit or credit of the balance of an actor caused by executing the messages of a tipset.`,
+	MinerBlockReward:               `MinerBlockReward is the reward paid to the miner of a block when the tipset containing the block is executed.`,
+	GasFeePercentiles:              `GasFeePercentiles are the percentiles of the gas prices of the messages to a method of an actor family executed in a tipset.`,
+	MarketDealDump:                 `MarketDealDump is a snapshot of a deal in the storage market actor state.`,
+	PowerClaimDump:                 `PowerClaimDump is a snapshot of the power claim of a miner in the storage power actor state.`,
+	MultisigActorDump:              `MultisigActorDump is a snapshot of the state of a multisig actor.`,
+	VerifiedRegistryDump:           `VerifiedRegistryDump is a snapshot of a verifier or a verified client in the verified registry actor state.`,
+	DataCapBalanceDump:             `DataCapBalanceDump is a snapshot of the DataCap balance of a client in the datacap actor state.`,
+	DDOPiece:                       `DDOPiece is a piece of data onboarded into a sector, decoded from the piece list of a sector-activated or sector-updated event and matched with the verified registry claim and market deal activated for the piece.`,
+	BuiltInVerifierBalanceEvent:    `VerifierBalanceEvent is a verifier-balance event emitted by the verified registry actor when the datacap allowance of a verifier changes.`,
+	BuiltInAllocationEvent:         `AllocationEvent is an allocation or allocation-removed event emitted by the verified registry actor when datacap is allocated to a piece or an expired allocation is removed.`,
+	BuiltInClaimEvent:              `ClaimEvent is a claim, claim-updated or claim-removed event emitted by the verified registry actor when a provider claims an allocation, the term of a claim is extended or an expired claim is removed.`,
+	BuiltInDealEvent:               `DealEvent is a deal-published, deal-activated, deal-terminated or deal-completed event emitted by the storage market actor as a deal moves through its lifecycle.`,
+	BuiltInSectorEvent:             `SectorEvent is a sector-precommitted, sector-activated, sector-updated or sector-terminated event emitted by a miner actor as a sector moves through its lifecycle.`,
+	ChainEconomicsProjection:       `ChainEconomicsProjection holds figures derived from the reward and power actor states of a tipset: how the network power compares to the baseline, how much of the storage mining allocation has been minted by the simple and baseline schedules and the initial pledge a new sector would require.`,
+	MultisigInfo:                   `MultisigInfo is the configuration of a multisig actor, recorded when the actor is created and whenever its signers, threshold or vesting parameters change, along with its locked and unlocked balance at that time.`,
 }
 
 var TableFieldComments = map[string]map[string]string{
@@ -457,4 +521,195 @@ var TableFieldComments = map[string]map[string]string{
 	},
 	BuiltInActorEvent: {},
 	MinerSectorDealV2: {},
+	FilLedgerEntry: {
+		"Address":      "Address is the ID address of the actor whose balance changed.",
+		"Amount":       "Amount attoFIL credited to the actor, negative for debits.",
+		"Counterparty": "Counterparty is the ID address of the actor on the other side of the entry.",
+		"Height":       "Height message was executed at.",
+		"Index":        "Index orders the entries of the state transition, each debit is immediately followed by its credit.",
+		"MessageCid":   "MessageCid of the on-chain or implicit message whose execution changed the balance.",
+		"Reason":       "Reason the balance changed.",
+		"StateRoot":    "StateRoot message was applied to.",
+	},
+	MinerBlockReward: {
+		"BlockCid":     "BlockCid is the CID of the block.",
+		"BlockReward":  "BlockReward attoFIL paid to the miner by the reward actor for winning the block.",
+		"GrossReward":  "GrossReward attoFIL paid to the miner, the sum of the block reward and the tips.",
+		"Height":       "Height of the block.",
+		"LockedReward": "LockedReward attoFIL of the gross reward locked in the vesting funds of the miner.",
+		"Miner":        "Miner is the ID address of the miner of the block.",
+		"Penalty":      "Penalty attoFIL charged to the miner for including invalid messages in the block.",
+		"StateRoot":    "StateRoot the reward was applied to.",
+		"Tips":         "Tips attoFIL paid to the miner by the messages first included in the block.",
+		"VestedReward": "VestedReward attoFIL of the gross reward available to the miner immediately.",
+		"WinCount":     "WinCount is the number of reward units won by the block.",
+	},
+	GasFeePercentiles: {
+		"ActorFamily":          "ActorFamily of the receivers of the messages, all for every message of the tipset.",
+		"BaseFee":              "BaseFee attoFIL per unit gas paid by the messages.",
+		"BelowBaseFeeCount":    "BelowBaseFeeCount is the number of messages with a fee cap below the base fee.",
+		"EffectiveGasPriceP10": "EffectiveGasPriceP10 attoFIL per unit gas paid by the sender, the lesser of the fee cap and the base fee plus premium.",
+		"GasFeeCapP10":         "GasFeeCapP10 attoFIL per unit gas.",
+		"GasPremiumP10":        "GasPremiumP10 attoFIL per unit gas.",
+		"Height":               "Height messages were executed at.",
+		"MessageCount":         "MessageCount is the number of messages.",
+		"Method":               "Method called by the messages, -1 for every method.",
+		"StateRoot":            "StateRoot messages were applied to.",
+	},
+	MarketDealDump: {
+		"ClientCollateral":     "ClientCollateral attoFIL locked by the client.",
+		"ClientID":             "ClientID is the ID address of the client.",
+		"DealID":               "DealID is the identifier of the deal.",
+		"EndEpoch":             "EndEpoch is the epoch the deal expires at.",
+		"Height":               "Height of the snapshot.",
+		"IsVerified":           "IsVerified is true when the deal is paid for with DataCap.",
+		"LastUpdateEpoch":      "LastUpdateEpoch is the epoch the deal was last updated at, -1 if never updated.",
+		"PaddedPieceSize":      "PaddedPieceSize is the padded size of the piece in bytes.",
+		"PieceCID":             "PieceCID is the CID of the data stored by the deal.",
+		"ProviderCollateral":   "ProviderCollateral attoFIL locked by the provider.",
+		"ProviderID":           "ProviderID is the ID address of the storage provider.",
+		"SectorID":             "SectorID is the sector the deal is stored in, zero if the deal is not yet activated or the network does not record it.",
+		"SectorStartEpoch":     "SectorStartEpoch is the epoch the deal was activated at, -1 if not yet activated.",
+		"SlashEpoch":           "SlashEpoch is the epoch the deal was slashed at, -1 if never slashed.",
+		"StartEpoch":           "StartEpoch is the epoch the deal is expected to start at.",
+		"StateRoot":            "StateRoot the snapshot was taken from.",
+		"StoragePricePerEpoch": "StoragePricePerEpoch attoFIL paid by the client each epoch of the deal.",
+		"UnpaddedPieceSize":    "UnpaddedPieceSize is the unpadded size of the piece in bytes.",
+	},
+	PowerClaimDump: {
+		"Height":          "Height of the snapshot.",
+		"MinerID":         "MinerID is the ID address of the miner.",
+		"QualityAdjPower": "QualityAdjPower is the quality adjusted power of the miner in bytes.",
+		"RawBytePower":    "RawBytePower is the raw byte power of the miner in bytes.",
+		"StateRoot":       "StateRoot the snapshot was taken from.",
+	},
+	MultisigActorDump: {
+		"Balance":             "Balance attoFIL held by the multisig.",
+		"Height":              "Height of the snapshot.",
+		"InitialBalance":      "InitialBalance attoFIL subject to vesting.",
+		"LockedBalance":       "LockedBalance attoFIL of the balance still locked by vesting.",
+		"MultisigID":          "MultisigID is the ID address of the multisig actor.",
+		"PendingTransactions": "PendingTransactions is the number of transactions awaiting approval.",
+		"Signers":             "Signers are the ID addresses of the signers of the multisig.",
+		"StartEpoch":          "StartEpoch is the epoch vesting started at.",
+		"StateRoot":           "StateRoot the snapshot was taken from.",
+		"Threshold":           "Threshold is the number of signers required to approve a transaction.",
+		"UnlockDuration":      "UnlockDuration is the number of epochs the initial balance vests over.",
+	},
+	VerifiedRegistryDump: {
+		"Address":   "Address is the ID address of the verifier or client.",
+		"DataCap":   "DataCap is the DataCap allowance of the verifier or client in bytes.",
+		"Height":    "Height of the snapshot.",
+		"Kind":      "Kind is either verifier or client.",
+		"StateRoot": "StateRoot the snapshot was taken from.",
+	},
+	DataCapBalanceDump: {
+		"Address":   "Address is the ID address of the client.",
+		"DataCap":   "DataCap is the DataCap balance of the client in bytes.",
+		"Height":    "Height of the snapshot.",
+		"StateRoot": "StateRoot the snapshot was taken from.",
+	},
+	DDOPiece: {
+		"ClaimID":     "ClaimID is the identifier of the verified registry claim for the piece, null if the piece is not claimed.",
+		"Client":      "Client is the ID address of the client of the claim or deal, null if the piece has neither.",
+		"DealID":      "DealID is the identifier of the market deal for the piece, null if the piece was onboarded without a deal.",
+		"EventType":   "EventType is sector-activated for new sectors and sector-updated for snapped sectors.",
+		"Height":      "Height the piece was activated at.",
+		"MessageCid":  "MessageCid is the CID of the message that activated the piece.",
+		"Miner":       "Miner is the ID address of the miner the sector belongs to.",
+		"PieceCID":    "PieceCID is the CID of the piece.",
+		"PieceSize":   "PieceSize is the padded size of the piece in bytes.",
+		"SectorID":    "SectorID is the number of the sector the piece was activated in.",
+		"StateRoot":   "StateRoot the activating message was applied to.",
+		"UnsealedCid": "UnsealedCid is the CID of the unsealed data of the sector, null for sectors without data.",
+		"Verified":    "Verified is true when the piece is covered by a verified registry claim or a verified deal.",
+	},
+	BuiltInVerifierBalanceEvent: {
+		"Balance":    "Balance is the datacap allowance of the verifier after the change.",
+		"Emitter":    "Emitter is the address of the actor that emitted the event.",
+		"EventIdx":   "EventIdx is the index of the event among the events emitted when executing the tipset.",
+		"EventType":  "EventType is the type of the event.",
+		"Height":     "Height of the tipset whose execution emitted the event.",
+		"MessageCid": "MessageCid is the CID of the message whose execution emitted the event.",
+		"Verifier":   "Verifier is the ID address of the verifier.",
+	},
+	BuiltInAllocationEvent: {
+		"AllocationID": "AllocationID is the identifier of the allocation.",
+		"Client":       "Client is the ID address of the client the datacap is allocated from.",
+		"Emitter":      "Emitter is the address of the actor that emitted the event.",
+		"EventIdx":     "EventIdx is the index of the event among the events emitted when executing the tipset.",
+		"EventType":    "EventType is the type of the event.",
+		"Expiration":   "Expiration is the epoch by which the provider must claim the allocation.",
+		"Height":       "Height of the tipset whose execution emitted the event.",
+		"MessageCid":   "MessageCid is the CID of the message whose execution emitted the event.",
+		"PieceCid":     "PieceCid is the CID of the allocated piece.",
+		"PieceSize":    "PieceSize is the padded size of the allocated piece in bytes.",
+		"Provider":     "Provider is the ID address of the provider the piece is allocated to.",
+		"TermMax":      "TermMax is the maximum duration in epochs the client may extend the claim of the piece to.",
+		"TermMin":      "TermMin is the minimum duration in epochs the provider must commit to store the piece for.",
+	},
+	BuiltInClaimEvent: {
+		"ClaimID":    "ClaimID is the identifier of the claim.",
+		"Client":     "Client is the ID address of the client the datacap of the claim was allocated from.",
+		"Emitter":    "Emitter is the address of the actor that emitted the event.",
+		"EventIdx":   "EventIdx is the index of the event among the events emitted when executing the tipset.",
+		"EventType":  "EventType is the type of the event.",
+		"Height":     "Height of the tipset whose execution emitted the event.",
+		"MessageCid": "MessageCid is the CID of the message whose execution emitted the event.",
+		"PieceCid":   "PieceCid is the CID of the claimed piece.",
+		"PieceSize":  "PieceSize is the padded size of the claimed piece in bytes.",
+		"Provider":   "Provider is the ID address of the provider storing the claimed piece.",
+		"Sector":     "Sector is the number of the sector holding the claimed piece.",
+		"TermMax":    "TermMax is the maximum duration in epochs the client may extend the claim to.",
+		"TermMin":    "TermMin is the minimum duration in epochs the provider committed to store the piece for.",
+		"TermStart":  "TermStart is the epoch the claim started at.",
+	},
+	BuiltInDealEvent: {
+		"Client":     "Client is the ID address of the client of the deal.",
+		"DealID":     "DealID is the identifier of the deal.",
+		"Emitter":    "Emitter is the address of the actor that emitted the event.",
+		"EventIdx":   "EventIdx is the index of the event among the events emitted when executing the tipset.",
+		"EventType":  "EventType is the type of the event.",
+		"Height":     "Height of the tipset whose execution emitted the event.",
+		"MessageCid": "MessageCid is the CID of the message whose execution emitted the event.",
+		"Provider":   "Provider is the ID address of the provider of the deal.",
+	},
+	BuiltInSectorEvent: {
+		"Emitter":     "Emitter is the address of the actor that emitted the event.",
+		"EventIdx":    "EventIdx is the index of the event among the events emitted when executing the tipset.",
+		"EventType":   "EventType is the type of the event.",
+		"Height":      "Height of the tipset whose execution emitted the event.",
+		"MessageCid":  "MessageCid is the CID of the message whose execution emitted the event.",
+		"PieceCids":   "PieceCids are the CIDs of the pieces of an activated or updated sector.",
+		"PieceSizes":  "PieceSizes are the padded sizes in bytes of the pieces of an activated or updated sector.",
+		"Sector":      "Sector is the number of the sector.",
+		"UnsealedCid": "UnsealedCid is the CID of the unsealed data of an activated or updated sector, null for sectors without data.",
+	},
+	ChainEconomicsProjection: {
+		"BaselineCrossed":      "BaselineCrossed is true when the raw byte power of the network, which the reward actor compares to the baseline, is at or above the baseline power.",
+		"BaselineMinted":       "BaselineMinted is the amount of attoFIL minted by the baseline schedule, which depends on the effective network time.",
+		"BaselinePower":        "BaselinePower is the baseline power of the epoch in bytes.",
+		"BaselineReward":       "BaselineReward is the amount of attoFIL of the reward of the epoch minted by the baseline schedule.",
+		"EffectiveNetworkTime": "EffectiveNetworkTime is the epoch at which the cumulative baseline power reaches the cumulative realized power.",
+		"Height":               "Height of the tipset the figures are derived from.",
+		"NetworkQaPower":       "NetworkQaPower is the quality adjusted power of the network in bytes.",
+		"NetworkRawPower":      "NetworkRawPower is the raw byte power of the network in bytes.",
+		"SectorInitialPledge":  "SectorInitialPledge is the initial pledge in attoFIL of a 32GiB sector without deals.",
+		"SimpleMinted":         "SimpleMinted is the amount of attoFIL minted by the simple schedule, which only depends on time.",
+		"SimpleReward":         "SimpleReward is the amount of attoFIL of the reward of the epoch minted by the simple schedule.",
+		"StateRoot":            "StateRoot the figures are derived from.",
+	},
+	MultisigInfo: {
+		"Balance":         "Balance is the balance of the multisig in attoFIL.",
+		"Height":          "Height the configuration was observed at.",
+		"InitialBalance":  "InitialBalance is the amount of attoFIL locked at the start of the vesting.",
+		"LockedBalance":   "LockedBalance is the part of the initial balance in attoFIL that has not vested yet.",
+		"MultisigID":      "MultisigID is the ID address of the multisig actor.",
+		"Signers":         "Signers are the addresses of the signers of the multisig.",
+		"StartEpoch":      "StartEpoch is the epoch the vesting of the initial balance started at.",
+		"StateRoot":       "StateRoot the configuration was observed in.",
+		"Threshold":       "Threshold is the number of signers required to approve a transaction.",
+		"UnlockDuration":  "UnlockDuration is the number of epochs over which the initial balance vests.",
+		"UnlockedBalance": "UnlockedBalance is the part of the balance in attoFIL that may be spent.",
+	},
 }
@@ -57,6 +57,7 @@ var TaskLookup = map[string][]string{
 	},
 	ActorStatesMultisigTask: {
 		MultisigTransaction,
+		MultisigInfo,
 	},
 	ActorStatesVerifreg: {
 		VerifiedRegistryVerifier,
@@ -80,10 +81,18 @@ var TaskLookup = map[string][]string{
 		MessageParam,
 		ReceiptReturn,
 		BuiltInActorEvent,
+		BuiltInVerifierBalanceEvent,
+		BuiltInAllocationEvent,
+		BuiltInClaimEvent,
+		BuiltInDealEvent,
+		BuiltInSectorEvent,
+		GasFeePercentiles,
+		DDOPiece,
 	},
 	ChainEconomicsTask: {
 		ChainEconomics,
 		ChainEconomicsV2,
+		ChainEconomicsProjection,
 	},
 	MultisigApprovalsTask: {
 		MultisigApproval,
@@ -92,6 +101,8 @@ var TaskLookup = map[string][]string{
 		InternalMessage,
 		InternalParsedMessage,
 		VMMessage,
+		FilLedgerEntry,
+		MinerBlockReward,
 	},
 	ChainConsensusTask: {
 		ChainConsensus,
@@ -107,6 +118,11 @@ var TaskLookup = map[string][]string{
 	ActorDump: {
 		FEVMActorDump,
 		MinerActorDump,
+		MarketDealDump,
+		PowerClaimDump,
+		MultisigActorDump,
+		VerifiedRegistryDump,
+		DataCapBalanceDump,
 	},
 }
 
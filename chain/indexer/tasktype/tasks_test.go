@@ -43,7 +43,7 @@ func TestMakeTaskNamesAlias(t *testing.T) {
 		},
 		{
 			taskAlias: tasktype.ActorStatesMultisigTask,
-			tasks:     []string{tasktype.MultisigTransaction},
+			tasks:     []string{tasktype.MultisigTransaction, tasktype.MultisigInfo},
 		},
 		{
 			taskAlias: tasktype.ActorStatesVerifreg,
@@ -56,11 +56,12 @@ func TestMakeTaskNamesAlias(t *testing.T) {
 		{
 			taskAlias: tasktype.MessagesTask,
 			tasks: []string{tasktype.Message, tasktype.ParsedMessage, tasktype.Receipt, tasktype.GasOutputs, tasktype.MessageGasEconomy, tasktype.BlockMessage, tasktype.ActorEvent, tasktype.MessageParam, tasktype.ReceiptReturn,
-				tasktype.BuiltInActorEvent},
+				tasktype.BuiltInActorEvent, tasktype.BuiltInVerifierBalanceEvent, tasktype.BuiltInAllocationEvent, tasktype.BuiltInClaimEvent,
+				tasktype.BuiltInDealEvent, tasktype.BuiltInSectorEvent, tasktype.GasFeePercentiles, tasktype.DDOPiece},
 		},
 		{
 			taskAlias: tasktype.ChainEconomicsTask,
-			tasks:     []string{tasktype.ChainEconomics, tasktype.ChainEconomicsV2},
+			tasks:     []string{tasktype.ChainEconomics, tasktype.ChainEconomicsV2, tasktype.ChainEconomicsProjection},
 		},
 		{
 			taskAlias: tasktype.MultisigApprovalsTask,
@@ -68,7 +69,7 @@ func TestMakeTaskNamesAlias(t *testing.T) {
 		},
 		{
 			taskAlias: tasktype.ImplicitMessageTask,
-			tasks:     []string{tasktype.InternalMessage, tasktype.InternalParsedMessage, tasktype.VMMessage},
+			tasks:     []string{tasktype.InternalMessage, tasktype.InternalParsedMessage, tasktype.VMMessage, tasktype.FilLedgerEntry, tasktype.MinerBlockReward},
 		},
 		{
 			taskAlias: tasktype.ChainConsensusTask,
@@ -102,7 +103,7 @@ func TestMakeAllTaskAliasNames(t *testing.T) {
 }
 
 func TestMakeAllTaskNames(t *testing.T) {
-	const TotalTableTasks = 54
+	const TotalTableTasks = 70
 	actual, err := tasktype.MakeTaskNames(tasktype.AllTableTasks)
 	require.NoError(t, err)
 	// if this test fails it means a new task name was added, update the above test
@@ -0,0 +1,128 @@
+package prune
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+
+	"github.com/filecoin-project/lily/storage"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/chain/prune")
+
+type API interface {
+	ChainHead(context.Context) (*types.TipSet, error)
+}
+
+// Storage is a storage that removes data outside of the retention configured for its tables.
+type Storage interface {
+	Retention() map[string]int64
+	Prune(ctx context.Context, table string, below int64, batchSize int64) (*storage.PruneResult, error)
+}
+
+func NewPruner(api API, strg Storage, name string, interval time.Duration, batchSize int64) (*Pruner, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("pruner interval must be greater than zero: %d", interval)
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("pruner batch size must be greater than zero: %d", batchSize)
+	}
+	if len(strg.Retention()) == 0 {
+		return nil, fmt.Errorf("storage has no tables with a retention")
+	}
+	return &Pruner{
+		api:       api,
+		storage:   strg,
+		name:      name,
+		interval:  interval,
+		batchSize: batchSize,
+	}, nil
+}
+
+// Pruner is a job that periodically removes the data of each table with a retention that is more than the retention
+// below the chain head.
+type Pruner struct {
+	api       API
+	storage   Storage
+	name      string
+	interval  time.Duration
+	batchSize int64
+	done      chan struct{}
+}
+
+// Run prunes the storage each interval and continues until the context is done or a fatal error occurs.
+func (p *Pruner) Run(ctx context.Context) error {
+	// init the done channel for each run since jobs may be started and stopped.
+	p.done = make(chan struct{})
+	defer close(p.done)
+
+	// Perform an initial prune before waiting
+	if err := p.Prune(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := p.Prune(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *Pruner) Done() <-chan struct{} {
+	return p.done
+}
+
+func (p *Pruner) Details() (string, map[string]interface{}) {
+	return "pruner", map[string]interface{}{
+		"name":      p.name,
+		"interval":  p.interval,
+		"batchSize": p.batchSize,
+		"retention": p.storage.Retention(),
+	}
+}
+
+// Prune removes the data of each table below its retention from the current chain head.
+func (p *Pruner) Prune(ctx context.Context) error {
+	ctx, span := otel.Tracer("").Start(ctx, "Pruner.Prune")
+	defer span.End()
+
+	head, err := p.api.ChainHead(ctx)
+	if err != nil {
+		return fmt.Errorf("getting chain head: %w", err)
+	}
+
+	retention := p.storage.Retention()
+	tables := make([]string, 0, len(retention))
+	for table := range retention {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		below := int64(head.Height()) - retention[table]
+		if below <= 0 {
+			continue
+		}
+		start := time.Now()
+		res, err := p.storage.Prune(ctx, table, below, p.batchSize)
+		if err != nil {
+			return fmt.Errorf("pruning %s: %w", table, err)
+		}
+		log.Infow("pruned table", "table", table, "below", below, "rows", res.Rows, "partitions", len(res.Partitions), "duration", time.Since(start))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package prune
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lily/storage"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+type fakeAPI struct {
+	head *types.TipSet
+}
+
+func (a *fakeAPI) ChainHead(context.Context) (*types.TipSet, error) {
+	return a.head, nil
+}
+
+type pruneCall struct {
+	table     string
+	below     int64
+	batchSize int64
+}
+
+type fakeStorage struct {
+	retention map[string]int64
+	calls     []pruneCall
+}
+
+func (s *fakeStorage) Retention() map[string]int64 {
+	return s.retention
+}
+
+func (s *fakeStorage) Prune(_ context.Context, table string, below int64, batchSize int64) (*storage.PruneResult, error) {
+	s.calls = append(s.calls, pruneCall{table: table, below: below, batchSize: batchSize})
+	return &storage.PruneResult{Table: table, Below: below}, nil
+}
+
+func TestPrunerRespectsRetention(t *testing.T) {
+	c := cid.MustParse("bafy2bzacedrunl6pmqwxtmhr5cyjiy3p4qx5qqffjdwjzouyyzrirrycrabwe")
+	miner, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	head, err := types.NewTipSet([]*types.BlockHeader{{
+		Miner:                 miner,
+		Height:                1000,
+		ParentStateRoot:       c,
+		ParentMessageReceipts: c,
+		Messages:              c,
+		Ticket:                &types.Ticket{VRFProof: []byte{1}},
+		ParentBaseFee:         abi.NewTokenAmount(100),
+	}})
+	require.NoError(t, err)
+
+	strg := &fakeStorage{retention: map[string]int64{"vm_messages": 100, "messages": 400, "actor_states": 2000}}
+	p, err := NewPruner(&fakeAPI{head: head}, strg, t.Name(), time.Minute, 50)
+	require.NoError(t, err)
+
+	require.NoError(t, p.Prune(context.Background()))
+
+	// tables whose retention reaches back beyond genesis are not pruned
+	require.Equal(t, []pruneCall{
+		{table: "messages", below: 600, batchSize: 50},
+		{table: "vm_messages", below: 900, batchSize: 50},
+	}, strg.calls)
+}
@@ -0,0 +1,203 @@
+package watch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/chain/indexer"
+	"github.com/filecoin-project/lily/schedule"
+
+	"github.com/filecoin-project/lotus/chain/events"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// testChain builds a chain of tipsets from genesis to the highest of `heights`, heights missing from `heights` are
+// null rounds.
+func testChain(t *testing.T, heights ...abi.ChainEpoch) map[abi.ChainEpoch]*types.TipSet {
+	c := cid.MustParse("bafy2bzacedrunl6pmqwxtmhr5cyjiy3p4qx5qqffjdwjzouyyzrirrycrabwe")
+	miner, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	chain := map[abi.ChainEpoch]*types.TipSet{}
+	var parents []cid.Cid
+	for _, h := range heights {
+		ts, err := types.NewTipSet([]*types.BlockHeader{{
+			Miner:                 miner,
+			Height:                h,
+			Parents:               parents,
+			ParentStateRoot:       c,
+			ParentMessageReceipts: c,
+			Messages:              c,
+			Ticket:                &types.Ticket{VRFProof: []byte{1}},
+			ParentBaseFee:         abi.NewTokenAmount(100),
+		}})
+		require.NoError(t, err)
+		chain[h] = ts
+		parents = ts.Cids()
+	}
+	return chain
+}
+
+type testWatcherAPI struct {
+	mu       sync.Mutex
+	chain    map[abi.ChainEpoch]*types.TipSet
+	observer events.TipSetObserver
+}
+
+func (a *testWatcherAPI) Observe(obs events.TipSetObserver) *types.TipSet {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.observer = obs
+	return a.chain[0]
+}
+
+func (a *testWatcherAPI) Unregister(events.TipSetObserver) bool {
+	return true
+}
+
+func (a *testWatcherAPI) ChainGetTipSet(_ context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
+	for _, ts := range a.chain {
+		if ts.Key() == tsk {
+			return ts, nil
+		}
+	}
+	return nil, nil
+}
+
+// ChainGetTipSetByHeight returns the tipset at `h`, or the tipset before it if `h` is a null round, like lotus does.
+func (a *testWatcherAPI) ChainGetTipSetByHeight(_ context.Context, h abi.ChainEpoch, _ types.TipSetKey) (*types.TipSet, error) {
+	for ; h > 0; h-- {
+		if ts, ok := a.chain[h]; ok {
+			return ts, nil
+		}
+	}
+	return a.chain[0], nil
+}
+
+func (a *testWatcherAPI) apply(t *testing.T, from, to *types.TipSet) {
+	require.Eventually(t, func() bool {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return a.observer != nil
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, a.observer.Apply(context.Background(), from, to))
+}
+
+type indexed struct {
+	height abi.ChainEpoch
+	typ    indexer.IndexerType
+}
+
+// testIndexer records the tipsets it indexes, failing to fully index the heights in `fail` the first time they are
+// indexed.
+type testIndexer struct {
+	mu      sync.Mutex
+	fail    map[abi.ChainEpoch]bool
+	indexed []indexed
+}
+
+func (i *testIndexer) TipSet(_ context.Context, ts *types.TipSet, opts ...indexer.Option) (bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	typ := indexer.Undefined
+	for _, opt := range opts {
+		if opt.Type() == indexer.IndexTypeOpt {
+			typ = opt.Value().(indexer.IndexerType)
+		}
+	}
+	i.indexed = append(i.indexed, indexed{height: ts.Height(), typ: typ})
+	if i.fail[ts.Height()] {
+		delete(i.fail, ts.Height())
+		return false, nil
+	}
+	return true, nil
+}
+
+func (i *testIndexer) get() []indexed {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]indexed(nil), i.indexed...)
+}
+
+func runTestWatcher(t *testing.T, api *testWatcherAPI, idx *testIndexer, opts ...WatcherOpt) func() {
+	interval := WatcherBackfillInterval
+	WatcherBackfillInterval = 10 * time.Millisecond
+
+	w := NewWatcher(api, idx, t.Name(), &schedule.Reporter{}, append([]WatcherOpt{WithConfidence(0)}, opts...)...)
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- w.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		require.ErrorIs(t, <-errs, context.Canceled)
+		WatcherBackfillInterval = interval
+	}
+}
+
+func TestWatcherBackfillsSkippedHeights(t *testing.T) {
+	// height 3 is a null round
+	api := &testWatcherAPI{chain: testChain(t, 0, 1, 2, 4, 5)}
+	idx := &testIndexer{}
+	stop := runTestWatcher(t, api, idx)
+	defer stop()
+
+	// the apply event of height 2 is never delivered
+	api.apply(t, api.chain[0], api.chain[1])
+	api.apply(t, api.chain[2], api.chain[4])
+
+	require.Eventually(t, func() bool { return len(idx.get()) == 4 }, 5*time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	// the gap is backfilled, the null round is not indexed
+	require.Equal(t, []indexed{
+		{height: 0, typ: indexer.Watch},
+		{height: 1, typ: indexer.Watch},
+		{height: 4, typ: indexer.Watch},
+		{height: 2, typ: indexer.Index},
+	}, idx.get())
+}
+
+func TestWatcherBackfillsFailedHeights(t *testing.T) {
+	api := &testWatcherAPI{chain: testChain(t, 0, 1, 2)}
+	idx := &testIndexer{fail: map[abi.ChainEpoch]bool{1: true}}
+	stop := runTestWatcher(t, api, idx)
+	defer stop()
+
+	api.apply(t, api.chain[0], api.chain[1])
+	api.apply(t, api.chain[1], api.chain[2])
+
+	require.Eventually(t, func() bool { return len(idx.get()) == 4 }, 5*time.Second, time.Millisecond)
+	require.Equal(t, []indexed{
+		{height: 0, typ: indexer.Watch},
+		{height: 1, typ: indexer.Watch},
+		{height: 2, typ: indexer.Watch},
+		{height: 1, typ: indexer.Index},
+	}, idx.get())
+}
+
+func TestWatcherWithoutBacklogDoesNotBackfill(t *testing.T) {
+	api := &testWatcherAPI{chain: testChain(t, 0, 1, 2, 3)}
+	idx := &testIndexer{fail: map[abi.ChainEpoch]bool{1: true}}
+	stop := runTestWatcher(t, api, idx, WithMaxBacklog(0))
+	defer stop()
+
+	api.apply(t, api.chain[0], api.chain[1])
+	api.apply(t, api.chain[2], api.chain[3])
+
+	require.Eventually(t, func() bool { return len(idx.get()) == 3 }, 5*time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, []indexed{
+		{height: 0, typ: indexer.Watch},
+		{height: 1, typ: indexer.Watch},
+		{height: 3, typ: indexer.Watch},
+	}, idx.get())
+}
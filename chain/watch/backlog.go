@@ -0,0 +1,64 @@
+package watch
+
+import (
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// backlog is a bounded set of heights the watcher failed to index, either because their tipsets were never delivered to
+// the watcher or because indexing them did not complete. Heights are handed out lowest first so the oldest holes in the
+// dataset are filled before newer ones.
+type backlog struct {
+	mu      sync.Mutex
+	max     int
+	heights map[abi.ChainEpoch]struct{}
+}
+
+func newBacklog(max int) *backlog {
+	return &backlog{
+		max:     max,
+		heights: make(map[abi.ChainEpoch]struct{}),
+	}
+}
+
+// add records `height` in the backlog. It returns false if the height could not be recorded because the backlog is full.
+// Adding a height already in the backlog is a no-op.
+func (b *backlog) add(height abi.ChainEpoch) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.heights[height]; ok {
+		return true
+	}
+	if len(b.heights) >= b.max {
+		return false
+	}
+	b.heights[height] = struct{}{}
+	return true
+}
+
+// next removes and returns the lowest height in the backlog. It returns false if the backlog is empty.
+func (b *backlog) next() (abi.ChainEpoch, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.heights) == 0 {
+		return 0, false
+	}
+	first := true
+	var lowest abi.ChainEpoch
+	for h := range b.heights {
+		if first || h < lowest {
+			lowest = h
+			first = false
+		}
+	}
+	delete(b.heights, lowest)
+	return lowest, true
+}
+
+// len returns the number of heights in the backlog.
+func (b *backlog) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.heights)
+}
@@ -0,0 +1,56 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+func TestBacklog(t *testing.T) {
+	t.Run("returns lowest height first", func(t *testing.T) {
+		b := newBacklog(5)
+		require.True(t, b.add(12))
+		require.True(t, b.add(10))
+		require.True(t, b.add(11))
+		assert.Equal(t, 3, b.len())
+
+		for _, expected := range []abi.ChainEpoch{10, 11, 12} {
+			h, ok := b.next()
+			require.True(t, ok)
+			assert.Equal(t, expected, h)
+		}
+		_, ok := b.next()
+		assert.False(t, ok)
+	})
+
+	t.Run("ignores duplicates", func(t *testing.T) {
+		b := newBacklog(2)
+		require.True(t, b.add(10))
+		require.True(t, b.add(10))
+		assert.Equal(t, 1, b.len())
+	})
+
+	t.Run("rejects heights when full", func(t *testing.T) {
+		b := newBacklog(2)
+		require.True(t, b.add(10))
+		require.True(t, b.add(11))
+		assert.False(t, b.add(12))
+		assert.Equal(t, 2, b.len())
+
+		// a height already in the backlog is not rejected
+		assert.True(t, b.add(11))
+
+		_, ok := b.next()
+		require.True(t, ok)
+		assert.True(t, b.add(12))
+	})
+
+	t.Run("disabled when max is zero", func(t *testing.T) {
+		b := newBacklog(0)
+		assert.False(t, b.add(10))
+		assert.Equal(t, 0, b.len())
+	})
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/gammazero/workerpool"
 	logging "github.com/ipfs/go-log/v2"
 	"go.opencensus.io/stats"
 	"go.opentelemetry.io/otel"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/lily/chain/cache"
 	"github.com/filecoin-project/lily/chain/indexer"
 	"github.com/filecoin-project/lily/chain/indexer/tasktype"
@@ -28,6 +30,7 @@ type WatcherAPI interface {
 	Observe(obs events.TipSetObserver) *types.TipSet
 	Unregister(obs events.TipSetObserver) bool
 	ChainGetTipSet(ctx context.Context, tsk types.TipSetKey) (*types.TipSet, error)
+	ChainGetTipSetByHeight(ctx context.Context, h abi.ChainEpoch, tsk types.TipSetKey) (*types.TipSet, error)
 }
 
 type WatcherOpt func(w *Watcher)
@@ -62,6 +65,14 @@ func WithBufferSize(b int) WatcherOpt {
 	}
 }
 
+// WithMaxBacklog sets the maximum number of heights the watcher will remember for backfilling after failing to index
+// them. A value of zero disables backfilling.
+func WithMaxBacklog(m int) WatcherOpt {
+	return func(w *Watcher) {
+		w.maxBacklog = m
+	}
+}
+
 // Watcher is a task that indexes blocks by following the chain head.
 type Watcher struct {
 	// required
@@ -74,17 +85,21 @@ type Watcher struct {
 	poolSize   int
 	tasks      []string
 	interval   int
+	maxBacklog int // max number of heights held for backfilling
 
 	// created internally
-	done       chan struct{}
-	indexer    indexer.Indexer
-	cache      *cache.TipSetCache     // caches tipsets for possible reversion
-	pool       *workerpool.WorkerPool // used for async tipset indexing
-	tsObserver *TipSetObserver
+	done        chan struct{}
+	indexer     indexer.Indexer
+	cache       *cache.TipSetCache     // caches tipsets for possible reversion
+	pool        *workerpool.WorkerPool // used for async tipset indexing
+	tsObserver  *TipSetObserver
+	backlog     *backlog      // heights that failed to index, kept across restarts of the watcher.
+	lastIndexed *types.TipSet // last tipset submitted for indexing from head, only accessed from the Run loop.
 
 	// metric tracking
-	active int64 // must be accessed using atomic operations, updated automatically.
-	report *schedule.Reporter
+	active   int64 // must be accessed using atomic operations, updated automatically.
+	inflight int64 // number of tipsets submitted to the pool and not yet indexed, must be accessed using atomic operations.
+	report   *schedule.Reporter
 
 	// error handling
 	fatalMu sync.Mutex
@@ -97,6 +112,10 @@ var (
 	WatcherDefaultConcurrentWorkers = 1
 	WatcherDefaultTasks             = tasktype.AllTableTasks
 	WatcherDefaultInterval          = 1
+	WatcherDefaultMaxBacklog        = 100
+
+	// WatcherBackfillInterval is how often an idle watcher checks its backlog for heights to backfill.
+	WatcherBackfillInterval = 5 * time.Second
 )
 
 // NewWatcher creates a new Watcher. confidence sets the number of tipsets that will be held
@@ -113,12 +132,14 @@ func NewWatcher(api WatcherAPI, indexer indexer.Indexer, name string, r *schedul
 		poolSize:   WatcherDefaultConcurrentWorkers,
 		tasks:      WatcherDefaultTasks,
 		interval:   WatcherDefaultInterval,
+		maxBacklog: WatcherDefaultMaxBacklog,
 		report:     r,
 	}
 
 	for _, opt := range opts {
 		opt(w)
 	}
+	w.backlog = newBacklog(w.maxBacklog)
 	return w
 }
 
@@ -144,6 +165,8 @@ func (c *Watcher) close() {
 	c.pool.Stop()
 	// ensure we reset the tipset cache to avoid process stale state if watcher is restarted.
 	c.cache.Reset()
+	// the backlog is retained, but gaps are only detected between tipsets observed by the same run.
+	c.lastIndexed = nil
 	// unregister the observer
 	if !c.api.Unregister(c.tsObserver) {
 		log.Errorf("watcher failed to unregister observer %T", c.tsObserver)
@@ -162,10 +185,17 @@ func (c *Watcher) Run(ctx context.Context) error {
 	}
 	defer c.close()
 
+	backfill := time.NewTicker(WatcherBackfillInterval)
+	defer backfill.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-backfill.C:
+			if err := c.backfillAsync(ctx); err != nil {
+				return fmt.Errorf("backfill: %w", err)
+			}
 		case he, ok := <-c.tsObserver.HeadEvents():
 			if !ok {
 				return c.tsObserver.Err()
@@ -239,18 +269,31 @@ func (c *Watcher) indexTipSetAsync(ctx context.Context, ts *types.TipSet) error
 		return err
 	}
 
-	stats.Record(ctx, metrics.WatcherActiveWorkers.M(c.active))
+	stats.Record(ctx, metrics.WatcherActiveWorkers.M(atomic.LoadInt64(&c.active)))
 	stats.Record(ctx, metrics.WatcherWaitingWorkers.M(int64(c.pool.WaitingQueueSize())))
 	if c.pool.WaitingQueueSize() > c.pool.Size() {
 		log.Warnw("queuing worker in watcher pool", "waiting", c.pool.WaitingQueueSize(), "reporter", c.name)
 	}
-	log.Infow("submitting tipset for async indexing", "height", ts.Height(), "active", c.active, "reporter", c.name)
+	log.Infow("submitting tipset for async indexing", "height", ts.Height(), "active", atomic.LoadInt64(&c.active), "reporter", c.name)
 	c.report.UpdateCurrentHeight(int64(ts.Height()))
+
+	// tipsets between the last indexed tipset and this one were never delivered to the watcher, e.g. their apply
+	// events were dropped while the observer's buffer was full. Remember their heights so they may be backfilled,
+	// null rounds are discarded when the backlog is consumed.
+	if c.lastIndexed != nil && ts.Height() > c.lastIndexed.Height()+1 && ts.Parents() != c.lastIndexed.Key() {
+		for h := c.lastIndexed.Height() + 1; h < ts.Height(); h++ {
+			c.addToBacklog(ctx, h)
+		}
+	}
+	c.lastIndexed = ts
+
 	ctx, span := otel.Tracer("").Start(ctx, "Watcher.indexTipSetAsync")
+	atomic.AddInt64(&c.inflight, 1)
 	c.pool.Submit(func() {
 		atomic.AddInt64(&c.active, 1)
 		defer func() {
 			atomic.AddInt64(&c.active, -1)
+			atomic.AddInt64(&c.inflight, -1)
 			span.End()
 		}()
 
@@ -263,11 +306,80 @@ func (c *Watcher) indexTipSetAsync(ctx context.Context, ts *types.TipSet) error
 		}
 		if !success {
 			log.Warnw("watcher failed to fully index tipset", "height", ts.Height(), "tipset", ts.Key().String(), "reporter", c.name)
+			c.addToBacklog(ctx, ts.Height())
 		}
 	})
 	return nil
 }
 
+// backfillAsync submits the lowest height in the backlog for indexing. Indexing the head of the chain takes priority
+// over backfilling, a height is only submitted once the watcher has no other tipsets in flight.
+func (c *Watcher) backfillAsync(ctx context.Context) error {
+	if err := c.fatalError(); err != nil {
+		return err
+	}
+
+	if c.backlog.len() == 0 || atomic.LoadInt64(&c.inflight) > 0 {
+		return nil
+	}
+
+	height, ok := c.backlog.next()
+	if !ok {
+		return nil
+	}
+	stats.Record(ctx, metrics.WatcherBacklog.M(int64(c.backlog.len())))
+
+	ts, err := c.api.ChainGetTipSetByHeight(ctx, height, types.EmptyTSK)
+	if err != nil {
+		log.Warnw("watcher failed to get tipset to backfill", "error", err, "height", height, "reporter", c.name)
+		return nil
+	}
+	if ts.Height() != height {
+		log.Debugw("watcher skipping backfill of null round", "height", height, "reporter", c.name)
+		return nil
+	}
+
+	log.Infow("submitting tipset for async backfill", "height", ts.Height(), "backlog", c.backlog.len(), "reporter", c.name)
+	metrics.RecordInc(ctx, metrics.WatcherBackfill)
+	ctx, span := otel.Tracer("").Start(ctx, "Watcher.backfillAsync")
+	atomic.AddInt64(&c.inflight, 1)
+	c.pool.Submit(func() {
+		atomic.AddInt64(&c.active, 1)
+		defer func() {
+			atomic.AddInt64(&c.active, -1)
+			atomic.AddInt64(&c.inflight, -1)
+			span.End()
+		}()
+
+		// backfilled tipsets are indexed as the index type so distributed workers consume them at a lower priority than watch.
+		success, err := c.indexer.TipSet(ctx, ts, indexer.WithIndexerType(indexer.Index), indexer.WithTasks(c.tasks), indexer.WithInterval(c.interval))
+		if err != nil {
+			log.Errorw("watcher suffered fatal error during backfill", "error", err, "height", ts.Height(), "tipset", ts.Key().String(), "reporter", c.name)
+			c.setFatalError(err)
+			return
+		}
+		// heights are not returned to the backlog after a failed backfill to avoid retrying tipsets that can never be
+		// indexed, they may still be found and filled by a gap find/fill job.
+		if !success {
+			log.Warnw("watcher failed to fully backfill tipset", "height", ts.Height(), "tipset", ts.Key().String(), "reporter", c.name)
+		}
+	})
+	return nil
+}
+
+// addToBacklog records `height` for backfilling, counting it as dropped if the backlog is full.
+func (c *Watcher) addToBacklog(ctx context.Context, height abi.ChainEpoch) {
+	if c.maxBacklog <= 0 {
+		return
+	}
+	if !c.backlog.add(height) {
+		log.Warnw("watcher backlog full, height will not be backfilled", "height", height, "max_backlog", c.maxBacklog, "reporter", c.name)
+		metrics.RecordInc(ctx, metrics.WatcherBacklogDropped)
+		return
+	}
+	stats.Record(ctx, metrics.WatcherBacklog.M(int64(c.backlog.len())))
+}
+
 func (c *Watcher) setFatalError(err error) {
 	c.fatalMu.Lock()
 	c.fatal = err
@@ -13,6 +13,7 @@ import (
 	"go.opencensus.io/tag"
 
 	paramfetch "github.com/filecoin-project/go-paramfetch"
+	"github.com/filecoin-project/lily/chain/gossip"
 	"github.com/filecoin-project/lily/chain/indexer/distributed"
 	"github.com/filecoin-project/lily/commands/util"
 	"github.com/filecoin-project/lily/config"
@@ -22,6 +23,7 @@ import (
 	"github.com/filecoin-project/lily/metrics"
 	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/lily/storage"
+	"github.com/filecoin-project/lily/storage/query"
 	"github.com/filecoin-project/lily/version"
 
 	lotusbuild "github.com/filecoin-project/lotus/build"
@@ -253,9 +255,10 @@ Note that jobs are not persisted between restarts of the daemon. See
 			LilyNodeAPIOption(&api),
 			node.Override(new(*config.Conf), modules.LoadConf(daemonFlags.config)),
 			node.Override(new(*events.Events), modules.NewEvents),
-			node.Override(new(*schedule.Scheduler), schedule.NewSchedulerDaemon),
+			node.Override(new(*schedule.Scheduler), modules.NewScheduler),
 			node.Override(new(*storage.Catalog), modules.NewStorageCatalog),
 			node.Override(new(*distributed.Catalog), modules.NewQueueCatalog),
+			node.Override(new(*query.Server), modules.NewQueryServer),
 			node.Override(new(*lutil.CacheConfig), modules.CacheConfig(cacheFlags.BlockstoreCacheSize, cacheFlags.StatestoreCacheSize)),
 			// End Injection
 
@@ -271,6 +274,10 @@ Note that jobs are not persisted between restarts of the daemon. See
 			node.Override(new(*stmgr.StateManager), modules.StateManager),
 			node.Override(new(stmgr.ExecMonitor), modules.NewBufferedExecMonitor),
 			// End custom StateManager injection.
+
+			// Observe the blocks received over gossipsub for the block propagation survey.
+			node.Override(new(*gossip.BlockTracker), gossip.NewBlockTracker),
+			node.Override(node.HandleIncomingBlocksKey, modules.HandleIncomingBlocks),
 			genesis,
 			liteModeDeps,
 
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+	"github.com/filecoin-project/lily/lens/lily"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	lotuscli "github.com/filecoin-project/lotus/cli"
+)
+
+var extractFlags struct {
+	tipset string
+	format string
+}
+
+var ExtractCmd = &cli.Command{
+	Name:  "extract",
+	Usage: "Extract the models of a tipset and print them without persisting them.",
+	Description: `
+The extract command runs the given tasks over a single tipset and prints the extracted models and the processing
+reports of each task as the tasks complete. Nothing is written to storage, which makes the command useful for
+inspecting and debugging the output of tasks.
+`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "tipset",
+			Usage:       "TipSetKey to extract, a comma separated list of block cids.",
+			Required:    true,
+			Destination: &extractFlags.tipset,
+		},
+		&cli.StringSliceFlag{
+			Name:     "tasks",
+			Usage:    "Comma separated list of tasks to run. Each task is reported separately in the output.",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:        "format",
+			Usage:       "Output format. One of [json, csv, table]",
+			Value:       "table",
+			Destination: &extractFlags.format,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := lotuscli.ReqContext(cctx)
+
+		tasks := cctx.StringSlice("tasks")
+		for _, taskName := range tasks {
+			if _, found := tasktype.TaskLookup[taskName]; found {
+				continue
+			} else if _, found := tasktype.TableLookup[taskName]; found {
+				continue
+			}
+			return fmt.Errorf("unknown task: %s", taskName)
+		}
+
+		var printResult func(res *lily.ExtractResult) error
+		switch extractFlags.format {
+		case "json":
+			printResult = printExtractJSON
+		case "csv":
+			printResult = printExtractCSV
+		case "table":
+			printResult = printExtractTable
+		default:
+			return fmt.Errorf("unknown output format %q", extractFlags.format)
+		}
+
+		cids, err := lotuscli.ParseTipSetString(extractFlags.tipset)
+		if err != nil {
+			return fmt.Errorf("failed to parse tipset key: %w", err)
+		}
+
+		api, closer, err := GetAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		results, err := api.LilyExtract(ctx, types.NewTipSetKey(cids...), tasks)
+		if err != nil {
+			return err
+		}
+
+		failed := false
+		for res := range results {
+			if res.Error != "" {
+				failed = true
+			}
+			if err := printResult(res); err != nil {
+				return err
+			}
+		}
+		if failed {
+			return fmt.Errorf("one or more tasks failed")
+		}
+		return nil
+	},
+}
+
+func printExtractJSON(res *lily.ExtractResult) error {
+	out, err := json.MarshalIndent(res, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+func printExtractCSV(res *lily.ExtractResult) error {
+	if res.Error != "" {
+		fmt.Printf("# task: %s error: %s\n", res.Task, res.Error)
+	}
+	for _, tr := range res.Tables {
+		fmt.Printf("# task: %s table: %s\n", res.Task, tr.Table)
+		fmt.Println(extractTable(tr.Columns, tr.Rows).RenderCSV())
+	}
+	return nil
+}
+
+func printExtractTable(res *lily.ExtractResult) error {
+	if res.Error != "" {
+		fmt.Printf("task: %s error: %s\n", res.Task, res.Error)
+	}
+	for _, tr := range res.Tables {
+		t := extractTable(tr.Columns, tr.Rows)
+		t.SetTitle(fmt.Sprintf("task: %s table: %s", res.Task, tr.Table))
+		fmt.Println(t.Render())
+	}
+	return nil
+}
+
+func extractTable(columns []string, rows [][]string) table.Writer {
+	t := table.NewWriter()
+	header := make(table.Row, len(columns))
+	for i, c := range columns {
+		header[i] = c
+	}
+	t.AppendHeader(header)
+	for _, r := range rows {
+		row := make(table.Row, len(r))
+		for i, v := range r {
+			row[i] = v
+		}
+		t.AppendRow(row)
+	}
+	return t
+}
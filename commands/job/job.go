@@ -22,6 +22,7 @@ var JobCmd = &cli.Command{
 		JobStopCmd,
 		JobWaitCmd,
 		JobListCmd,
+		QueueCmd,
 	},
 }
 
@@ -43,6 +44,7 @@ var JobRunCmd = &cli.Command{
 		WatchCmd,
 		IndexCmd,
 		SurveyCmd,
+		PruneCmd,
 		GapFillCmd,
 		GapFindCmd,
 		TipSetWorkerCmd,
@@ -2,6 +2,8 @@ package job
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/urfave/cli/v2"
@@ -159,3 +161,32 @@ var IntervalFlag = &cli.IntFlag{
 	Value:       120,
 	Destination: &watchFlags.interval,
 }
+
+// parseActorDumpIntervals parses a list of `task=epochs` pairs into the interval of each periodic actor dump task.
+func parseActorDumpIntervals(values []string) (map[string]int, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	dumpTasks := make(map[string]struct{})
+	for _, t := range tasktype.TaskLookup[tasktype.ActorDump] {
+		dumpTasks[t] = struct{}{}
+	}
+
+	out := make(map[string]int, len(values))
+	for _, v := range values {
+		task, epochs, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid actor dump interval %q, expected task=epochs", v)
+		}
+		if _, found := dumpTasks[task]; !found {
+			return nil, fmt.Errorf("invalid actor dump interval %q, %s is not a periodic actor dump task", v, task)
+		}
+		interval, err := strconv.Atoi(epochs)
+		if err != nil || interval < 0 {
+			return nil, fmt.Errorf("invalid actor dump interval %q, epochs must be a non-negative integer", v)
+		}
+		out[task] = interval
+	}
+	return out, nil
+}
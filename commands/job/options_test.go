@@ -0,0 +1,37 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+)
+
+func TestParseActorDumpIntervals(t *testing.T) {
+	intervals, err := parseActorDumpIntervals(nil)
+	require.NoError(t, err)
+	require.Nil(t, intervals)
+
+	intervals, err = parseActorDumpIntervals([]string{tasktype.MarketDealDump + "=2880", tasktype.PowerClaimDump + "=0"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{tasktype.MarketDealDump: 2880, tasktype.PowerClaimDump: 0}, intervals)
+
+	testCases := []struct {
+		name  string
+		value string
+	}{
+		{name: "bad pair", value: tasktype.MarketDealDump + ":2880"},
+		{name: "unknown task", value: "market_deals=2880"},
+		{name: "table task that is not a dump", value: tasktype.BlockHeader + "=2880"},
+		{name: "negative value", value: tasktype.MarketDealDump + "=-1"},
+		{name: "not a number", value: tasktype.MarketDealDump + "=daily"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseActorDumpIntervals([]string{tasktype.PowerClaimDump + "=10", tc.value})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.value)
+		})
+	}
+}
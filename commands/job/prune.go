@@ -0,0 +1,61 @@
+package job
+
+import (
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/lily/commands"
+	"github.com/filecoin-project/lily/lens/lily"
+
+	lotuscli "github.com/filecoin-project/lotus/cli"
+)
+
+var pruneFlags struct {
+	interval  time.Duration
+	batchSize int64
+}
+
+var PruneCmd = &cli.Command{
+	Name:  "prune",
+	Usage: "Start a daemon job to remove data outside of the retention of each table of the storage.",
+	Description: `The retention of each table is configured in the storage's Retention setting as the number of epochs
+of data to keep. On each interval the rows of a table more than its retention below the chain head are
+removed. Partitions entirely below the retention are detached and dropped, remaining rows are deleted in
+batches so that pruning does not block indexing for long.`,
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:        "interval",
+			Usage:       "Interval to wait between each prune",
+			Value:       time.Hour,
+			Destination: &pruneFlags.interval,
+		},
+		&cli.Int64Flag{
+			Name:        "batch-size",
+			Usage:       "Number of epochs deleted from a table in each batch",
+			Value:       100,
+			Destination: &pruneFlags.batchSize,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := lotuscli.ReqContext(cctx)
+
+		api, closer, err := commands.GetAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		res, err := api.LilyPrune(ctx, &lily.LilyPruneConfig{
+			JobConfig: RunFlags.ParseJobConfig("prune"),
+			Interval:  pruneFlags.interval,
+			BatchSize: pruneFlags.batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		return commands.PrintNewJob(os.Stdout, res)
+	},
+}
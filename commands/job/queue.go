@@ -0,0 +1,87 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/lily/commands"
+
+	lotuscli "github.com/filecoin-project/lotus/cli"
+)
+
+var queueStatsFlags struct {
+	queue  string
+	output string
+}
+
+var QueueCmd = &cli.Command{
+	Name:  "queue",
+	Usage: "Inspect the queueing systems used by distributed jobs.",
+	Subcommands: []*cli.Command{
+		QueueStatsCmd,
+	},
+}
+
+var QueueStatsCmd = &cli.Command{
+	Name:  "stats",
+	Usage: "Print the number of pending, active, scheduled, retry and archived tasks of each queue in a queueing system.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "queue",
+			Usage:       "Name of the worker or notifier queue system to inspect.",
+			EnvVars:     []string{"LILY_JOB_QUEUE"},
+			Required:    true,
+			Destination: &queueStatsFlags.queue,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Usage:       "Output format. One of [text, json]",
+			Aliases:     []string{"o"},
+			Value:       "text",
+			Destination: &queueStatsFlags.output,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ctx := lotuscli.ReqContext(cctx)
+		api, closer, err := commands.GetAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		stats, err := api.LilyQueueStats(ctx, queueStatsFlags.queue)
+		if err != nil {
+			return err
+		}
+
+		switch queueStatsFlags.output {
+		case "json":
+			prettyStats, err := json.MarshalIndent(stats, "", "\t")
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(os.Stdout, "%s\n", prettyStats); err != nil {
+				return err
+			}
+		case "text":
+			w := tabwriter.NewWriter(os.Stdout, 4, 0, 1, ' ', 0)
+			if _, err := fmt.Fprintln(w, "QUEUE\tSIZE\tPENDING\tACTIVE\tSCHEDULED\tRETRY\tARCHIVED\tCOMPLETED\tLATENCY\tPAUSED"); err != nil {
+				return err
+			}
+			for _, s := range stats {
+				if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%t\n",
+					s.Queue, s.Size, s.Pending, s.Active, s.Scheduled, s.Retry, s.Archived, s.Completed, s.Latency, s.Paused); err != nil {
+					return err
+				}
+			}
+			return w.Flush()
+		default:
+			return fmt.Errorf("unknown output format %q", queueStatsFlags.output)
+		}
+		return nil
+	},
+}
@@ -17,6 +17,7 @@ var surveyFlags struct {
 	storage  string
 	name     string
 	interval time.Duration
+	geoIPDBs cli.StringSlice
 }
 
 var SurveyCmd = &cli.Command{
@@ -29,6 +30,11 @@ var SurveyCmd = &cli.Command{
 			Value:       10 * time.Minute,
 			Destination: &surveyFlags.interval,
 		},
+		&cli.StringSliceFlag{
+			Name:        "geoip-db",
+			Usage:       "Path to a MaxMind DB file, such as GeoLite2-ASN.mmdb or GeoLite2-Country.mmdb, used by the peertopology task to record the ASN and country of peers. May be repeated.",
+			Destination: &surveyFlags.geoIPDBs,
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		ctx := lotuscli.ReqContext(cctx)
@@ -40,8 +46,9 @@ var SurveyCmd = &cli.Command{
 		defer closer()
 
 		res, err := api.LilySurvey(ctx, &lily.LilySurveyConfig{
-			JobConfig: RunFlags.ParseJobConfig("survey"),
-			Interval:  surveyFlags.interval,
+			JobConfig:      RunFlags.ParseJobConfig("survey"),
+			Interval:       surveyFlags.interval,
+			GeoIPDatabases: surveyFlags.geoIPDBs.Value(),
 		})
 		if err != nil {
 			return err
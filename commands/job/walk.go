@@ -14,7 +14,8 @@ import (
 )
 
 type walkOps struct {
-	interval int `zap:"interval"`
+	interval           int `zap:"interval"`
+	actorDumpIntervals cli.StringSlice
 }
 
 var walkFlags walkOps
@@ -26,6 +27,12 @@ var WalkIntervalFlag = &cli.IntFlag{
 	Destination: &walkFlags.interval,
 }
 
+var WalkActorDumpIntervalFlag = &cli.StringSliceFlag{
+	Name:        "actor-dump-interval",
+	Usage:       "Comma separated list of task=epochs pairs setting the interval of individual periodic actor dump tasks, overriding --interval",
+	Destination: &walkFlags.actorDumpIntervals,
+}
+
 //revive:disable
 var WalkCmd = &cli.Command{
 	Name:  "walk",
@@ -43,6 +50,7 @@ The status of each epoch and its set of tasks can be observed in the visor_proce
 		RangeFromFlag,
 		RangeToFlag,
 		WalkIntervalFlag,
+		WalkActorDumpIntervalFlag,
 	},
 	Subcommands: []*cli.Command{
 		WalkNotifyCmd,
@@ -69,11 +77,18 @@ The status of each epoch and its set of tasks can be observed in the visor_proce
 		}
 		defer closer()
 
+		actorDumpIntervals, err := parseActorDumpIntervals(walkFlags.actorDumpIntervals.Value())
+		if err != nil {
+			return err
+		}
+
 		cfg := &lily.LilyWalkConfig{
 			JobConfig: RunFlags.ParseJobConfig("walk"),
 			From:      rangeFlags.from,
 			To:        rangeFlags.to,
 			Interval:  walkFlags.interval,
+
+			ActorDumpIntervals: actorDumpIntervals,
 		}
 
 		res, err := api.LilyWalk(ctx, cfg)
@@ -7,6 +7,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/filecoin-project/lily/chain/indexer/tasktype"
+	"github.com/filecoin-project/lily/chain/watch"
 	"github.com/filecoin-project/lily/commands"
 	"github.com/filecoin-project/lily/lens/lily"
 	"github.com/filecoin-project/lily/schedule"
@@ -19,6 +20,9 @@ type watchOps struct {
 	workers    int
 	bufferSize int
 	interval   int
+	maxBacklog int
+
+	actorDumpIntervals cli.StringSlice
 }
 
 var watchFlags watchOps
@@ -37,6 +41,12 @@ var WatchIntervalFlag = &cli.IntFlag{
 	Value:       120,
 	Destination: &watchFlags.interval,
 }
+var WatchActorDumpIntervalFlag = &cli.StringSliceFlag{
+	Name:        "actor-dump-interval",
+	Usage:       "Comma separated list of task=epochs pairs setting the interval of individual periodic actor dump tasks, overriding --interval",
+	Destination: &watchFlags.actorDumpIntervals,
+}
+
 var WatchWorkersFlag = &cli.IntFlag{
 	Name:        "workers",
 	Usage:       "Sets the number of tipsets that may be simultaneous indexed while watching.",
@@ -52,6 +62,14 @@ var WatchBufferSizeFlag = &cli.IntFlag{
 	Destination: &watchFlags.bufferSize,
 }
 
+var WatchMaxBacklogFlag = &cli.IntFlag{
+	Name:        "max-backlog",
+	Usage:       "Set the number of heights the watcher will remember for backfilling after failing to index them. Zero disables backfilling.",
+	EnvVars:     []string{"LILY_WATCH_MAX_BACKLOG"},
+	Value:       watch.WatcherDefaultMaxBacklog,
+	Destination: &watchFlags.maxBacklog,
+}
+
 //revive:disable
 var WatchCmd = &cli.Command{
 	Name:  "watch",
@@ -89,6 +107,11 @@ the --confidence flag may be used to buffer the amount of tipsets observed befor
                                              └────────┘      └────────┘
                                               (process)       (process)
 
+Tipsets the watch job fails to index, either because they were dropped while the job was falling behind the head of
+the chain or because one or more of their tasks did not complete, are remembered and backfilled once the job has caught
+up. Backfilling never delays indexing of the chain head. The --max-backlog flag bounds the number of heights remembered,
+heights that do not fit are left for a gap find/fill job.
+
 As and example, the below command:
   $ lily job run --tasks-block_header,messages watch --confidence=10 --workers=2
 watches the chain head and only indexes a tipset after observing 10 subsequent tipsets indexing at most two tipset simultaneously.
@@ -98,6 +121,8 @@ watches the chain head and only indexes a tipset after observing 10 subsequent t
 		WatchWorkersFlag,
 		WatchBufferSizeFlag,
 		WatchIntervalFlag,
+		WatchActorDumpIntervalFlag,
+		WatchMaxBacklogFlag,
 	},
 	Before: func(cctx *cli.Context) error {
 		tasks := RunFlags.Tasks.Value()
@@ -124,6 +149,11 @@ watches the chain head and only indexes a tipset after observing 10 subsequent t
 		}
 		defer closer()
 
+		actorDumpIntervals, err := parseActorDumpIntervals(watchFlags.actorDumpIntervals.Value())
+		if err != nil {
+			return err
+		}
+
 		var res *schedule.JobSubmitResult
 		cfg := &lily.LilyWatchConfig{
 			JobConfig:  RunFlags.ParseJobConfig("watch"),
@@ -131,6 +161,9 @@ watches the chain head and only indexes a tipset after observing 10 subsequent t
 			Confidence: watchFlags.confidence,
 			Workers:    watchFlags.workers,
 			Interval:   watchFlags.interval,
+			MaxBacklog: watchFlags.maxBacklog,
+
+			ActorDumpIntervals: actorDumpIntervals,
 		}
 
 		res, err = api.LilyWatch(ctx, cfg)
@@ -170,6 +203,7 @@ This command should be used when lily is configured to perform distributed index
 
 			Confidence: watchFlags.confidence,
 			BufferSize: watchFlags.bufferSize,
+			MaxBacklog: watchFlags.maxBacklog,
 
 			Queue: notifyFlags.queue,
 		}
@@ -1,8 +1,13 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/urfave/cli/v2"
 
@@ -65,6 +70,10 @@ var dbConnectFlags = []cli.Flag{
 var MigrateCmd = &cli.Command{
 	Name:  "migrate",
 	Usage: "Manage the schema version installed in a database.",
+	Subcommands: []*cli.Command{
+		MigrateVerifyCmd,
+		MigratePartitionCmd,
+	},
 	Flags: FlagSet(
 		dbConnectFlags,
 		[]cli.Flag{
@@ -78,6 +87,11 @@ var MigrateCmd = &cli.Command{
 				Value: false,
 				Usage: "Migrate the schema to the latest version.",
 			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Value: false,
+				Usage: "Print the SQL of each pending patch, the tables it affects and any destructive statements instead of migrating the schema. Plans a migration to the latest version unless --to is set.",
+			},
 		},
 	),
 	Action: func(cctx *cli.Context) error {
@@ -92,6 +106,22 @@ var MigrateCmd = &cli.Command{
 			return fmt.Errorf("connect database: %w", err)
 		}
 
+		if cctx.Bool("dry-run") {
+			targetVersion := storage.LatestSchemaVersion()
+			if cctx.IsSet("to") {
+				targetVersion, err = model.ParseVersion(cctx.String("to"))
+				if err != nil {
+					return fmt.Errorf("invalid schema version: %w", err)
+				}
+			}
+
+			plan, err := db.PlanSchemaMigrationTo(ctx, targetVersion)
+			if err != nil {
+				return fmt.Errorf("plan migration: %w", err)
+			}
+			return printMigrationPlan(os.Stdout, plan)
+		}
+
 		if cctx.IsSet("to") {
 			targetVersion, err := model.ParseVersion(cctx.String("to"))
 			if err != nil {
@@ -120,3 +150,177 @@ var MigrateCmd = &cli.Command{
 		return nil
 	},
 }
+
+var MigratePartitionCmd = &cli.Command{
+	Name:      "partition",
+	Usage:     "Recreate a high volume table as a table range partitioned by height.",
+	ArgsUsage: "<table>",
+	Description: `Recreates the named table, one of ` + strings.Join(storage.PartitionableTables, ", ") + `, as a table range
+partitioned by height and records its partition size in the database. Lily creates the partitions of the table ahead
+of the heights being indexed. Run after migrating the schema and before indexing into the table: only empty tables
+can be partitioned and tables that have been converted to TimescaleDB hypertables are refused.`,
+	Flags: FlagSet(
+		dbConnectFlags,
+		[]cli.Flag{
+			&cli.Int64Flag{
+				Name:  "partition-size",
+				Usage: "Number of epochs in each partition of the table.",
+				Value: storage.DefaultPartitionSize,
+			},
+		},
+	),
+	Action: func(cctx *cli.Context) error {
+		if err := setupLogging(LilyLogFlags); err != nil {
+			return fmt.Errorf("setup logging: %w", err)
+		}
+
+		if cctx.Args().Len() != 1 {
+			return fmt.Errorf("expected the name of one table to partition")
+		}
+		table := cctx.Args().First()
+
+		ctx := cctx.Context
+
+		db, err := storage.NewDatabase(ctx, LilyDBFlags.DB, LilyDBFlags.DBPoolSize, LilyDBFlags.Name, LilyDBFlags.DBSchema, false)
+		if err != nil {
+			return fmt.Errorf("connect database: %w", err)
+		}
+		if err := db.Connect(ctx); err != nil {
+			return fmt.Errorf("connect database: %w", err)
+		}
+		defer db.Close(ctx) // nolint: errcheck
+
+		if err := db.PartitionTable(ctx, table, cctx.Int64("partition-size")); err != nil {
+			return err
+		}
+
+		log.Infof("partitioned %s with partitions of %d epochs", table, cctx.Int64("partition-size"))
+		return nil
+	},
+}
+
+var MigrateVerifyCmd = &cli.Command{
+	Name:  "verify",
+	Usage: "Report the differences between the schema installed in a database and the models of this version of lily.",
+	Description: `Compares every table used by lily at the installed schema version with the live database and reports
+missing tables, missing columns, column type mismatches, missing or mismatched primary keys and extra columns,
+along with the SQL that reconciles each difference. The SQL is not executed and should be reviewed before use.
+Exits with an error if any difference is found.`,
+	Flags: FlagSet(
+		dbConnectFlags,
+		[]cli.Flag{
+			&cli.StringFlag{
+				Name:  "report",
+				Usage: "Format of the report, one of text or json.",
+				Value: "text",
+			},
+		},
+	),
+	Action: func(cctx *cli.Context) error {
+		if err := setupLogging(LilyLogFlags); err != nil {
+			return fmt.Errorf("setup logging: %w", err)
+		}
+
+		format := cctx.String("report")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown report format %q, must be one of text or json", format)
+		}
+
+		ctx := cctx.Context
+
+		db, err := storage.NewDatabase(ctx, LilyDBFlags.DB, LilyDBFlags.DBPoolSize, LilyDBFlags.Name, LilyDBFlags.DBSchema, false)
+		if err != nil {
+			return fmt.Errorf("connect database: %w", err)
+		}
+
+		report, err := db.SchemaReport(ctx)
+		if err != nil {
+			return fmt.Errorf("verify schema: %w", err)
+		}
+
+		if format == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			printSchemaReport(os.Stdout, report)
+		}
+
+		if !report.Valid() {
+			return fmt.Errorf("database schema version %s differs from the models of this version of lily", report.Version)
+		}
+		return nil
+	},
+}
+
+// printSchemaReport writes the differences of each table followed by the SQL that reconciles them.
+func printSchemaReport(w io.Writer, report *storage.SchemaReport) {
+	fmt.Fprintf(w, "Schema version %s\n", report.Version)
+	if report.Valid() {
+		fmt.Fprintln(w, "All tables match their models")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 4, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tKIND\tCOLUMN\tEXPECTED\tACTUAL")
+	for _, t := range report.Tables {
+		for _, d := range t.Diffs {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", t.Table, d.Kind, d.Column, d.Expected, d.Actual)
+		}
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "-- SQL to reconcile the database with the models, review before executing")
+	for _, sql := range report.SQL() {
+		fmt.Fprintln(w, sql)
+	}
+}
+
+// printMigrationPlan writes the SQL of each step of the plan as a script that can be reviewed, followed by a summary of
+// the affected tables. Destructive statements are preceded by a comment describing why.
+func printMigrationPlan(w io.Writer, plan *storage.MigrationPlan) error {
+	fmt.Fprintf(w, "-- Migration plan from schema version %s to %s\n", plan.From, plan.To)
+	if plan.Destructive() {
+		fmt.Fprintln(w, "-- WARNING: this migration contains destructive statements")
+	}
+
+	for _, step := range plan.Steps {
+		fmt.Fprintln(w)
+		switch {
+		case step.Down:
+			fmt.Fprintf(w, "-- Patch %d: rolled back, no SQL is executed\n", step.Patch)
+			continue
+		case step.Patch == 0:
+			fmt.Fprintln(w, "-- Base schema")
+		default:
+			fmt.Fprintf(w, "-- Patch %d\n", step.Patch)
+		}
+
+		for _, stmt := range step.Statements {
+			for _, warning := range stmt.Warnings {
+				fmt.Fprintf(w, "-- DESTRUCTIVE: %s\n", warning)
+			}
+			fmt.Fprintf(w, "%s;\n", stmt.SQL)
+		}
+	}
+
+	if len(plan.Tables) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "-- Affected tables (estimated from pg_class)")
+	tw := tabwriter.NewWriter(w, 4, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "--\tTABLE\tROWS\tBYTES")
+	for _, t := range plan.Tables {
+		rows := "unknown"
+		if t.Rows >= 0 {
+			rows = strconv.FormatInt(t.Rows, 10)
+		}
+		fmt.Fprintf(tw, "--\t%s\t%s\t%d\n", t.Table, rows, t.Bytes)
+	}
+	return tw.Flush()
+}
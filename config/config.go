@@ -24,11 +24,29 @@ type Conf struct {
 	Chainstore config.Chainstore
 	Storage    StorageConf
 	Queue      QueueConfig
+	QueryAPI   QueryAPIConf
+	Scheduler  SchedulerConf
+}
+
+// SchedulerConf bounds the resources used by the tasks of all jobs run by the daemon.
+type SchedulerConf struct {
+	// TaskConcurrency is the maximum number of tasks executing at once across all jobs. Tasks wait for a free slot
+	// before executing.
+	//
+	// If unset or zero, the number of tasks is unlimited.
+	TaskConcurrency int
+
+	// TaskTimeout is the maximum duration of a single task. Tasks exceeding it are canceled and reported with a
+	// status of SKIP.
+	//
+	// If unset or zero, tasks are never timed out.
+	TaskTimeout time.Duration
 }
 
 type StorageConf struct {
 	Postgresql map[string]PgStorageConf
 	File       map[string]FileStorageConf
+	Composite  map[string]CompositeStorageConf
 }
 
 type PgStorageConf struct {
@@ -38,13 +56,72 @@ type PgStorageConf struct {
 	SchemaName      string
 	PoolSize        int
 	AllowUpsert     bool
+	BulkCopy        bool // persist models using COPY FROM STDIN into a temporary table rather than INSERT
+	Partitioning    PartitionConf
+
+	// Retention is the number of epochs of data to keep in each table, keyed by table name. Prune jobs delete the
+	// rows of a table more than its retention below the chain head. Tables without a retention are never pruned.
+	Retention map[string]int64
+}
+
+// PartitionConf configures how lily creates the partitions of the tables range partitioned by height. Tables are
+// partitioned with the lily migrate partition command, which records each table and its partition size in the
+// database.
+type PartitionConf struct {
+	// Ahead is the number of partitions created ahead of the partition containing the height being indexed.
+	//
+	// If unset or zero, 2 partitions are created ahead.
+	Ahead int
 }
 
 type FileStorageConf struct {
 	Format      string
-	Path        string
+	Path        string // local directory, or s3://bucket/prefix URL of an S3 compatible object store to upload files to
 	OmitHeader  bool   // when true, don't write column headers to new output files
 	FilePattern string // pattern to use for filenames written in the path specified
+
+	// HeightRange is the number of epochs of data in each file uploaded to an object store. Ignored for local paths.
+	//
+	// If unset or zero, files of 2880 epochs are uploaded.
+	HeightRange int64
+
+	// FlushAfter is how long data is buffered without being added to before it is uploaded to an object store.
+	// Ignored for local paths.
+	//
+	// If unset or zero, data is uploaded after one minute.
+	FlushAfter time.Duration
+}
+
+// CompositeStorageConf configures a storage that persists data to several other storages.
+type CompositeStorageConf struct {
+	Children []CompositeChildConf
+}
+
+// CompositeChildConf configures one of the storages a composite storage persists data to.
+type CompositeChildConf struct {
+	Storage string // name of a Postgresql or File storage
+
+	// BestEffort is true if failing to persist data to the storage should be logged rather than fail the batch.
+	BestEffort bool
+
+	// Tables are the only tables persisted to the storage. If empty, all tables are persisted.
+	Tables []string
+
+	// ExcludeTables are tables that are not persisted to the storage.
+	ExcludeTables []string
+}
+
+// QueryAPIConf configures the read-only HTTP API the daemon serves over data indexed into a Postgresql storage.
+type QueryAPIConf struct {
+	// ListenAddress is the multiaddress the query API listens on, e.g. /ip4/127.0.0.1/tcp/1235/http.
+	// The query API is disabled when empty.
+	ListenAddress string
+	// Storage is the name of the Postgresql storage queries are served from.
+	Storage string
+	// MaxPageSize is the maximum number of rows returned by a single request.
+	//
+	// If unset or zero, a maximum of 1000 rows is used.
+	MaxPageSize int
 }
 
 type QueueConfig struct {
@@ -126,6 +203,12 @@ type WorkerConfig struct {
 	//
 	// If unset or zero, default timeout of 8 seconds is used.
 	ShutdownTimeout time.Duration
+
+	// QueueStatsInterval specifies how often the worker records the number of pending, active, scheduled,
+	// retry and archived tasks of its queues as metrics.
+	//
+	// If unset or zero, an interval of 10 seconds is used.
+	QueueStatsInterval time.Duration
 }
 
 func (q WorkerConfig) Queues() map[string]int {
@@ -198,6 +281,22 @@ func SampleConf() *Conf {
 				FilePattern: "{table}.csv",
 			},
 		},
+
+		// this composite storage is only here to give an example to the user
+		Composite: map[string]CompositeStorageConf{
+			"Database1AndCSV": {
+				Children: []CompositeChildConf{
+					{
+						Storage:       "Database1",
+						ExcludeTables: []string{"vm_messages"},
+					},
+					{
+						Storage:    "CSV",
+						BestEffort: true,
+					},
+				},
+			},
+		},
 	}
 	cfg.Queue = QueueConfig{
 		Workers: map[string]AsynqWorkerConfig{
@@ -220,6 +319,7 @@ func SampleConf() *Conf {
 					WalkQueuePriority:  1,
 					StrictPriority:     false,
 					ShutdownTimeout:    time.Second * 30,
+					QueueStatsInterval: time.Second * 10,
 				},
 			},
 		},
@@ -235,6 +335,15 @@ func SampleConf() *Conf {
 			},
 		},
 	}
+	cfg.Scheduler = SchedulerConf{
+		TaskConcurrency: 0,
+		TaskTimeout:     0,
+	}
+	// the query API is opt-in, set ListenAddress (e.g. /ip4/127.0.0.1/tcp/1235/http) to enable it.
+	cfg.QueryAPI = QueryAPIConf{
+		Storage:     "Database1",
+		MaxPageSize: 1000,
+	}
 
 	return &cfg
 }
@@ -10,7 +10,9 @@ import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
 	"github.com/filecoin-project/lily/schedule"
+	"github.com/filecoin-project/lily/storage"
 
 	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/chain/types"
@@ -23,9 +25,13 @@ type LilyAPI interface {
 	AuthVerify(ctx context.Context, token string) ([]auth.Permission, error)
 
 	LilyIndex(ctx context.Context, cfg *LilyIndexConfig) (interface{}, error)
+	// LilyExtract runs `tasks` over the tipset `tsk` and streams the extracted models and processing reports of each
+	// task back to the caller as they complete. Nothing is persisted.
+	LilyExtract(ctx context.Context, tsk types.TipSetKey, tasks []string) (<-chan *ExtractResult, error)
 	LilyWatch(ctx context.Context, cfg *LilyWatchConfig) (*schedule.JobSubmitResult, error)
 	LilyWalk(ctx context.Context, cfg *LilyWalkConfig) (*schedule.JobSubmitResult, error)
 	LilySurvey(ctx context.Context, cfg *LilySurveyConfig) (*schedule.JobSubmitResult, error)
+	LilyPrune(ctx context.Context, cfg *LilyPruneConfig) (*schedule.JobSubmitResult, error)
 
 	LilyIndexNotify(ctx context.Context, cfg *LilyIndexNotifyConfig) (interface{}, error)
 	LilyWatchNotify(ctx context.Context, cfg *LilyWatchNotifyConfig) (*schedule.JobSubmitResult, error)
@@ -88,6 +94,9 @@ type LilyAPI interface {
 	NetDisconnect(context.Context, peer.ID) error
 
 	StartTipSetWorker(ctx context.Context, cfg *LilyTipSetWorkerConfig) (*schedule.JobSubmitResult, error)
+	// LilyQueueStats returns the number of pending, active, scheduled, retry and archived tasks of each queue in the
+	// queueing system named `name`.
+	LilyQueueStats(ctx context.Context, name string) ([]*queue.QueueStats, error)
 
 	FindOldestState(ctx context.Context, limit int64) ([]*StateReport, error)
 	StateCompute(ctx context.Context, tsk types.TipSetKey) (interface{}, error)
@@ -118,6 +127,9 @@ type LilyWatchConfig struct {
 	Confidence int
 	Workers    int // number of indexing jobs that can run in parallel
 	Interval   int
+	MaxBacklog int // number of heights that failed to index to remember for backfilling, zero disables backfilling
+
+	ActorDumpIntervals map[string]int // interval in epochs of individual periodic actor dump tasks, overrides Interval
 }
 
 type LilyWatchNotifyConfig struct {
@@ -125,6 +137,7 @@ type LilyWatchNotifyConfig struct {
 
 	BufferSize int // number of tipsets to buffer from notifier service
 	Confidence int
+	MaxBacklog int // number of heights that failed to index to remember for backfilling, zero disables backfilling
 	Queue      string
 }
 
@@ -134,6 +147,8 @@ type LilyWalkConfig struct {
 	From     int64
 	To       int64
 	Interval int
+
+	ActorDumpIntervals map[string]int // interval in epochs of individual periodic actor dump tasks, overrides Interval
 }
 
 type LilyWalkNotifyConfig struct {
@@ -173,6 +188,16 @@ type LilySurveyConfig struct {
 	JobConfig LilyJobConfig
 
 	Interval time.Duration
+	// GeoIPDatabases are MaxMind DB files used to resolve the ASN and country of the peers observed by the
+	// peertopology task.
+	GeoIPDatabases []string
+}
+
+type LilyPruneConfig struct {
+	JobConfig LilyJobConfig
+
+	Interval  time.Duration
+	BatchSize int64
 }
 
 type LilyIndexConfig struct {
@@ -181,6 +206,16 @@ type LilyIndexConfig struct {
 	TipSet types.TipSetKey
 }
 
+// ExtractResult holds the data extracted by a single task of LilyExtract.
+type ExtractResult struct {
+	// Task is the name of the task, empty if the result holds an error not attributable to a task.
+	Task string
+	// Tables holds the extracted models and the processing reports of the task, grouped by table.
+	Tables []*storage.TableRows
+	// Error is set when the task failed fatally or its models could not be formatted.
+	Error string
+}
+
 type LilyIndexNotifyConfig struct {
 	IndexConfig LilyIndexConfig
 
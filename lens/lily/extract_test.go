@@ -0,0 +1,67 @@
+package lily
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/chain/indexer/integrated/tipset"
+	"github.com/filecoin-project/lily/model/blocks"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+)
+
+func receiveAll(t *testing.T, out <-chan *ExtractResult) []*ExtractResult {
+	var got []*ExtractResult
+	for {
+		select {
+		case res, ok := <-out:
+			if !ok {
+				return got
+			}
+			got = append(got, res)
+		case <-time.After(5 * time.Second):
+			t.Fatal("extract results were not closed")
+		}
+	}
+}
+
+func TestExtractResults(t *testing.T) {
+	taskResults := make(chan *tipset.Result, 1)
+	taskErrors := make(chan error, 1)
+	taskResults <- &tipset.Result{
+		Name:   "block_header",
+		Data:   blocks.BlockHeaders{{Height: 10, Cid: "blocka"}},
+		Report: visormodel.ProcessingReportList{{Height: 10, Task: "block_header", Status: visormodel.ProcessingStatusOK}},
+	}
+	close(taskResults)
+	taskErrors <- errors.New("fatal")
+	close(taskErrors)
+
+	got := receiveAll(t, extractResults(context.Background(), taskResults, taskErrors))
+	require.Len(t, got, 2)
+	require.Equal(t, "block_header", got[0].Task)
+	require.Empty(t, got[0].Error)
+	require.Len(t, got[0].Tables, 2)
+	require.Equal(t, "block_headers", got[0].Tables[0].Table)
+	require.Equal(t, "visor_processing_reports", got[0].Tables[1].Table)
+	require.Equal(t, &ExtractResult{Error: "fatal"}, got[1])
+}
+
+func TestExtractResultsClosedOnCancel(t *testing.T) {
+	// the stream is closed when the client goes away while results are waiting to be read
+	ctx, cancel := context.WithCancel(context.Background())
+	taskResults := make(chan *tipset.Result, 1)
+	taskResults <- &tipset.Result{Name: "block_header", Data: blocks.BlockHeaders{{Height: 10, Cid: "blocka"}}}
+	out := extractResults(ctx, taskResults, make(chan error))
+	cancel()
+	require.LessOrEqual(t, len(receiveAll(t, out)), 1)
+
+	// and when it goes away while the tasks are still executing
+	ctx, cancel = context.WithCancel(context.Background())
+	out = extractResults(ctx, make(chan *tipset.Result), make(chan error))
+	cancel()
+	require.Empty(t, receiveAll(t, out))
+}
@@ -19,25 +19,30 @@ import (
 	network2 "github.com/filecoin-project/go-state-types/network"
 	"github.com/filecoin-project/lily/chain/datasource"
 	"github.com/filecoin-project/lily/chain/gap"
+	"github.com/filecoin-project/lily/chain/gossip"
 	"github.com/filecoin-project/lily/chain/indexer"
 	"github.com/filecoin-project/lily/chain/indexer/distributed"
 	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
 	"github.com/filecoin-project/lily/chain/indexer/distributed/queue/tasks"
 	"github.com/filecoin-project/lily/chain/indexer/integrated"
 	"github.com/filecoin-project/lily/chain/indexer/integrated/tipset"
+	"github.com/filecoin-project/lily/chain/prune"
 	"github.com/filecoin-project/lily/chain/walk"
 	"github.com/filecoin-project/lily/chain/watch"
 	"github.com/filecoin-project/lily/lens"
 	"github.com/filecoin-project/lily/lens/lily/modules"
 	"github.com/filecoin-project/lily/lens/util"
+	"github.com/filecoin-project/lily/model"
 	"github.com/filecoin-project/lily/network"
 	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/lily/storage"
+	"github.com/filecoin-project/lily/storage/query"
 	"github.com/filecoin-project/specs-actors/actors/util/adt"
 
 	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/chain/consensus"
 	"github.com/filecoin-project/lotus/chain/events"
+	"github.com/filecoin-project/lotus/chain/messagepool"
 	"github.com/filecoin-project/lotus/chain/state"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/types"
@@ -65,10 +70,15 @@ type LilyNodeAPI struct {
 	Scheduler *schedule.Scheduler
 
 	ExecMonitor stmgr.ExecMonitor
+	Mpool       *messagepool.MessagePool
 	CacheConfig *util.CacheConfig
+	// BlockTracker is nil unless the node reports the blocks it receives over gossipsub.
+	BlockTracker *gossip.BlockTracker `optional:"true"`
 
 	StorageCatalog *storage.Catalog
 	QueueCatalog   *distributed.Catalog
+	// QueryServer is nil unless the read-only query API is enabled.
+	QueryServer *query.Server `optional:"true"`
 
 	actorStore     adt.Store
 	actorStoreInit sync.Once
@@ -78,6 +88,20 @@ func (m *LilyNodeAPI) Host() host.Host {
 	return m.RawHost
 }
 
+// BlockGossip subscribes to the blocks received over the network's blocks topic, along with the peer each block was
+// first received from and the time it was received.
+func (m *LilyNodeAPI) BlockGossip(ctx context.Context) (<-chan *gossip.Block, error) {
+	if m.BlockTracker == nil {
+		return nil, fmt.Errorf("node does not track gossiped blocks")
+	}
+	return m.BlockTracker.Subscribe(ctx), nil
+}
+
+// MpoolSub subscribes to the messages added to and removed from the node's mempool.
+func (m *LilyNodeAPI) MpoolSub(ctx context.Context) (<-chan api.MpoolUpdate, error) {
+	return m.Mpool.Updates(ctx)
+}
+
 func (m *LilyNodeAPI) StartTipSetWorker(_ context.Context, cfg *LilyTipSetWorkerConfig) (*schedule.JobSubmitResult, error) {
 	ctx := context.Background()
 	log.Infow("starting TipSetWorker", "name", cfg.JobConfig.Name)
@@ -101,7 +125,7 @@ func (m *LilyNodeAPI) StartTipSetWorker(_ context.Context, cfg *LilyTipSetWorker
 		return nil, err
 	}
 
-	im, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name))
+	im, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name).WithTaskLimiter(m.Scheduler.TaskBudget()))
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +154,7 @@ func (m *LilyNodeAPI) StartTipSetWorker(_ context.Context, cfg *LilyTipSetWorker
 			"storage": cfg.JobConfig.Storage,
 		},
 		Job:                 queue.NewAsynqWorker(cfg.JobConfig.Name, worker, handlers...),
+		Storage:             strg,
 		RestartOnFailure:    cfg.JobConfig.RestartOnFailure,
 		RestartOnCompletion: cfg.JobConfig.RestartOnCompletion,
 		RestartDelay:        cfg.JobConfig.RestartDelay,
@@ -137,6 +162,14 @@ func (m *LilyNodeAPI) StartTipSetWorker(_ context.Context, cfg *LilyTipSetWorker
 	return res, nil
 }
 
+func (m *LilyNodeAPI) LilyQueueStats(_ context.Context, name string) ([]*queue.QueueStats, error) {
+	inspector, err := m.QueueCatalog.Inspector(name)
+	if err != nil {
+		return nil, err
+	}
+	return queue.Stats(inspector)
+}
+
 func (m *LilyNodeAPI) LilyIndex(_ context.Context, cfg *LilyIndexConfig) (interface{}, error) {
 	md := storage.Metadata{
 		JobName: cfg.JobConfig.Name,
@@ -156,7 +189,7 @@ func (m *LilyNodeAPI) LilyIndex(_ context.Context, cfg *LilyIndexConfig) (interf
 	}
 
 	// instantiate an indexer to extract block, message, and actor state data from observed tipsets and persists it to the storage.
-	im, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name), integrated.WithWindow(cfg.JobConfig.Window))
+	im, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name).WithTaskLimiter(m.Scheduler.TaskBudget()), integrated.WithWindow(cfg.JobConfig.Window))
 	if err != nil {
 		return nil, err
 	}
@@ -167,10 +200,99 @@ func (m *LilyNodeAPI) LilyIndex(_ context.Context, cfg *LilyIndexConfig) (interf
 	}
 
 	success, err := im.TipSet(ctx, ts, indexer.WithTasks(cfg.JobConfig.Tasks))
+	if fs, ok := strg.(storage.Flusher); ok {
+		if ferr := fs.Flush(ctx); ferr != nil && err == nil {
+			err = fmt.Errorf("flushing storage: %w", ferr)
+		}
+	}
 
 	return success, err
 }
 
+func (m *LilyNodeAPI) LilyExtract(ctx context.Context, tsk types.TipSetKey, tasks []string) (<-chan *ExtractResult, error) {
+	taskAPI, err := datasource.NewDataSource(m)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := m.ChainGetTipSet(ctx, tsk)
+	if err != nil {
+		return nil, err
+	}
+
+	idxer, err := tipset.NewBuilder(taskAPI, "extract").WithTaskLimiter(m.Scheduler.TaskBudget()).WithTasks(tasks).Build()
+	if err != nil {
+		return nil, err
+	}
+
+	// the context lives for as long as the client is reading results, the indexer stops if the client goes away.
+	taskResults, taskErrors, err := idxer.TipSet(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractResults(ctx, taskResults, taskErrors), nil
+}
+
+// extractResults formats the models of each of `taskResults` as rows and emits them on the returned channel, followed
+// by the fatal `taskErrors`. The channel is closed once both are drained or when `ctx` is canceled.
+func extractResults(ctx context.Context, taskResults <-chan *tipset.Result, taskErrors <-chan error) <-chan *ExtractResult {
+	out := make(chan *ExtractResult)
+	go func() {
+		defer close(out)
+		// the genesis tipset has no parent to diff against so nothing is extracted.
+		if taskResults == nil {
+			return
+		}
+
+		send := func(res *ExtractResult) bool {
+			select {
+			case out <- res:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		version := storage.LatestSchemaVersion()
+		for {
+			var res *tipset.Result
+			var ok bool
+			select {
+			case res, ok = <-taskResults:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				break
+			}
+			tables, err := storage.ModelRows(ctx, version, model.PersistableList{res.Report, res.Data})
+			er := &ExtractResult{Task: res.Name, Tables: tables}
+			if err != nil {
+				er.Error = fmt.Sprintf("formatting models: %s", err)
+			}
+			if !send(er) {
+				return
+			}
+		}
+		for {
+			select {
+			case fatal, ok := <-taskErrors:
+				if !ok {
+					return
+				}
+				if !send(&ExtractResult{Error: fatal.Error()}) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
 func (m *LilyNodeAPI) LilyIndexNotify(_ context.Context, cfg *LilyIndexNotifyConfig) (interface{}, error) {
 	// the context's passed to these methods live for the duration of the clients request, so make a new one.
 	ctx := context.Background()
@@ -220,7 +342,8 @@ func (m *LilyNodeAPI) LilyWatch(_ context.Context, cfg *LilyWatchConfig) (*sched
 	}
 
 	// instantiate an indexer to extract block, message, and actor state data from observed tipsets and persists it to the storage.
-	idx, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name), integrated.WithWindow(cfg.JobConfig.Window))
+	idxBuilder := tipset.NewBuilder(taskAPI, cfg.JobConfig.Name).WithTaskLimiter(m.Scheduler.TaskBudget()).WithActorDumpIntervals(cfg.ActorDumpIntervals)
+	idx, err := integrated.NewManager(strg, idxBuilder, integrated.WithWindow(cfg.JobConfig.Window))
 	if err != nil {
 		return nil, err
 	}
@@ -233,6 +356,7 @@ func (m *LilyNodeAPI) LilyWatch(_ context.Context, cfg *LilyWatchConfig) (*sched
 		watch.WithConcurrentWorkers(cfg.Workers),
 		watch.WithBufferSize(cfg.BufferSize),
 		watch.WithInterval(cfg.Interval),
+		watch.WithMaxBacklog(cfg.MaxBacklog),
 	)
 	jobConfig := &schedule.JobConfig{
 		Name: cfg.JobConfig.Name,
@@ -244,12 +368,14 @@ func (m *LilyNodeAPI) LilyWatch(_ context.Context, cfg *LilyWatchConfig) (*sched
 			"worker":     strconv.Itoa(cfg.Workers),
 			"buffer":     strconv.Itoa(cfg.BufferSize),
 			"interval":   strconv.Itoa(cfg.Interval),
+			"backlog":    strconv.Itoa(cfg.MaxBacklog),
 		},
 		Tasks:               cfg.JobConfig.Tasks,
 		RestartOnFailure:    cfg.JobConfig.RestartOnFailure,
 		RestartOnCompletion: cfg.JobConfig.RestartOnCompletion,
 		RestartDelay:        cfg.JobConfig.RestartDelay,
 		Job:                 watchJob,
+		Storage:             strg,
 		Reporter:            reporter,
 	}
 
@@ -274,6 +400,7 @@ func (m *LilyNodeAPI) LilyWatchNotify(_ context.Context, cfg *LilyWatchNotifyCon
 		watch.WithTasks(cfg.JobConfig.Tasks...),
 		watch.WithConfidence(cfg.Confidence),
 		watch.WithBufferSize(cfg.BufferSize),
+		watch.WithMaxBacklog(cfg.MaxBacklog),
 	)
 	jobConfig := &schedule.JobConfig{
 		Name: cfg.JobConfig.Name,
@@ -281,6 +408,7 @@ func (m *LilyNodeAPI) LilyWatchNotify(_ context.Context, cfg *LilyWatchNotifyCon
 		Params: map[string]string{
 			"confidence": strconv.Itoa(cfg.Confidence),
 			"buffer":     strconv.Itoa(cfg.BufferSize),
+			"backlog":    strconv.Itoa(cfg.MaxBacklog),
 			"queue":      cfg.Queue,
 		},
 		Tasks:               cfg.JobConfig.Tasks,
@@ -314,7 +442,8 @@ func (m *LilyNodeAPI) LilyWalk(_ context.Context, cfg *LilyWalkConfig) (*schedul
 	}
 
 	// instantiate an indexer to extract block, message, and actor state data from observed tipsets and persists it to the storage.
-	idx, err := integrated.NewManager(strg, tipset.NewBuilder(taskAPI, cfg.JobConfig.Name), integrated.WithWindow(cfg.JobConfig.Window))
+	idxBuilder := tipset.NewBuilder(taskAPI, cfg.JobConfig.Name).WithTaskLimiter(m.Scheduler.TaskBudget()).WithActorDumpIntervals(cfg.ActorDumpIntervals)
+	idx, err := integrated.NewManager(strg, idxBuilder, integrated.WithWindow(cfg.JobConfig.Window))
 	if err != nil {
 		return nil, err
 	}
@@ -334,6 +463,7 @@ func (m *LilyNodeAPI) LilyWalk(_ context.Context, cfg *LilyWalkConfig) (*schedul
 		RestartOnCompletion: cfg.JobConfig.RestartOnCompletion,
 		RestartDelay:        cfg.JobConfig.RestartDelay,
 		Job:                 walk.NewWalker(idx, m, cfg.JobConfig.Name, cfg.JobConfig.Tasks, cfg.From, cfg.To, reporter, cfg.JobConfig.StopOnError, cfg.Interval),
+		Storage:             strg,
 		Reporter:            reporter,
 	}
 
@@ -427,7 +557,7 @@ func (m *LilyNodeAPI) LilyGapFill(_ context.Context, cfg *LilyGapFillConfig) (*s
 		RestartOnCompletion: cfg.JobConfig.RestartOnCompletion,
 		RestartDelay:        cfg.JobConfig.RestartDelay,
 		Reporter:            reporter,
-		Job:                 gap.NewFiller(m, db, cfg.JobConfig.Name, cfg.From, cfg.To, cfg.JobConfig.Tasks, reporter),
+		Job:                 gap.NewFiller(m, db, cfg.JobConfig.Name, cfg.From, cfg.To, cfg.JobConfig.Tasks, reporter, m.Scheduler.TaskBudget()),
 	}
 	res := m.Scheduler.Submit(jobConfig)
 	return res, nil
@@ -841,15 +971,16 @@ func (m *LilyNodeAPI) LilySurvey(_ context.Context, cfg *LilySurveyConfig) (*sch
 	}
 
 	// instantiate a new survey.
-	surv, err := network.NewSurveyer(m, strg, cfg.Interval, cfg.JobConfig.Name, cfg.JobConfig.Tasks)
+	surv, err := network.NewSurveyer(m, strg, cfg.Interval, cfg.JobConfig.Name, cfg.JobConfig.Tasks, network.WithGeoIPDatabases(cfg.GeoIPDatabases))
 	if err != nil {
 		return nil, err
 	}
 
 	res := m.Scheduler.Submit(&schedule.JobConfig{
-		Name:  cfg.JobConfig.Name,
-		Tasks: cfg.JobConfig.Tasks,
-		Job:   surv,
+		Name:    cfg.JobConfig.Name,
+		Tasks:   cfg.JobConfig.Tasks,
+		Job:     surv,
+		Storage: strg,
 		Params: map[string]string{
 			"interval": cfg.Interval.String(),
 		},
@@ -861,6 +992,41 @@ func (m *LilyNodeAPI) LilySurvey(_ context.Context, cfg *LilySurveyConfig) (*sch
 	return res, nil
 }
 
+func (m *LilyNodeAPI) LilyPrune(_ context.Context, cfg *LilyPruneConfig) (*schedule.JobSubmitResult, error) {
+	// the context's passed to these methods live for the duration of the clients request, so make a new one.
+	ctx := context.Background()
+
+	// create a database connection for this prune, ensure its pingable, and run migrations if needed/configured to.
+	strg, err := m.StorageCatalog.Connect(ctx, cfg.JobConfig.Storage, storage.Metadata{JobName: cfg.JobConfig.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	db, ok := strg.(prune.Storage)
+	if !ok {
+		return nil, fmt.Errorf("storage %q does not support pruning", cfg.JobConfig.Storage)
+	}
+
+	pruner, err := prune.NewPruner(m, db, cfg.JobConfig.Name, cfg.Interval, cfg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	res := m.Scheduler.Submit(&schedule.JobConfig{
+		Name: cfg.JobConfig.Name,
+		Job:  pruner,
+		Params: map[string]string{
+			"interval":  cfg.Interval.String(),
+			"batchSize": strconv.FormatInt(cfg.BatchSize, 10),
+		},
+		RestartOnFailure:    cfg.JobConfig.RestartOnFailure,
+		RestartOnCompletion: cfg.JobConfig.RestartOnCompletion,
+		RestartDelay:        cfg.JobConfig.RestartDelay,
+	})
+
+	return res, nil
+}
+
 type StateReport struct {
 	Height      int64
 	TipSet      *types.TipSet
@@ -1,10 +1,15 @@
 package modules
 
 import (
+	"context"
+
+	"github.com/hibiken/asynq"
 	"go.uber.org/fx"
 
 	"github.com/filecoin-project/lily/chain/indexer/distributed"
+	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
 	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/lily/storage"
 
 	"github.com/filecoin-project/lotus/node/modules/helpers"
@@ -20,6 +25,28 @@ func LoadConf(path string) func(mctx helpers.MetricsCtx, lc fx.Lifecycle) (*conf
 	}
 }
 
-func NewQueueCatalog(_ helpers.MetricsCtx, _ fx.Lifecycle, cfg *config.Conf) (*distributed.Catalog, error) {
-	return distributed.NewCatalog(cfg.Queue)
+// NewScheduler returns a daemon Scheduler enforcing the task limits of the config.
+func NewScheduler(mctx helpers.MetricsCtx, lc fx.Lifecycle, cfg *config.Conf) *schedule.Scheduler {
+	s := schedule.NewSchedulerDaemon(mctx, lc)
+	s.SetTaskLimits(schedule.TaskLimits{
+		Concurrency: cfg.Scheduler.TaskConcurrency,
+		Timeout:     cfg.Scheduler.TaskTimeout,
+	})
+	return s
+}
+
+func NewQueueCatalog(_ helpers.MetricsCtx, lc fx.Lifecycle, cfg *config.Conf) (*distributed.Catalog, error) {
+	c, err := distributed.NewCatalog(cfg.Queue)
+	if err != nil {
+		return nil, err
+	}
+	c.SetNotifierReporter(func(ctx context.Context, instance string, inspector *asynq.Inspector) {
+		queue.ReportStats(ctx, inspector, instance, queue.DefaultStatsInterval)
+	})
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return c.Close()
+		},
+	})
+	return c, nil
 }
@@ -0,0 +1,53 @@
+package modules
+
+import (
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/lily/chain/gossip"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain"
+	"github.com/filecoin-project/lotus/chain/consensus"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/sub"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+)
+
+// HandleIncomingBlocks replaces the lotus module of the same name, it validates and syncs the blocks received over the
+// blocks topic as lotus does and additionally reports them to `tracker`.
+func HandleIncomingBlocks(mctx helpers.MetricsCtx,
+	lc fx.Lifecycle,
+	ps *pubsub.PubSub,
+	s *chain.Syncer,
+	bserv dtypes.ChainBlockService,
+	chain *store.ChainStore,
+	cns consensus.Consensus,
+	h host.Host,
+	nn dtypes.NetworkName,
+	tracker *gossip.BlockTracker) {
+	ctx := helpers.LifecycleCtx(mctx, lc)
+
+	v := sub.NewBlockValidator(
+		h.ID(), chain, cns,
+		func(p peer.ID) {
+			ps.BlacklistPeer(p)
+			h.ConnManager().TagPeer(p, "badblock", -1000)
+		})
+
+	if err := ps.RegisterTopicValidator(build.BlocksTopic(nn), tracker.Validator(v.Validate)); err != nil {
+		panic(err)
+	}
+
+	log.Infof("subscribing to pubsub topic %s", build.BlocksTopic(nn))
+
+	blocksub, err := ps.Subscribe(build.BlocksTopic(nn)) //nolint
+	if err != nil {
+		panic(err)
+	}
+
+	go sub.HandleIncomingBlocks(ctx, blocksub, s, bserv, h.ConnManager())
+}
@@ -0,0 +1,71 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/lily/config"
+	"github.com/filecoin-project/lily/storage"
+	"github.com/filecoin-project/lily/storage/query"
+
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+)
+
+// NewQueryServer starts the read-only query API when it is enabled in the config. It returns a nil server when the
+// query API is disabled.
+func NewQueryServer(mctx helpers.MetricsCtx, lc fx.Lifecycle, cfg *config.Conf, catalog *storage.Catalog) (*query.Server, error) {
+	qc := cfg.QueryAPI
+	if qc.ListenAddress == "" {
+		return nil, nil
+	}
+
+	addr, err := multiaddr.NewMultiaddr(qc.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("parsing query api listen address: %w", err)
+	}
+
+	db, err := catalog.ConnectAsDatabase(mctx, qc.Storage, storage.Metadata{JobName: "query-api"})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to query api storage %q: %w", qc.Storage, err)
+	}
+
+	version, _, err := db.GetSchemaVersions(mctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting query api storage schema version: %w", err)
+	}
+
+	schema, err := query.NewSchema(version)
+	if err != nil {
+		return nil, fmt.Errorf("building query api schema: %w", err)
+	}
+
+	srv := query.NewServer(db.AsORM(), schema, query.WithMaxPageSize(qc.MaxPageSize))
+	hs := &http.Server{Handler: srv}
+
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			lst, err := manet.Listen(addr)
+			if err != nil {
+				return fmt.Errorf("query api could not listen: %w", err)
+			}
+			log.Infow("serving query api", "address", addr.String(), "storage", qc.Storage, "schema", version.String())
+			go func() {
+				if err := hs.Serve(manet.NetListener(lst)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Errorw("query api stopped", "error", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return hs.Shutdown(ctx)
+		},
+	})
+
+	return srv, nil
+}
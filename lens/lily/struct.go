@@ -9,6 +9,7 @@ import (
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/chain/indexer/distributed/queue"
 	"github.com/filecoin-project/lily/schedule"
 	"github.com/filecoin-project/specs-actors/actors/util/adt"
 
@@ -34,6 +35,9 @@ type LilyAPIStruct struct {
 		LilyWatch  func(context.Context, *LilyWatchConfig) (*schedule.JobSubmitResult, error)  `perm:"read"`
 		LilyWalk   func(context.Context, *LilyWalkConfig) (*schedule.JobSubmitResult, error)   `perm:"read"`
 		LilySurvey func(context.Context, *LilySurveyConfig) (*schedule.JobSubmitResult, error) `perm:"read"`
+		LilyPrune  func(context.Context, *LilyPruneConfig) (*schedule.JobSubmitResult, error)  `perm:"read"`
+
+		LilyExtract func(ctx context.Context, tsk types.TipSetKey, tasks []string) (<-chan *ExtractResult, error) `perm:"read"`
 
 		LilyIndexNotify   func(ctx context.Context, config *LilyIndexNotifyConfig) (interface{}, error)                 `perm:"read"`
 		LilyWatchNotify   func(ctx context.Context, config *LilyWatchNotifyConfig) (*schedule.JobSubmitResult, error)   `perm:"read"`
@@ -92,6 +96,7 @@ type LilyAPIStruct struct {
 		NetDisconnect    func(context.Context, peer.ID) error                          `perm:"read"`
 
 		StartTipSetWorker func(ctx context.Context, cfg *LilyTipSetWorkerConfig) (*schedule.JobSubmitResult, error) `perm:"read"`
+		LilyQueueStats    func(ctx context.Context, name string) ([]*queue.QueueStats, error)                       `perm:"read"`
 
 		FindOldestState func(ctx context.Context, limit int64) ([]*StateReport, error)      `perm:"read"`
 		StateCompute    func(ctx context.Context, tsk types.TipSetKey) (interface{}, error) `perm:"read"`
@@ -186,6 +191,10 @@ func (s *LilyAPIStruct) LilySurvey(ctx context.Context, cfg *LilySurveyConfig) (
 	return s.Internal.LilySurvey(ctx, cfg)
 }
 
+func (s *LilyAPIStruct) LilyPrune(ctx context.Context, cfg *LilyPruneConfig) (*schedule.JobSubmitResult, error) {
+	return s.Internal.LilyPrune(ctx, cfg)
+}
+
 func (s *LilyAPIStruct) LilyJobStart(ctx context.Context, ID schedule.JobID) error {
 	return s.Internal.LilyJobStart(ctx, ID)
 }
@@ -266,6 +275,14 @@ func (s *LilyAPIStruct) StartTipSetWorker(ctx context.Context, cfg *LilyTipSetWo
 	return s.Internal.StartTipSetWorker(ctx, cfg)
 }
 
+func (s *LilyAPIStruct) LilyExtract(ctx context.Context, tsk types.TipSetKey, tasks []string) (<-chan *ExtractResult, error) {
+	return s.Internal.LilyExtract(ctx, tsk, tasks)
+}
+
+func (s *LilyAPIStruct) LilyQueueStats(ctx context.Context, name string) ([]*queue.QueueStats, error) {
+	return s.Internal.LilyQueueStats(ctx, name)
+}
+
 func (s *LilyAPIStruct) LilyIndexNotify(ctx context.Context, cfg *LilyIndexNotifyConfig) (interface{}, error) {
 	return s.Internal.LilyIndexNotify(ctx, cfg)
 }
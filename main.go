@@ -159,6 +159,7 @@ func main() {
 			commands.ChainCmd,
 			commands.DaemonCmd,
 			commands.ExportChainCmd,
+			commands.ExtractCmd,
 			commands.InitCmd,
 			commands.LogCmd,
 			commands.MigrateCmd,
@@ -41,6 +41,8 @@ var (
 	TipsetHeight            = stats.Int64("tipset_height", "The height of the tipset being processed by a task", stats.UnitDimensionless)
 	ProcessingFailure       = stats.Int64("processing_failure", "Number of processing failures", stats.UnitDimensionless)
 	PersistFailure          = stats.Int64("persist_failure", "Number of persistence failures", stats.UnitDimensionless)
+	TaskTimeout             = stats.Int64("task_timeout", "Number of tasks skipped due to exceeding the task timeout", stats.UnitDimensionless)
+	TaskBudgetInUse         = stats.Int64("task_budget_in_use", "Number of tasks currently executing across all jobs of the scheduler", stats.UnitDimensionless)
 	WatchHeight             = stats.Int64("watch_height", "The height of the tipset last seen by the watch command", stats.UnitDimensionless)
 	TipSetSkip              = stats.Int64("tipset_skip", "Number of tipsets that were not processed. This is is an indication that lily cannot keep up with chain.", stats.UnitDimensionless)
 	JobStart                = stats.Int64("job_start", "Number of jobs started", stats.UnitDimensionless)
@@ -53,6 +55,9 @@ var (
 	TipSetCacheEmptyRevert  = stats.Int64("tipset_cache_empty_revert", "Number of revert operations performed on an empty tipset cache. This is an indication that a chain reorg is underway that is deeper than the cache size and includes tipsets that have already been read from the cache.", stats.UnitDimensionless)
 	WatcherActiveWorkers    = stats.Int64("watcher_active_workers", "Current number of tipset indexers executing", stats.UnitDimensionless)
 	WatcherWaitingWorkers   = stats.Int64("watcher_waiting_workers", "Current number of tipset indexers waiting to execute", stats.UnitDimensionless)
+	WatcherBacklog          = stats.Int64("watcher_backlog", "Current number of heights the watcher failed to index and is waiting to backfill", stats.UnitDimensionless)
+	WatcherBacklogDropped   = stats.Int64("watcher_backlog_dropped", "Number of heights the watcher failed to index that were not added to a full backlog", stats.UnitDimensionless)
+	WatcherBackfill         = stats.Int64("watcher_backfill", "Number of heights submitted for backfilling by the watcher", stats.UnitDimensionless)
 
 	// DataSource API
 
@@ -70,6 +75,12 @@ var (
 
 	TipSetWorkerConcurrency   = stats.Int64("tipset_worker_concurrency", "Concurrency of tipset worker", stats.UnitDimensionless)
 	TipSetWorkerQueuePriority = stats.Int64("tipset_worker_queue_priority", "Priority of tipset worker queue", stats.UnitDimensionless)
+	TipSetQueuePending        = stats.Int64("tipset_queue_pending", "Number of tasks in a tipset queue waiting to be processed", stats.UnitDimensionless)
+	TipSetQueueActive         = stats.Int64("tipset_queue_active", "Number of tasks in a tipset queue currently being processed", stats.UnitDimensionless)
+	TipSetQueueScheduled      = stats.Int64("tipset_queue_scheduled", "Number of tasks in a tipset queue scheduled to be processed in the future", stats.UnitDimensionless)
+	TipSetQueueRetry          = stats.Int64("tipset_queue_retry", "Number of tasks in a tipset queue that failed and are waiting to be retried", stats.UnitDimensionless)
+	TipSetQueueArchived       = stats.Int64("tipset_queue_archived", "Number of tasks in a tipset queue that exhausted their retries", stats.UnitDimensionless)
+	TipSetQueueLatency        = stats.Float64("tipset_queue_latency_ms", "Age of the oldest pending task in a tipset queue", stats.UnitMilliseconds)
 
 	// Store caches
 	StateStoreCacheLimit = stats.Int64("state_store_cache_limit", "Max size of cache", stats.UnitDimensionless)
@@ -139,6 +150,36 @@ var DefaultViews = []*view.View{
 		Aggregation: view.LastValue(),
 		TagKeys:     []tag.Key{QueueName},
 	},
+	{
+		Measure:     TipSetQueuePending,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{QueueName, Job},
+	},
+	{
+		Measure:     TipSetQueueActive,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{QueueName, Job},
+	},
+	{
+		Measure:     TipSetQueueScheduled,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{QueueName, Job},
+	},
+	{
+		Measure:     TipSetQueueRetry,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{QueueName, Job},
+	},
+	{
+		Measure:     TipSetQueueArchived,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{QueueName, Job},
+	},
+	{
+		Measure:     TipSetQueueLatency,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{QueueName, Job},
+	},
 	{
 		Measure:     DataSourceMessageExecutionRead,
 		Aggregation: view.Count(),
@@ -216,6 +257,16 @@ var DefaultViews = []*view.View{
 		Aggregation: view.LastValue(),
 		TagKeys:     []tag.Key{Job},
 	},
+	{
+		Name:        TaskTimeout.Name() + "_total",
+		Measure:     TaskTimeout,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{TaskType, Name},
+	},
+	{
+		Measure:     TaskBudgetInUse,
+		Aggregation: view.LastValue(),
+	},
 	{
 		Name:        TipSetSkip.Name() + "_total",
 		Measure:     TipSetSkip,
@@ -285,6 +336,23 @@ var DefaultViews = []*view.View{
 		Aggregation: view.LastValue(),
 		TagKeys:     []tag.Key{Job},
 	},
+	{
+		Measure:     WatcherBacklog,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{Job},
+	},
+	{
+		Name:        WatcherBacklogDropped.Name() + "_total",
+		Measure:     WatcherBacklogDropped,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Job},
+	},
+	{
+		Name:        WatcherBackfill.Name() + "_total",
+		Measure:     WatcherBackfill,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{Job},
+	},
 }
 
 // SinceInMilliseconds returns the duration of time since the provide time as a float64.
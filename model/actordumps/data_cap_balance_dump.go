@@ -0,0 +1,49 @@
+package actordumps
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// DataCapBalanceDump is a snapshot of the DataCap balance of a client in the datacap actor state.
+type DataCapBalanceDump struct {
+	tableName struct{} `pg:"data_cap_balance_dumps"` // nolint: structcheck
+
+	// Height of the snapshot.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// Address is the ID address of the client.
+	Address string `pg:",pk,notnull"`
+	// StateRoot the snapshot was taken from.
+	StateRoot string `pg:",notnull"`
+	// DataCap is the DataCap balance of the client in bytes.
+	DataCap string `pg:"type:numeric,notnull"`
+}
+
+func (d *DataCapBalanceDump) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "DataCapBalanceDump.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "data_cap_balance_dumps"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, d)
+}
+
+type DataCapBalanceDumpList []*DataCapBalanceDump
+
+func (dl DataCapBalanceDumpList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "DataCapBalanceDumpList.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "data_cap_balance_dumps"))
+
+	if len(dl) == 0 {
+		return nil
+	}
+	metrics.RecordCount(ctx, metrics.PersistModel, len(dl))
+	return s.PersistModel(ctx, dl)
+}
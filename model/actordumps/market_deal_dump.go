@@ -0,0 +1,79 @@
+package actordumps
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// MarketDealDump is a snapshot of a deal in the storage market actor state.
+type MarketDealDump struct {
+	tableName struct{} `pg:"market_deal_dumps"` // nolint: structcheck
+
+	// Height of the snapshot.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// DealID is the identifier of the deal.
+	DealID uint64 `pg:",pk,use_zero"`
+	// StateRoot the snapshot was taken from.
+	StateRoot string `pg:",notnull"`
+
+	// PieceCID is the CID of the data stored by the deal.
+	PieceCID string `pg:",notnull"`
+	// PaddedPieceSize is the padded size of the piece in bytes.
+	PaddedPieceSize uint64 `pg:",use_zero"`
+	// UnpaddedPieceSize is the unpadded size of the piece in bytes.
+	UnpaddedPieceSize uint64 `pg:",use_zero"`
+	// IsVerified is true when the deal is paid for with DataCap.
+	IsVerified bool `pg:",notnull,use_zero"`
+	// ClientID is the ID address of the client.
+	ClientID string `pg:",notnull"`
+	// ProviderID is the ID address of the storage provider.
+	ProviderID string `pg:",notnull"`
+	// StartEpoch is the epoch the deal is expected to start at.
+	StartEpoch int64 `pg:",use_zero"`
+	// EndEpoch is the epoch the deal expires at.
+	EndEpoch int64 `pg:",use_zero"`
+	// StoragePricePerEpoch attoFIL paid by the client each epoch of the deal.
+	StoragePricePerEpoch string `pg:"type:numeric,notnull"`
+	// ProviderCollateral attoFIL locked by the provider.
+	ProviderCollateral string `pg:"type:numeric,notnull"`
+	// ClientCollateral attoFIL locked by the client.
+	ClientCollateral string `pg:"type:numeric,notnull"`
+
+	// SectorID is the sector the deal is stored in, zero if the deal is not yet activated or the network does not record it.
+	SectorID uint64 `pg:",use_zero"`
+	// SectorStartEpoch is the epoch the deal was activated at, -1 if not yet activated.
+	SectorStartEpoch int64 `pg:",use_zero"`
+	// LastUpdateEpoch is the epoch the deal was last updated at, -1 if never updated.
+	LastUpdateEpoch int64 `pg:",use_zero"`
+	// SlashEpoch is the epoch the deal was slashed at, -1 if never slashed.
+	SlashEpoch int64 `pg:",use_zero"`
+}
+
+func (d *MarketDealDump) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "MarketDealDump.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "market_deal_dumps"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, d)
+}
+
+type MarketDealDumpList []*MarketDealDump
+
+func (dl MarketDealDumpList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "MarketDealDumpList.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "market_deal_dumps"))
+
+	if len(dl) == 0 {
+		return nil
+	}
+	metrics.RecordCount(ctx, metrics.PersistModel, len(dl))
+	return s.PersistModel(ctx, dl)
+}
@@ -0,0 +1,64 @@
+package actordumps
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// MultisigActorDump is a snapshot of the state of a multisig actor.
+type MultisigActorDump struct {
+	tableName struct{} `pg:"multisig_actor_dumps"` // nolint: structcheck
+
+	// Height of the snapshot.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// MultisigID is the ID address of the multisig actor.
+	MultisigID string `pg:",pk,notnull"`
+	// StateRoot the snapshot was taken from.
+	StateRoot string `pg:",notnull"`
+
+	// Balance attoFIL held by the multisig.
+	Balance string `pg:"type:numeric,notnull"`
+	// Signers are the ID addresses of the signers of the multisig.
+	Signers string `pg:",type:jsonb"`
+	// Threshold is the number of signers required to approve a transaction.
+	Threshold uint64 `pg:",use_zero"`
+	// InitialBalance attoFIL subject to vesting.
+	InitialBalance string `pg:"type:numeric,notnull"`
+	// LockedBalance attoFIL of the balance still locked by vesting.
+	LockedBalance string `pg:"type:numeric,notnull"`
+	// StartEpoch is the epoch vesting started at.
+	StartEpoch int64 `pg:",use_zero"`
+	// UnlockDuration is the number of epochs the initial balance vests over.
+	UnlockDuration int64 `pg:",use_zero"`
+	// PendingTransactions is the number of transactions awaiting approval.
+	PendingTransactions int64 `pg:",use_zero"`
+}
+
+func (d *MultisigActorDump) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "MultisigActorDump.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "multisig_actor_dumps"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, d)
+}
+
+type MultisigActorDumpList []*MultisigActorDump
+
+func (dl MultisigActorDumpList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "MultisigActorDumpList.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "multisig_actor_dumps"))
+
+	if len(dl) == 0 {
+		return nil
+	}
+	metrics.RecordCount(ctx, metrics.PersistModel, len(dl))
+	return s.PersistModel(ctx, dl)
+}
@@ -0,0 +1,51 @@
+package actordumps
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// PowerClaimDump is a snapshot of the power claim of a miner in the storage power actor state.
+type PowerClaimDump struct {
+	tableName struct{} `pg:"power_claim_dumps"` // nolint: structcheck
+
+	// Height of the snapshot.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// MinerID is the ID address of the miner.
+	MinerID string `pg:",pk,notnull"`
+	// StateRoot the snapshot was taken from.
+	StateRoot string `pg:",notnull"`
+	// RawBytePower is the raw byte power of the miner in bytes.
+	RawBytePower string `pg:"type:numeric,notnull"`
+	// QualityAdjPower is the quality adjusted power of the miner in bytes.
+	QualityAdjPower string `pg:"type:numeric,notnull"`
+}
+
+func (d *PowerClaimDump) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "PowerClaimDump.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "power_claim_dumps"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, d)
+}
+
+type PowerClaimDumpList []*PowerClaimDump
+
+func (dl PowerClaimDumpList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "PowerClaimDumpList.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "power_claim_dumps"))
+
+	if len(dl) == 0 {
+		return nil
+	}
+	metrics.RecordCount(ctx, metrics.PersistModel, len(dl))
+	return s.PersistModel(ctx, dl)
+}
@@ -0,0 +1,57 @@
+package actordumps
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// VerifiedRegistryKind* describe the role of an address in the verified registry.
+const (
+	VerifiedRegistryKindVerifier = "verifier"
+	VerifiedRegistryKindClient   = "client"
+)
+
+// VerifiedRegistryDump is a snapshot of a verifier or a verified client in the verified registry actor state.
+type VerifiedRegistryDump struct {
+	tableName struct{} `pg:"verified_registry_dumps"` // nolint: structcheck
+
+	// Height of the snapshot.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// Address is the ID address of the verifier or client.
+	Address string `pg:",pk,notnull"`
+	// Kind is either verifier or client.
+	Kind string `pg:",pk,notnull"`
+	// StateRoot the snapshot was taken from.
+	StateRoot string `pg:",notnull"`
+	// DataCap is the DataCap allowance of the verifier or client in bytes.
+	DataCap string `pg:"type:numeric,notnull"`
+}
+
+func (d *VerifiedRegistryDump) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "VerifiedRegistryDump.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "verified_registry_dumps"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, d)
+}
+
+type VerifiedRegistryDumpList []*VerifiedRegistryDump
+
+func (dl VerifiedRegistryDumpList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "VerifiedRegistryDumpList.Persist")
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "verified_registry_dumps"))
+
+	if len(dl) == 0 {
+		return nil
+	}
+	metrics.RecordCount(ctx, metrics.PersistModel, len(dl))
+	return s.PersistModel(ctx, dl)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/lily/model"
 )
 
+// BuiltInActorEvent is a builtin actor event stored with its entries as json. Events of a type with a typed table are not stored when the task of the typed table runs in the same job.
 type BuiltInActorEvent struct {
 	tableName struct{} `pg:"builtin_actor_events"` // nolint: structcheck
 
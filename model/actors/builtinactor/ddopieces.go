@@ -0,0 +1,65 @@
+package builtinactor
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// DDOPiece is a piece of data onboarded into a sector, decoded from the piece list of a sector-activated or
+// sector-updated event and matched with the verified registry claim and market deal activated for the piece.
+type DDOPiece struct {
+	tableName struct{} `pg:"ddo_pieces"` // nolint: structcheck
+
+	// Height the piece was activated at.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// Miner is the ID address of the miner the sector belongs to.
+	Miner string `pg:",pk,notnull"`
+	// SectorID is the number of the sector the piece was activated in.
+	SectorID uint64 `pg:",pk,use_zero"`
+	// PieceCID is the CID of the piece.
+	PieceCID string `pg:",pk,notnull"`
+	// StateRoot the activating message was applied to.
+	StateRoot string `pg:",notnull"`
+	// MessageCid is the CID of the message that activated the piece.
+	MessageCid string `pg:",notnull"`
+	// EventType is sector-activated for new sectors and sector-updated for snapped sectors.
+	EventType string `pg:",notnull"`
+	// PieceSize is the padded size of the piece in bytes.
+	PieceSize uint64 `pg:",use_zero"`
+	// UnsealedCid is the CID of the unsealed data of the sector, null for sectors without data.
+	UnsealedCid string
+	// Verified is true when the piece is covered by a verified registry claim or a verified deal.
+	Verified bool `pg:",notnull,use_zero"`
+	// ClaimID is the identifier of the verified registry claim for the piece, null if the piece is not claimed.
+	ClaimID *uint64
+	// DealID is the identifier of the market deal for the piece, null if the piece was onboarded without a deal.
+	DealID *uint64
+	// Client is the ID address of the client of the claim or deal, null if the piece has neither.
+	Client string
+}
+
+func (d *DDOPiece) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "ddo_pieces"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, d)
+}
+
+type DDOPieces []*DDOPiece
+
+func (dl DDOPieces) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "DDOPieces.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(dl)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "ddo_pieces"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(dl))
+	return s.PersistModel(ctx, dl)
+}
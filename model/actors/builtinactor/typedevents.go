@@ -0,0 +1,259 @@
+package builtinactor
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// The models in this file hold builtin actor events decoded into typed columns. The `event` tag of a field names the
+// event entry the field is decoded from, optionally followed by the encoding of the entry: `id` for actor IDs which
+// are stored as ID addresses, `cid` for CIDs and `bigint` for token amounts. Slice fields collect the values of
+// repeated entries.
+
+// VerifierBalanceEvent is a verifier-balance event emitted by the verified registry actor when the datacap allowance
+// of a verifier changes.
+type VerifierBalanceEvent struct {
+	tableName struct{} `pg:"builtin_verifier_balance_events"` // nolint: structcheck
+
+	// Height of the tipset whose execution emitted the event.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// MessageCid is the CID of the message whose execution emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// EventIdx is the index of the event among the events emitted when executing the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Emitter is the address of the actor that emitted the event.
+	Emitter string `pg:",notnull"`
+	// EventType is the type of the event.
+	EventType string `pg:",notnull"`
+
+	// Verifier is the ID address of the verifier.
+	Verifier string `pg:",notnull" event:"verifier,id"`
+	// Balance is the datacap allowance of the verifier after the change.
+	Balance string `pg:"type:numeric,notnull" event:"balance,bigint"`
+}
+
+func (e *VerifierBalanceEvent) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_verifier_balance_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+type VerifierBalanceEvents []*VerifierBalanceEvent
+
+func (el VerifierBalanceEvents) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "VerifierBalanceEvents.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(el)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_verifier_balance_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(el))
+	return s.PersistModel(ctx, el)
+}
+
+// AllocationEvent is an allocation or allocation-removed event emitted by the verified registry actor when datacap is
+// allocated to a piece or an expired allocation is removed.
+type AllocationEvent struct {
+	tableName struct{} `pg:"builtin_allocation_events"` // nolint: structcheck
+
+	// Height of the tipset whose execution emitted the event.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// MessageCid is the CID of the message whose execution emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// EventIdx is the index of the event among the events emitted when executing the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Emitter is the address of the actor that emitted the event.
+	Emitter string `pg:",notnull"`
+	// EventType is the type of the event.
+	EventType string `pg:",notnull"`
+
+	// AllocationID is the identifier of the allocation.
+	AllocationID uint64 `pg:",use_zero" event:"id"`
+	// Client is the ID address of the client the datacap is allocated from.
+	Client string `pg:",notnull" event:"client,id"`
+	// Provider is the ID address of the provider the piece is allocated to.
+	Provider string `pg:",notnull" event:"provider,id"`
+	// PieceCid is the CID of the allocated piece.
+	PieceCid string `event:"piece-cid,cid"`
+	// PieceSize is the padded size of the allocated piece in bytes.
+	PieceSize uint64 `pg:",use_zero" event:"piece-size"`
+	// TermMin is the minimum duration in epochs the provider must commit to store the piece for.
+	TermMin int64 `pg:",use_zero" event:"term-min"`
+	// TermMax is the maximum duration in epochs the client may extend the claim of the piece to.
+	TermMax int64 `pg:",use_zero" event:"term-max"`
+	// Expiration is the epoch by which the provider must claim the allocation.
+	Expiration int64 `pg:",use_zero" event:"expiration"`
+}
+
+func (e *AllocationEvent) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_allocation_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+type AllocationEvents []*AllocationEvent
+
+func (el AllocationEvents) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "AllocationEvents.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(el)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_allocation_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(el))
+	return s.PersistModel(ctx, el)
+}
+
+// ClaimEvent is a claim, claim-updated or claim-removed event emitted by the verified registry actor when a provider
+// claims an allocation, the term of a claim is extended or an expired claim is removed.
+type ClaimEvent struct {
+	tableName struct{} `pg:"builtin_claim_events"` // nolint: structcheck
+
+	// Height of the tipset whose execution emitted the event.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// MessageCid is the CID of the message whose execution emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// EventIdx is the index of the event among the events emitted when executing the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Emitter is the address of the actor that emitted the event.
+	Emitter string `pg:",notnull"`
+	// EventType is the type of the event.
+	EventType string `pg:",notnull"`
+
+	// ClaimID is the identifier of the claim.
+	ClaimID uint64 `pg:",use_zero" event:"id"`
+	// Client is the ID address of the client the datacap of the claim was allocated from.
+	Client string `pg:",notnull" event:"client,id"`
+	// Provider is the ID address of the provider storing the claimed piece.
+	Provider string `pg:",notnull" event:"provider,id"`
+	// PieceCid is the CID of the claimed piece.
+	PieceCid string `event:"piece-cid,cid"`
+	// PieceSize is the padded size of the claimed piece in bytes.
+	PieceSize uint64 `pg:",use_zero" event:"piece-size"`
+	// TermMin is the minimum duration in epochs the provider committed to store the piece for.
+	TermMin int64 `pg:",use_zero" event:"term-min"`
+	// TermMax is the maximum duration in epochs the client may extend the claim to.
+	TermMax int64 `pg:",use_zero" event:"term-max"`
+	// TermStart is the epoch the claim started at.
+	TermStart int64 `pg:",use_zero" event:"term-start"`
+	// Sector is the number of the sector holding the claimed piece.
+	Sector uint64 `pg:",use_zero" event:"sector"`
+}
+
+func (e *ClaimEvent) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_claim_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+type ClaimEvents []*ClaimEvent
+
+func (el ClaimEvents) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "ClaimEvents.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(el)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_claim_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(el))
+	return s.PersistModel(ctx, el)
+}
+
+// DealEvent is a deal-published, deal-activated, deal-terminated or deal-completed event emitted by the storage market
+// actor as a deal moves through its lifecycle.
+type DealEvent struct {
+	tableName struct{} `pg:"builtin_deal_events"` // nolint: structcheck
+
+	// Height of the tipset whose execution emitted the event.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// MessageCid is the CID of the message whose execution emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// EventIdx is the index of the event among the events emitted when executing the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Emitter is the address of the actor that emitted the event.
+	Emitter string `pg:",notnull"`
+	// EventType is the type of the event.
+	EventType string `pg:",notnull"`
+
+	// DealID is the identifier of the deal.
+	DealID uint64 `pg:",use_zero" event:"id"`
+	// Client is the ID address of the client of the deal.
+	Client string `pg:",notnull" event:"client,id"`
+	// Provider is the ID address of the provider of the deal.
+	Provider string `pg:",notnull" event:"provider,id"`
+}
+
+func (e *DealEvent) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_deal_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+type DealEvents []*DealEvent
+
+func (el DealEvents) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "DealEvents.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(el)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_deal_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(el))
+	return s.PersistModel(ctx, el)
+}
+
+// SectorEvent is a sector-precommitted, sector-activated, sector-updated or sector-terminated event emitted by a miner
+// actor as a sector moves through its lifecycle.
+type SectorEvent struct {
+	tableName struct{} `pg:"builtin_sector_events"` // nolint: structcheck
+
+	// Height of the tipset whose execution emitted the event.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// MessageCid is the CID of the message whose execution emitted the event.
+	MessageCid string `pg:",pk,notnull"`
+	// EventIdx is the index of the event among the events emitted when executing the tipset.
+	EventIdx int64 `pg:",pk,notnull,use_zero"`
+	// Emitter is the address of the actor that emitted the event.
+	Emitter string `pg:",notnull"`
+	// EventType is the type of the event.
+	EventType string `pg:",notnull"`
+
+	// Sector is the number of the sector.
+	Sector uint64 `pg:",use_zero" event:"sector"`
+	// UnsealedCid is the CID of the unsealed data of an activated or updated sector, null for sectors without data.
+	UnsealedCid string `event:"unsealed-cid,cid"`
+	// PieceCids are the CIDs of the pieces of an activated or updated sector.
+	PieceCids []string `pg:",array" event:"piece-cid,cid"`
+	// PieceSizes are the padded sizes in bytes of the pieces of an activated or updated sector.
+	PieceSizes []uint64 `pg:",array" event:"piece-size"`
+}
+
+func (e *SectorEvent) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_sector_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+type SectorEvents []*SectorEvent
+
+func (el SectorEvents) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, span := otel.Tracer("").Start(ctx, "SectorEvents.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(el)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "builtin_sector_events"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(el))
+	return s.PersistModel(ctx, el)
+}
@@ -0,0 +1,55 @@
+package multisig
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// MultisigInfo is the configuration of a multisig actor, recorded when the actor is created and whenever its signers,
+// threshold or vesting parameters change, along with its locked and unlocked balance at that time.
+type MultisigInfo struct {
+	tableName struct{} `pg:"multisig_info"` // nolint: structcheck
+
+	// Height the configuration was observed at.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// MultisigID is the ID address of the multisig actor.
+	MultisigID string `pg:",pk,notnull"`
+	// StateRoot the configuration was observed in.
+	StateRoot string `pg:",pk,notnull"`
+
+	// Signers are the addresses of the signers of the multisig.
+	Signers []string `pg:",notnull"`
+	// Threshold is the number of signers required to approve a transaction.
+	Threshold uint64 `pg:",notnull,use_zero"`
+	// StartEpoch is the epoch the vesting of the initial balance started at.
+	StartEpoch int64 `pg:",notnull,use_zero"`
+	// UnlockDuration is the number of epochs over which the initial balance vests.
+	UnlockDuration int64 `pg:",notnull,use_zero"`
+	// InitialBalance is the amount of attoFIL locked at the start of the vesting.
+	InitialBalance string `pg:"type:numeric,notnull"`
+
+	// Balance is the balance of the multisig in attoFIL.
+	Balance string `pg:"type:numeric,notnull"`
+	// LockedBalance is the part of the initial balance in attoFIL that has not vested yet.
+	LockedBalance string `pg:"type:numeric,notnull"`
+	// UnlockedBalance is the part of the balance in attoFIL that may be spent.
+	UnlockedBalance string `pg:"type:numeric,notnull"`
+}
+
+func (m *MultisigInfo) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "multisig_info"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, m)
+}
+
+type MultisigInfoList []*MultisigInfo
+
+func (ml MultisigInfoList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "multisig_info"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(ml))
+	return s.PersistModel(ctx, ml)
+}
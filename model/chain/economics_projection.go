@@ -0,0 +1,54 @@
+package chain
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// ChainEconomicsProjection holds figures derived from the reward and power actor states of a tipset: how the network
+// power compares to the baseline, how much of the storage mining allocation has been minted by the simple and
+// baseline schedules and the initial pledge a new sector would require.
+type ChainEconomicsProjection struct {
+	tableName struct{} `pg:"chain_economics_projections"` // nolint: structcheck
+
+	// Height of the tipset the figures are derived from.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// StateRoot the figures are derived from.
+	StateRoot string `pg:",pk,notnull"`
+
+	// BaselinePower is the baseline power of the epoch in bytes.
+	BaselinePower string `pg:"type:numeric,notnull"`
+	// NetworkRawPower is the raw byte power of the network in bytes.
+	NetworkRawPower string `pg:"type:numeric,notnull"`
+	// NetworkQaPower is the quality adjusted power of the network in bytes.
+	NetworkQaPower string `pg:"type:numeric,notnull"`
+	// BaselineCrossed is true when the raw byte power of the network, which the reward actor compares to the
+	// baseline, is at or above the baseline power.
+	BaselineCrossed bool `pg:",notnull,use_zero"`
+	// EffectiveNetworkTime is the epoch at which the cumulative baseline power reaches the cumulative realized power.
+	EffectiveNetworkTime int64 `pg:",notnull,use_zero"`
+
+	// SimpleMinted is the amount of attoFIL minted by the simple schedule, which only depends on time.
+	SimpleMinted string `pg:"type:numeric,notnull"`
+	// BaselineMinted is the amount of attoFIL minted by the baseline schedule, which depends on the effective network
+	// time.
+	BaselineMinted string `pg:"type:numeric,notnull"`
+	// SimpleReward is the amount of attoFIL of the reward of the epoch minted by the simple schedule.
+	SimpleReward string `pg:"type:numeric,notnull"`
+	// BaselineReward is the amount of attoFIL of the reward of the epoch minted by the baseline schedule.
+	BaselineReward string `pg:"type:numeric,notnull"`
+
+	// SectorInitialPledge is the initial pledge in attoFIL of a 32GiB sector without deals.
+	SectorInitialPledge string `pg:"type:numeric,notnull"`
+}
+
+func (c *ChainEconomicsProjection) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "chain_economics_projections"))
+
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, c)
+}
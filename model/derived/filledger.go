@@ -0,0 +1,69 @@
+package derived
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// Reasons of FIL ledger entries.
+const (
+	LedgerReasonTransfer           = "transfer"             // value sent by an on-chain message
+	LedgerReasonInternalSend       = "internal_send"        // value sent by an actor while executing a message
+	LedgerReasonMultisigSend       = "multisig_send"        // value sent by a multisig actor
+	LedgerReasonMinerReward        = "miner_reward"         // block reward paid by the reward actor to a miner
+	LedgerReasonPenalty            = "penalty"              // value burnt by a miner actor, such as fault fees and termination penalties
+	LedgerReasonBurn               = "burn"                 // value sent to the burnt funds actor by any other actor
+	LedgerReasonBaseFeeBurn        = "base_fee_burn"        // base fee of the gas used by a message, burnt
+	LedgerReasonOverEstimationBurn = "over_estimation_burn" // fee of the gas over estimated by a message, burnt
+	LedgerReasonMinerTip           = "miner_tip"            // gas premium of a message paid to the reward actor
+)
+
+// FilLedgerEntry is a debit or credit of the balance of an actor caused by executing the messages of a tipset.
+type FilLedgerEntry struct {
+	tableName struct{} `pg:"fil_ledger"` // nolint: structcheck
+	// Height message was executed at.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// StateRoot message was applied to.
+	StateRoot string `pg:",pk,notnull"`
+	// Index orders the entries of the state transition, each debit is immediately followed by its credit.
+	Index uint64 `pg:",pk,notnull,use_zero"`
+	// Address is the ID address of the actor whose balance changed.
+	Address string `pg:",notnull"`
+	// Counterparty is the ID address of the actor on the other side of the entry.
+	Counterparty string `pg:",notnull"`
+	// Amount attoFIL credited to the actor, negative for debits.
+	Amount string `pg:"type:numeric,notnull"`
+	// Reason the balance changed.
+	Reason string `pg:",notnull"`
+	// MessageCid of the on-chain or implicit message whose execution changed the balance.
+	MessageCid string `pg:",notnull"`
+}
+
+func (e *FilLedgerEntry) Persist(ctx context.Context, s model.StorageBatch, version model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fil_ledger"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, e)
+}
+
+type FilLedgerEntryList []*FilLedgerEntry
+
+func (l FilLedgerEntryList) Persist(ctx context.Context, s model.StorageBatch, version model.Version) error {
+	if len(l) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer("").Start(ctx, "FilLedgerEntryList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "fil_ledger"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+	return s.PersistModel(ctx, l)
+}
@@ -0,0 +1,77 @@
+package derived
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// GasFeePercentilesAll is the actor family and method of the percentiles of every message executed in a tipset.
+const (
+	GasFeePercentilesAllFamily       = "all"
+	GasFeePercentilesAllMethod int64 = -1
+)
+
+// GasFeePercentiles are the percentiles of the gas prices of the messages to a method of an actor family executed in a
+// tipset.
+type GasFeePercentiles struct {
+	tableName struct{} `pg:"gas_fee_percentiles"` // nolint: structcheck
+	// Height messages were executed at.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// StateRoot messages were applied to.
+	StateRoot string `pg:",pk,notnull"`
+	// ActorFamily of the receivers of the messages, all for every message of the tipset.
+	ActorFamily string `pg:",pk,notnull"`
+	// Method called by the messages, -1 for every method.
+	Method int64 `pg:",pk,notnull,use_zero"`
+	// BaseFee attoFIL per unit gas paid by the messages.
+	BaseFee string `pg:"type:numeric,notnull"`
+	// MessageCount is the number of messages.
+	MessageCount int64 `pg:",notnull,use_zero"`
+	// BelowBaseFeeCount is the number of messages with a fee cap below the base fee.
+	BelowBaseFeeCount int64 `pg:",notnull,use_zero"`
+
+	// EffectiveGasPriceP10 attoFIL per unit gas paid by the sender, the lesser of the fee cap and the base fee plus premium.
+	EffectiveGasPriceP10 string `pg:"type:numeric,notnull"`
+	EffectiveGasPriceP50 string `pg:"type:numeric,notnull"`
+	EffectiveGasPriceP90 string `pg:"type:numeric,notnull"`
+	EffectiveGasPriceP99 string `pg:"type:numeric,notnull"`
+	// GasPremiumP10 attoFIL per unit gas.
+	GasPremiumP10 string `pg:"type:numeric,notnull"`
+	GasPremiumP50 string `pg:"type:numeric,notnull"`
+	GasPremiumP90 string `pg:"type:numeric,notnull"`
+	GasPremiumP99 string `pg:"type:numeric,notnull"`
+	// GasFeeCapP10 attoFIL per unit gas.
+	GasFeeCapP10 string `pg:"type:numeric,notnull"`
+	GasFeeCapP50 string `pg:"type:numeric,notnull"`
+	GasFeeCapP90 string `pg:"type:numeric,notnull"`
+	GasFeeCapP99 string `pg:"type:numeric,notnull"`
+}
+
+func (g *GasFeePercentiles) Persist(ctx context.Context, s model.StorageBatch, version model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "gas_fee_percentiles"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, g)
+}
+
+type GasFeePercentilesList []*GasFeePercentiles
+
+func (l GasFeePercentilesList) Persist(ctx context.Context, s model.StorageBatch, version model.Version) error {
+	if len(l) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer("").Start(ctx, "GasFeePercentilesList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "gas_fee_percentiles"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+	return s.PersistModel(ctx, l)
+}
@@ -0,0 +1,62 @@
+package derived
+
+import (
+	"context"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// MinerBlockReward is the reward paid to the miner of a block when the tipset containing the block is executed.
+type MinerBlockReward struct {
+	tableName struct{} `pg:"miner_block_rewards"` // nolint: structcheck
+	// Height of the block.
+	Height int64 `pg:",pk,notnull,use_zero"`
+	// BlockCid is the CID of the block.
+	BlockCid string `pg:",pk,notnull"`
+	// StateRoot the reward was applied to.
+	StateRoot string `pg:",notnull"`
+	// Miner is the ID address of the miner of the block.
+	Miner string `pg:",notnull"`
+	// WinCount is the number of reward units won by the block.
+	WinCount int64 `pg:",notnull,use_zero"`
+	// GrossReward attoFIL paid to the miner, the sum of the block reward and the tips.
+	GrossReward string `pg:"type:numeric,notnull"`
+	// BlockReward attoFIL paid to the miner by the reward actor for winning the block.
+	BlockReward string `pg:"type:numeric,notnull"`
+	// Tips attoFIL paid to the miner by the messages first included in the block.
+	Tips string `pg:"type:numeric,notnull"`
+	// Penalty attoFIL charged to the miner for including invalid messages in the block.
+	Penalty string `pg:"type:numeric,notnull"`
+	// LockedReward attoFIL of the gross reward locked in the vesting funds of the miner.
+	LockedReward string `pg:"type:numeric,notnull"`
+	// VestedReward attoFIL of the gross reward available to the miner immediately.
+	VestedReward string `pg:"type:numeric,notnull"`
+}
+
+func (r *MinerBlockReward) Persist(ctx context.Context, s model.StorageBatch, version model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "miner_block_rewards"))
+	metrics.RecordCount(ctx, metrics.PersistModel, 1)
+	return s.PersistModel(ctx, r)
+}
+
+type MinerBlockRewardList []*MinerBlockReward
+
+func (l MinerBlockRewardList) Persist(ctx context.Context, s model.StorageBatch, version model.Version) error {
+	if len(l) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer("").Start(ctx, "MinerBlockRewardList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "miner_block_rewards"))
+	metrics.RecordCount(ctx, metrics.PersistModel, len(l))
+	return s.PersistModel(ctx, l)
+}
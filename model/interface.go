@@ -19,6 +19,12 @@ type Storage interface {
 	PersistBatch(ctx context.Context, ps ...Persistable) error
 }
 
+// A PartitionedStorage partitions tables by height and must create the partitions for a height before data at that
+// height is persisted.
+type PartitionedStorage interface {
+	EnsurePartitions(ctx context.Context, height int64) error
+}
+
 // A StorageBatch persists a model to storage as part of a batch such as a transaction.
 type StorageBatch interface {
 	PersistModel(ctx context.Context, m interface{}) error
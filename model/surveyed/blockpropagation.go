@@ -0,0 +1,67 @@
+package observed
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+type BlockPropagation struct {
+	tableName struct{} `pg:"block_propagation"` // nolint: structcheck
+
+	// Height is the epoch of the block
+	Height int64 `pg:",pk,notnull,use_zero"`
+
+	// Cid is the CID of the block header
+	Cid string `pg:",pk,notnull"`
+
+	// SurveyerPeerID is the peer ID of the node that observed the block
+	SurveyerPeerID string `pg:",pk,notnull"`
+
+	// Miner is the address of the miner that produced the block
+	Miner string `pg:",notnull"`
+
+	// RelayPeerID is the peer ID of the peer the block was first received from
+	RelayPeerID string `pg:",notnull"`
+
+	// FirstSeen is the wall-clock time the block was first received
+	FirstSeen time.Time `pg:",notnull"`
+
+	// EpochStart is the expected start time of the block's epoch
+	EpochStart time.Time `pg:",notnull"`
+
+	// OffsetMs is the time between EpochStart and FirstSeen in milliseconds
+	OffsetMs int64 `pg:",use_zero,notnull"`
+
+	// Canonical is true if the block was included in the canonical chain once final
+	Canonical bool `pg:",use_zero,notnull"`
+}
+
+func (b *BlockPropagation) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "block_propagation"))
+
+	return s.PersistModel(ctx, b)
+}
+
+type BlockPropagationList []*BlockPropagation
+
+func (l BlockPropagationList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	if len(l) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer("").Start(ctx, "BlockPropagationList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "block_propagation"))
+
+	return s.PersistModel(ctx, l)
+}
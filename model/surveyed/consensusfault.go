@@ -0,0 +1,98 @@
+package observed
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// Types of consensus faults, as defined by the miner actor.
+const (
+	ConsensusFaultDoubleFork     = "double_fork"     // the miner mined two blocks at the same epoch
+	ConsensusFaultTimeOffset     = "time_offset"     // the miner mined two blocks with the same parents at different epochs
+	ConsensusFaultParentGrinding = "parent_grinding" // the miner mined a block on a tipset excluding its own block of the previous epoch
+)
+
+// Sources of consensus fault observations.
+const (
+	ConsensusFaultObserved = "observed" // the fault was detected from the block headers observed by the surveyer
+	ConsensusFaultReported = "reported" // the fault was reported to the miner actor by a message included in the canonical chain
+)
+
+type ConsensusFaultObservation struct {
+	tableName struct{} `pg:"consensus_fault_observations"` // nolint: structcheck
+
+	// Height is the epoch of the first block of the fault
+	Height int64 `pg:",pk,notnull,use_zero"`
+
+	// Block1Cid is the CID of the first block of the fault
+	Block1Cid string `pg:",pk,notnull"`
+
+	// Block2Cid is the CID of the second block of the fault
+	Block2Cid string `pg:",pk,notnull"`
+
+	// Source is either observed or reported
+	Source string `pg:",pk,notnull"`
+
+	// SurveyerPeerID is the peer ID of the node that observed the fault
+	SurveyerPeerID string `pg:",pk,notnull"`
+
+	// FaultType is one of double_fork, time_offset or parent_grinding
+	FaultType string `pg:",notnull"`
+
+	// Miner is the address of the miner that mined both blocks
+	Miner string `pg:",notnull"`
+
+	// Block2Height is the epoch of the second block of the fault
+	Block2Height int64 `pg:",notnull,use_zero"`
+
+	// BlockExtraCid is the CID of the block the second block was mined on instead of the first, set for parent grinding faults
+	BlockExtraCid string
+
+	// Block1Canonical is true if the first block was included in the canonical chain once final
+	Block1Canonical bool `pg:",use_zero,notnull"`
+
+	// Block2Canonical is true if the second block was included in the canonical chain once final
+	Block2Canonical bool `pg:",use_zero,notnull"`
+
+	// ReportMessageCid is the CID of the message reporting the fault, set for reported faults
+	ReportMessageCid string
+
+	// ReportHeight is the epoch the message reporting the fault was included at, set for reported faults
+	ReportHeight int64
+
+	// Reporter is the address of the sender of the message reporting the fault, set for reported faults
+	Reporter string
+
+	// ObservedAt is the wall-clock time the fault was detected
+	ObservedAt time.Time `pg:",notnull"`
+}
+
+func (c *ConsensusFaultObservation) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "consensus_fault_observations"))
+
+	return s.PersistModel(ctx, c)
+}
+
+type ConsensusFaultObservationList []*ConsensusFaultObservation
+
+func (l ConsensusFaultObservationList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	if len(l) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer("").Start(ctx, "ConsensusFaultObservationList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "consensus_fault_observations"))
+
+	return s.PersistModel(ctx, l)
+}
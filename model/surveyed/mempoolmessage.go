@@ -0,0 +1,92 @@
+package observed
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+// Statuses of mempool messages once final.
+const (
+	MempoolMessageIncluded = "included" // the message was included in the canonical chain
+	MempoolMessageReplaced = "replaced" // a message from the same sender with the same nonce was included instead
+	MempoolMessageDropped  = "dropped"  // no message from the sender with the same nonce was included within finality
+)
+
+type MempoolMessage struct {
+	tableName struct{} `pg:"mempool_messages"` // nolint: structcheck
+
+	// Cid is the CID of the message
+	Cid string `pg:",pk,notnull"`
+
+	// SurveyerPeerID is the peer ID of the node that observed the message
+	SurveyerPeerID string `pg:",pk,notnull"`
+
+	// From is the address of the sender of the message
+	From string `pg:",notnull"`
+
+	// Nonce is the sequence number of the message
+	Nonce uint64 `pg:",use_zero,notnull"`
+
+	// GasLimit is the gas limit of the message
+	GasLimit int64 `pg:",use_zero,notnull"`
+
+	// GasFeeCap is the maximum price of gas the sender will pay
+	GasFeeCap string `pg:"type:numeric,notnull"`
+
+	// GasPremium is the price of gas paid to the miner including the message
+	GasPremium string `pg:"type:numeric,notnull"`
+
+	// FirstSeen is the wall-clock time the message was first added to the mempool
+	FirstSeen time.Time `pg:",notnull"`
+
+	// FirstSeenHeight is the epoch the message was first added to the mempool
+	FirstSeenHeight int64 `pg:",use_zero,notnull"`
+
+	// Status is whether the message was included, replaced or dropped
+	Status string `pg:",notnull"`
+
+	// IncludedCid is the CID of the message included with the sender and nonce of the message, null if dropped
+	IncludedCid string
+
+	// InclusionHeight is the epoch of the tipset the message, or its replacement, was included in, null if dropped
+	InclusionHeight int64
+
+	// InclusionLatencyMs is the time between FirstSeen and the start of the inclusion epoch in milliseconds, null if dropped
+	InclusionLatencyMs int64
+
+	// InclusionBaseFee is the base fee paid by the messages of the inclusion tipset, null if dropped
+	InclusionBaseFee string `pg:"type:numeric"`
+
+	// FeeCapBaseFeeRatio is GasFeeCap divided by InclusionBaseFee, null if dropped
+	FeeCapBaseFeeRatio float64
+}
+
+func (m *MempoolMessage) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "mempool_messages"))
+
+	return s.PersistModel(ctx, m)
+}
+
+type MempoolMessageList []*MempoolMessage
+
+func (l MempoolMessageList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	if len(l) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer("").Start(ctx, "MempoolMessageList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "mempool_messages"))
+
+	return s.PersistModel(ctx, l)
+}
@@ -0,0 +1,79 @@
+package observed
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+type MinerCapability struct {
+	tableName struct{} `pg:"surveyed_miner_capabilities"` // nolint: structcheck
+
+	// ObservedAt is the time the observation was made.
+	ObservedAt time.Time `pg:",pk,notnull"`
+
+	// MinerID is the address of the miner observed.
+	MinerID string `pg:",pk,notnull"`
+
+	// PeerID is the peerID of the miner observed.
+	PeerID string
+
+	// Reachable is true if a connection to the miner could be established.
+	Reachable bool `pg:",use_zero"`
+
+	// FailureReason describes why the miner could not be surveyed, empty if the survey succeeded.
+	FailureReason string
+
+	// ConnectLatencyMs is the time taken to connect to the miner in milliseconds.
+	ConnectLatencyMs int64 `pg:",use_zero"`
+
+	// TransportsLatencyMs is the time taken to query the retrieval transports of the miner in milliseconds.
+	TransportsLatencyMs int64 `pg:",use_zero"`
+
+	// RetrievalTransports is the list of retrieval transports and their addresses served by the miner.
+	RetrievalTransports []RetrievalTransport
+
+	// RetrievalProtocols is the list of retrieval protocols supported by the miner.
+	RetrievalProtocols []string
+
+	// DealProtocols is the list of storage deal protocols supported by the miner.
+	DealProtocols []string
+
+	// AskProtocols is the list of storage ask protocol versions supported by the miner. The ask itself is not queried.
+	AskProtocols []string
+}
+
+// RetrievalTransport is a retrieval transport served by a miner, e.g. libp2p, http or bitswap.
+type RetrievalTransport struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses"`
+}
+
+func (m *MinerCapability) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "surveyed_miner_capabilities"))
+
+	return s.PersistModel(ctx, m)
+}
+
+type MinerCapabilityList []*MinerCapability
+
+func (m MinerCapabilityList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	if len(m) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer("").Start(ctx, "MinerCapabilityList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(m)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "surveyed_miner_capabilities"))
+
+	return s.PersistModel(ctx, m)
+}
@@ -0,0 +1,73 @@
+package observed
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
+)
+
+type PeerTopology struct {
+	tableName struct{} `pg:"surveyed_peer_topology"` // nolint: structcheck
+
+	// ObservedAt is the time the observation was made
+	ObservedAt time.Time `pg:",pk,notnull"`
+
+	// SurveyerPeerID is the peer ID of the node performing the survey
+	SurveyerPeerID string `pg:",pk,notnull"`
+
+	// PeerID is the peer ID of the observed peer
+	PeerID string `pg:",pk,notnull"`
+
+	// Agent is the agent string reported by the observed peer
+	Agent string
+
+	// Multiaddrs is the list of addresses known for the observed peer
+	Multiaddrs []string
+
+	// IP is the ip address the surveyer is connected to the observed peer on
+	IP string `pg:"ip"`
+
+	// ASN is the autonomous system number of IP, zero if unknown
+	ASN int64 `pg:"asn,use_zero"`
+
+	// ASOrganization is the organization operating ASN
+	ASOrganization string `pg:"as_organization"`
+
+	// Country is the ISO 3166-1 country code of IP
+	Country string
+
+	// Direction is the direction of the connection to the observed peer: inbound, outbound or unknown
+	Direction string
+
+	// LatencyMs is the moving average latency to the observed peer in milliseconds
+	LatencyMs int64 `pg:",use_zero"`
+}
+
+func (p *PeerTopology) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "surveyed_peer_topology"))
+
+	return s.PersistModel(ctx, p)
+}
+
+type PeerTopologyList []*PeerTopology
+
+func (l PeerTopologyList) Persist(ctx context.Context, s model.StorageBatch, _ model.Version) error {
+	if len(l) == 0 {
+		return nil
+	}
+	ctx, span := otel.Tracer("").Start(ctx, "PeerTopologyList.Persist")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.Int("count", len(l)))
+	}
+	defer span.End()
+
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.Table, "surveyed_peer_topology"))
+
+	return s.PersistModel(ctx, l)
+}
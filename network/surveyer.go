@@ -12,27 +12,60 @@ import (
 
 	"github.com/filecoin-project/lily/metrics"
 	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/tasks/survey/blockpropagation"
+	"github.com/filecoin-project/lily/tasks/survey/consensusfaults"
+	"github.com/filecoin-project/lily/tasks/survey/mempoolmessages"
+	"github.com/filecoin-project/lily/tasks/survey/minercapabilities"
 	"github.com/filecoin-project/lily/tasks/survey/minerprotocols"
 	"github.com/filecoin-project/lily/tasks/survey/peeragents"
+	"github.com/filecoin-project/lily/tasks/survey/peertopology"
 )
 
 const (
-	MinerProtocolsTask = "minerprotocols" // task that observes the supported protocols of miners on the Filecoin network.
-	PeerAgentsTask     = "peeragents"     // task that observes connected peer agents
+	MinerProtocolsTask    = "minerprotocols"    // task that observes the supported protocols of miners on the Filecoin network.
+	MinerCapabilitiesTask = "minercapabilities" // task that observes the retrieval and deal-making capabilities of miners on the Filecoin network.
+	PeerAgentsTask        = "peeragents"        // task that observes connected peer agents
+	PeerTopologyTask      = "peertopology"      // task that observes the addresses, location and connection of connected peers
+	BlockPropagationTask  = "blockpropagation"  // task that observes the propagation of blocks received over gossipsub
+	MempoolMessagesTask   = "mempoolmessages"   // task that observes messages added to the mempool and their inclusion
+	ConsensusFaultsTask   = "consensusfaults"   // task that detects consensus faults from observed and reported block headers
 )
 
 var log = logging.Logger("lily/network")
 
 type API interface {
 	minerprotocols.API
+	minercapabilities.API
 	peeragents.API
+	peertopology.API
+	blockpropagation.API
+	mempoolmessages.API
+	consensusfaults.API
 }
 
-func NewSurveyer(api API, storage model.Storage, interval time.Duration, name string, tasks []string) (*Surveyer, error) {
+type SurveyerOpt func(cfg *surveyerConfig)
+
+type surveyerConfig struct {
+	geoIPDatabases []string
+}
+
+// WithGeoIPDatabases sets the MaxMind DB files used by the peertopology task to resolve the ASN and country of peers.
+func WithGeoIPDatabases(paths []string) SurveyerOpt {
+	return func(cfg *surveyerConfig) {
+		cfg.geoIPDatabases = paths
+	}
+}
+
+func NewSurveyer(api API, storage model.Storage, interval time.Duration, name string, tasks []string, opts ...SurveyerOpt) (*Surveyer, error) {
 	if interval <= 0 {
 		return nil, fmt.Errorf("surveyer interval must be greater than zero: %d", interval)
 	}
 
+	var cfg surveyerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	obs := &Surveyer{
 		interval: interval,
 		storage:  storage,
@@ -44,8 +77,18 @@ func NewSurveyer(api API, storage model.Storage, interval time.Duration, name st
 		switch task {
 		case PeerAgentsTask:
 			obs.tasks[PeerAgentsTask] = peeragents.NewTask(api)
+		case PeerTopologyTask:
+			obs.tasks[PeerTopologyTask] = peertopology.NewTask(api, cfg.geoIPDatabases)
+		case BlockPropagationTask:
+			obs.tasks[BlockPropagationTask] = blockpropagation.NewTask(api)
+		case MempoolMessagesTask:
+			obs.tasks[MempoolMessagesTask] = mempoolmessages.NewTask(api)
+		case ConsensusFaultsTask:
+			obs.tasks[ConsensusFaultsTask] = consensusfaults.NewTask(api)
 		case MinerProtocolsTask:
 			obs.tasks[MinerProtocolsTask] = minerprotocols.NewTask(api)
+		case MinerCapabilitiesTask:
+			obs.tasks[MinerCapabilitiesTask] = minercapabilities.NewTask(api)
 		default:
 			return nil, fmt.Errorf("unknown task: %s", task)
 		}
@@ -69,6 +112,7 @@ func (s *Surveyer) Run(ctx context.Context) error {
 	// init the done channel for each run since jobs may be started and stopped.
 	s.done = make(chan struct{})
 	defer close(s.done)
+	defer s.closeTasks()
 
 	// Perform an initial tick before waiting
 	if err := s.Tick(ctx); err != nil {
@@ -90,6 +134,15 @@ func (s *Surveyer) Run(ctx context.Context) error {
 	}
 }
 
+// closeTasks closes every task of the surveyer, tasks must be able to resume if the surveyer is run again.
+func (s *Surveyer) closeTasks() {
+	for name, task := range s.tasks {
+		if err := task.Close(); err != nil {
+			log.Errorw("failed to close task", "task", name, "error", err)
+		}
+	}
+}
+
 func (s *Surveyer) Done() <-chan struct{} {
 	return s.done
 }
@@ -0,0 +1,71 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.uber.org/atomic"
+
+	"github.com/filecoin-project/lily/metrics"
+)
+
+// TaskLimits bounds the resources used by the tasks of all jobs run by a Scheduler.
+type TaskLimits struct {
+	// Concurrency is the maximum number of tasks executing at once across all jobs, zero means unlimited.
+	Concurrency int
+	// Timeout is the maximum duration of a single task, zero means tasks are never timed out.
+	Timeout time.Duration
+}
+
+// TaskBudget is a daemon-wide budget of task executions shared by all jobs of a Scheduler. Tasks must acquire a slot
+// from the budget before executing and release it once complete, tasks exceeding the timeout of the budget are
+// expected to be canceled by their job.
+type TaskBudget struct {
+	slots   chan struct{}
+	inUse   atomic.Int64
+	timeout time.Duration
+}
+
+// NewTaskBudget returns a TaskBudget enforcing `limits`.
+func NewTaskBudget(limits TaskLimits) *TaskBudget {
+	b := &TaskBudget{
+		timeout: limits.Timeout,
+	}
+	if limits.Concurrency > 0 {
+		b.slots = make(chan struct{}, limits.Concurrency)
+	}
+	return b
+}
+
+// Acquire blocks until a slot is available in the budget or `ctx` is done, in which case the context's error is
+// returned.
+func (b *TaskBudget) Acquire(ctx context.Context) error {
+	if b.slots != nil {
+		select {
+		case b.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	stats.Record(ctx, metrics.TaskBudgetInUse.M(b.inUse.Inc()))
+	return nil
+}
+
+// Release frees a slot taken by Acquire.
+func (b *TaskBudget) Release() {
+	stats.Record(context.Background(), metrics.TaskBudgetInUse.M(b.inUse.Dec()))
+	if b.slots != nil {
+		<-b.slots
+	}
+}
+
+// Timeout returns the maximum duration of a single task, zero means tasks are never timed out.
+func (b *TaskBudget) Timeout() time.Duration {
+	return b.timeout
+}
+
+// InUse returns the number of slots currently held by executing tasks.
+func (b *TaskBudget) InUse() int64 {
+	return b.inUse.Load()
+}
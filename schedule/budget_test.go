@@ -0,0 +1,38 @@
+package schedule_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/schedule"
+)
+
+func TestTaskBudget(t *testing.T) {
+	t.Run("blocks when all slots are in use", func(t *testing.T) {
+		b := schedule.NewTaskBudget(schedule.TaskLimits{Concurrency: 1})
+		require.NoError(t, b.Acquire(context.Background()))
+		assert.EqualValues(t, 1, b.InUse())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, b.Acquire(ctx), context.DeadlineExceeded)
+
+		b.Release()
+		assert.EqualValues(t, 0, b.InUse())
+		require.NoError(t, b.Acquire(context.Background()))
+		b.Release()
+	})
+
+	t.Run("unlimited when concurrency is zero", func(t *testing.T) {
+		b := schedule.NewTaskBudget(schedule.TaskLimits{Timeout: time.Minute})
+		for i := 0; i < 10; i++ {
+			require.NoError(t, b.Acquire(context.Background()))
+		}
+		assert.EqualValues(t, 10, b.InUse())
+		assert.Equal(t, time.Minute, b.Timeout())
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/filecoin-project/lily/metrics"
+	"github.com/filecoin-project/lily/model"
 	"github.com/filecoin-project/lily/storage"
 	"github.com/filecoin-project/lily/wait"
 
@@ -61,6 +62,9 @@ type JobConfig struct {
 	// Locker is an optional lock that must be taken before the job can execute.
 	Locker Locker
 
+	// Storage is the optional storage the job persists to. Storage that buffers data is flushed once the job stops.
+	Storage model.Storage
+
 	// RestartOnFailure controls whether the job should be restarted if it stops with an error.
 	RestartOnFailure bool
 
@@ -115,6 +119,8 @@ func NewScheduler(jobDelay time.Duration, scheduledJobs ...*JobConfig) *Schedule
 		workerJobComplete: make(chan struct{}),
 		workerJobsRunning: 0,
 
+		budget: NewTaskBudget(TaskLimits{}),
+
 		daemonMode: false,
 	}
 
@@ -169,11 +175,25 @@ type Scheduler struct {
 	workerJobComplete chan struct{}
 	workerJobsRunning int
 
+	// budget bounds the tasks executed by all jobs of the scheduler.
+	budget *TaskBudget
+
 	// if daemonMode is set to true the scheduler will continue to run until its context is canceled.
 	// else the scheduler will exit when all scheduled jobs are complete.
 	daemonMode bool
 }
 
+// SetTaskLimits sets the limits enforced on the tasks of all jobs run by the scheduler. It must be called before any
+// job is submitted, by default tasks are unlimited.
+func (s *Scheduler) SetTaskLimits(limits TaskLimits) {
+	s.budget = NewTaskBudget(limits)
+}
+
+// TaskBudget returns the budget tasks of jobs run by the scheduler must execute within.
+func (s *Scheduler) TaskBudget() *TaskBudget {
+	return s.budget
+}
+
 type JobSubmitResult struct {
 	ID                  JobID
 	Name                string
@@ -416,6 +436,12 @@ func (s *Scheduler) execute(jc *JobConfig, complete chan struct{}) {
 
 	// Report job is complete when this goroutine exits
 	defer func() {
+		// the job's context is done, data buffered by its storage is flushed regardless.
+		if fs, ok := jc.Storage.(storage.Flusher); ok {
+			if err := fs.Flush(context.Background()); err != nil {
+				jc.log.Errorw("failed to flush storage", "error", err.Error())
+			}
+		}
 		complete <- struct{}{}
 
 		jc.lk.Lock()
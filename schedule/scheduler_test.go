@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/fx/fxtest"
 
+	"github.com/filecoin-project/lily/model"
 	"github.com/filecoin-project/lily/schedule"
 )
 
@@ -35,6 +36,20 @@ func (r *testJob) Done() <-chan struct{} {
 	return r.done
 }
 
+// testStorage counts the times its buffered data is flushed.
+type testStorage struct {
+	flushed chan struct{}
+}
+
+func (s *testStorage) PersistBatch(context.Context, ...model.Persistable) error {
+	return nil
+}
+
+func (s *testStorage) Flush(context.Context) error {
+	s.flushed <- struct{}{}
+	return nil
+}
+
 func TestScheduler(t *testing.T) {
 	t.Run("Job Submit", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
@@ -188,6 +203,37 @@ func TestScheduler(t *testing.T) {
 
 	})
 
+	t.Run("Job stop flushes storage", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		s := schedule.NewSchedulerDaemon(ctx, fxtest.NewLifecycle(t))
+
+		strg := &testStorage{flushed: make(chan struct{}, 1)}
+		s.Submit(&schedule.JobConfig{
+			Job: newTestJob(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+			Name:    t.Name(),
+			Storage: strg,
+		})
+		// wait for job to execute
+		time.Sleep(100 * time.Millisecond)
+		select {
+		case <-strg.flushed:
+			t.Fatal("storage flushed while the job is running")
+		default:
+		}
+
+		// stopping the job flushes its storage
+		assert.NoError(t, s.StopJob(1))
+		select {
+		case <-strg.flushed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("storage was not flushed when the job stopped")
+		}
+	})
+
 	t.Run("Job restart on error success, list:running=true, stop, list:running=true", func(t *testing.T) {
 		// scheduler logs are noisy when job returns an error
 		logging.SetAllLoggers(logging.LevelFatal)
@@ -0,0 +1,43 @@
+package v1
+
+// Schema patch 41 adds surveyed miner retrieval and deal-making capabilities
+
+func init() {
+	patches.Register(
+		41,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: surveyed_miner_capabilities
+	-- Model: surveyed.MinerCapability
+	-- Growth: N/A
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.surveyed_miner_capabilities (
+		observed_at				timestamp with time zone NOT NULL,
+		miner_id				text NOT NULL,
+		peer_id					text,
+		reachable				boolean NOT NULL,
+		failure_reason			text,
+		connect_latency_ms		bigint NOT NULL,
+		transports_latency_ms	bigint NOT NULL,
+		retrieval_transports	jsonb,
+		retrieval_protocols		jsonb,
+		deal_protocols			jsonb,
+		ask_protocols			jsonb
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.surveyed_miner_capabilities ADD CONSTRAINT surveyed_miner_capabilities_pkey PRIMARY KEY (observed_at, miner_id);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.surveyed_miner_capabilities IS 'Observations of the retrieval transports and the retrieval, storage deal and storage ask protocol versions supported by Filecoin storage providers over time. The storage ask itself is not queried.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.observed_at IS 'Timestamp of the observation.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.miner_id IS 'Address (ActorID) of the miner.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.peer_id IS 'PeerID of the miner advertised in on-chain MinerInfo structure.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.reachable IS 'True if a connection to the miner could be established.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.failure_reason IS 'Reason the miner could not be surveyed, empty if the survey succeeded.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.connect_latency_ms IS 'Time taken to connect to the miner in milliseconds.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.transports_latency_ms IS 'Time taken to query the retrieval transports of the miner in milliseconds.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.retrieval_transports IS 'List of retrieval transports and their addresses served by the miner.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.retrieval_protocols IS 'List of retrieval protocols supported by the miner.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.deal_protocols IS 'List of storage deal protocols supported by the miner.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_miner_capabilities.ask_protocols IS 'List of storage ask protocol versions supported by the miner, the ask itself is not queried.';
+`)
+}
@@ -0,0 +1,43 @@
+package v1
+
+// Schema patch 42 adds surveyed peer topology
+
+func init() {
+	patches.Register(
+		42,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: surveyed_peer_topology
+	-- Model: surveyed.PeerTopology
+	-- Growth: N/A
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.surveyed_peer_topology (
+		observed_at			timestamp with time zone NOT NULL,
+		surveyer_peer_id	text NOT NULL,
+		peer_id				text NOT NULL,
+		agent				text,
+		multiaddrs			jsonb,
+		ip					text,
+		asn					bigint NOT NULL,
+		as_organization		text,
+		country				text,
+		direction			text,
+		latency_ms			bigint NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.surveyed_peer_topology ADD CONSTRAINT surveyed_peer_topology_pkey PRIMARY KEY (observed_at, surveyer_peer_id, peer_id);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.surveyed_peer_topology IS 'Observations of the addresses, location and connection of peers connected to the surveyer over time.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.observed_at IS 'Timestamp of the observation.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.surveyer_peer_id IS 'PeerID of the node performing the survey.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.peer_id IS 'PeerID of the observed peer.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.agent IS 'Agent string as reported by the peer.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.multiaddrs IS 'List of multiaddrs known for the peer.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.ip IS 'IP address the surveyer is connected to the peer on.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.asn IS 'Autonomous system number of the IP address from the configured GeoIP database, zero if unknown.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.as_organization IS 'Organization operating the autonomous system of the IP address.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.country IS 'ISO 3166-1 country code of the IP address from the configured GeoIP database.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.direction IS 'Direction of the connection to the peer: inbound, outbound or unknown.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.surveyed_peer_topology.latency_ms IS 'Moving average latency to the peer in milliseconds.';
+`)
+}
@@ -0,0 +1,39 @@
+package v1
+
+// Schema patch 43 adds surveyed block propagation
+
+func init() {
+	patches.Register(
+		43,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: block_propagation
+	-- Model: surveyed.BlockPropagation
+	-- Growth: About 5 blocks per epoch per surveyer
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.block_propagation (
+		height				bigint NOT NULL,
+		cid					text NOT NULL,
+		surveyer_peer_id	text NOT NULL,
+		miner				text NOT NULL,
+		relay_peer_id		text NOT NULL,
+		first_seen			timestamp with time zone NOT NULL,
+		epoch_start			timestamp with time zone NOT NULL,
+		offset_ms			bigint NOT NULL,
+		canonical			boolean NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.block_propagation ADD CONSTRAINT block_propagation_pkey PRIMARY KEY (height, cid, surveyer_peer_id);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.block_propagation IS 'Observations of blocks received over gossipsub, joined with their inclusion in the canonical chain once final.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.block_propagation.height IS 'Epoch of the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.block_propagation.cid IS 'CID of the block header.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.block_propagation.surveyer_peer_id IS 'PeerID of the node that observed the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.block_propagation.miner IS 'Address of the miner that produced the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.block_propagation.relay_peer_id IS 'PeerID of the peer the block was first received from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.block_propagation.first_seen IS 'Wall-clock time the block was first received.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.block_propagation.epoch_start IS 'Expected start time of the epoch of the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.block_propagation.offset_ms IS 'Milliseconds between the expected start of the epoch and the block being first received.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.block_propagation.canonical IS 'True if the block was included in the canonical chain once final, false if it was orphaned.';
+`)
+}
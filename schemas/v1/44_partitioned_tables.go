@@ -0,0 +1,27 @@
+package v1
+
+// Schema patch 44 records the tables range partitioned by height. Tables are partitioned by `lily migrate partition`
+// after the schema is migrated, partitions are created by lily ahead of the heights being indexed.
+
+func init() {
+	patches.Register(
+		44,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: visor_partitioned_tables
+	-- Model: none
+	-- Growth: N/A
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE IF NOT EXISTS {{ .SchemaName | default "public"}}.visor_partitioned_tables (
+		table_name		text NOT NULL,
+		partition_size	bigint NOT NULL,
+		PRIMARY KEY (table_name)
+	);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.visor_partitioned_tables IS 'Tables range partitioned by height, lily creates partitions of these tables ahead of the heights being indexed.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_partitioned_tables.table_name IS 'Name of the partitioned table.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.visor_partitioned_tables.partition_size IS 'Number of epochs in each partition of the table.';
+`,
+	)
+}
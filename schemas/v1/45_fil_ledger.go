@@ -0,0 +1,40 @@
+package v1
+
+// Schema patch 45 adds the fil ledger
+
+func init() {
+	patches.Register(
+		45,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: fil_ledger
+	-- Model: derived.FilLedgerEntry
+	-- Growth: About 2 rows per message transferring value or paying gas
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.fil_ledger (
+		height			bigint NOT NULL,
+		state_root		text NOT NULL,
+		index			bigint NOT NULL,
+		address			text NOT NULL,
+		counterparty	text NOT NULL,
+		amount			numeric NOT NULL,
+		reason			text NOT NULL,
+		message_cid		text NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.fil_ledger ADD CONSTRAINT fil_ledger_pkey PRIMARY KEY (height, state_root, index);
+	CREATE INDEX fil_ledger_height_idx ON {{ .SchemaName | default "public"}}.fil_ledger USING BTREE (height);
+	CREATE INDEX fil_ledger_address_idx ON {{ .SchemaName | default "public"}}.fil_ledger USING HASH (address);
+	CREATE INDEX fil_ledger_message_cid_idx ON {{ .SchemaName | default "public"}}.fil_ledger USING HASH (message_cid);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.fil_ledger IS 'Double entry ledger of every change to actor balances caused by executing the messages of a tipset. The sum of the amounts of an address at a height equals the change of its balance.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fil_ledger.height IS 'Height the messages were executed at.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fil_ledger.state_root IS 'CID of the parent state root at which the messages were executed.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fil_ledger.index IS 'Order of the entry in the state transition, each debit is immediately followed by its credit.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fil_ledger.address IS 'ID address of the actor whose balance changed.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fil_ledger.counterparty IS 'ID address of the actor on the other side of the entry.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fil_ledger.amount IS 'Amount of FIL (in attoFIL) credited to the actor, negative for debits.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fil_ledger.reason IS 'Reason the balance changed: transfer, internal_send, multisig_send, miner_reward, penalty, burn, base_fee_burn, over_estimation_burn or miner_tip.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.fil_ledger.message_cid IS 'CID of the on-chain or implicit message whose execution changed the balance.';
+`)
+}
@@ -0,0 +1,52 @@
+package v1
+
+// Schema patch 46 adds surveyed mempool messages
+
+func init() {
+	patches.Register(
+		46,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: mempool_messages
+	-- Model: surveyed.MempoolMessage
+	-- Growth: About 1 row per message sent to the network per surveyer
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.mempool_messages (
+		cid						text NOT NULL,
+		surveyer_peer_id		text NOT NULL,
+		"from"					text NOT NULL,
+		nonce					bigint NOT NULL,
+		gas_limit				bigint NOT NULL,
+		gas_fee_cap				numeric NOT NULL,
+		gas_premium				numeric NOT NULL,
+		first_seen				timestamp with time zone NOT NULL,
+		first_seen_height		bigint NOT NULL,
+		status					text NOT NULL,
+		included_cid			text,
+		inclusion_height		bigint,
+		inclusion_latency_ms	bigint,
+		inclusion_base_fee		numeric,
+		fee_cap_base_fee_ratio	double precision
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.mempool_messages ADD CONSTRAINT mempool_messages_pkey PRIMARY KEY (cid, surveyer_peer_id);
+	CREATE INDEX mempool_messages_first_seen_height_idx ON {{ .SchemaName | default "public"}}.mempool_messages USING BTREE (first_seen_height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.mempool_messages IS 'Observations of messages added to the mempool of the surveyer, joined with their inclusion in the canonical chain once final.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.cid IS 'CID of the message.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.surveyer_peer_id IS 'PeerID of the node that observed the message.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages."from" IS 'Address of the sender of the message.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.nonce IS 'Sequence number of the message.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.gas_limit IS 'Gas limit of the message.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.gas_fee_cap IS 'Maximum price of gas (in attoFIL) the sender will pay.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.gas_premium IS 'Price of gas (in attoFIL) paid to the miner including the message.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.first_seen IS 'Wall-clock time the message was first added to the mempool.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.first_seen_height IS 'Epoch the message was first added to the mempool.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.status IS 'included if the message was included in the canonical chain, replaced if a message with the same sender and nonce was included instead and dropped if neither was included within finality.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.included_cid IS 'CID of the message included with the sender and nonce of the message, null if dropped.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.inclusion_height IS 'Epoch of the tipset the message, or its replacement, was included in, null if dropped.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.inclusion_latency_ms IS 'Milliseconds between the message being first seen and the start of the inclusion epoch, null if dropped.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.inclusion_base_fee IS 'Base fee (in attoFIL) paid by the messages of the inclusion tipset, null if dropped.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.mempool_messages.fee_cap_base_fee_ratio IS 'Gas fee cap of the message divided by the base fee of the inclusion tipset, null if dropped.';
+`)
+}
@@ -0,0 +1,45 @@
+package v1
+
+// Schema patch 47 adds per block miner rewards
+
+func init() {
+	patches.Register(
+		47,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: miner_block_rewards
+	-- Model: derived.MinerBlockReward
+	-- Growth: About 5 rows per epoch
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.miner_block_rewards (
+		height			bigint NOT NULL,
+		block_cid		text NOT NULL,
+		state_root		text NOT NULL,
+		miner			text NOT NULL,
+		win_count		bigint NOT NULL,
+		gross_reward	numeric NOT NULL,
+		block_reward	numeric NOT NULL,
+		tips			numeric NOT NULL,
+		penalty			numeric NOT NULL,
+		locked_reward	numeric NOT NULL,
+		vested_reward	numeric NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.miner_block_rewards ADD CONSTRAINT miner_block_rewards_pkey PRIMARY KEY (height, block_cid);
+	CREATE INDEX miner_block_rewards_height_idx ON {{ .SchemaName | default "public"}}.miner_block_rewards USING BTREE (height);
+	CREATE INDEX miner_block_rewards_miner_idx ON {{ .SchemaName | default "public"}}.miner_block_rewards USING HASH (miner);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.miner_block_rewards IS 'Reward paid to the miner of each block when the tipset containing the block is executed.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.height IS 'Height of the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.block_cid IS 'CID of the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.state_root IS 'CID of the parent state root the reward was applied to.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.miner IS 'ID address of the miner of the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.win_count IS 'Number of reward units won by the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.gross_reward IS 'Reward (in attoFIL) paid to the miner, the sum of the block reward and the tips.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.block_reward IS 'Reward (in attoFIL) paid to the miner by the reward actor for winning the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.tips IS 'Miner tips (in attoFIL) of the messages first included in the block, the sum of their miner_tip in derived_gas_outputs.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.penalty IS 'Penalty (in attoFIL) charged to the miner for including invalid messages in the block.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.locked_reward IS 'Part of the gross reward (in attoFIL) locked in the vesting funds of the miner, 75% of the gross reward since network version 6, the whole gross reward before it.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.miner_block_rewards.vested_reward IS 'Part of the gross reward (in attoFIL) available to the miner immediately.';
+`)
+}
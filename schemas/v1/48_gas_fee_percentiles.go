@@ -0,0 +1,60 @@
+package v1
+
+// Schema patch 48 adds gas fee percentiles
+
+func init() {
+	patches.Register(
+		48,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: gas_fee_percentiles
+	-- Model: derived.GasFeePercentiles
+	-- Growth: About 20 rows per epoch
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.gas_fee_percentiles (
+		height					bigint NOT NULL,
+		state_root				text NOT NULL,
+		actor_family			text NOT NULL,
+		method					bigint NOT NULL,
+		base_fee				numeric NOT NULL,
+		message_count			bigint NOT NULL,
+		below_base_fee_count	bigint NOT NULL,
+		effective_gas_price_p10	numeric NOT NULL,
+		effective_gas_price_p50	numeric NOT NULL,
+		effective_gas_price_p90	numeric NOT NULL,
+		effective_gas_price_p99	numeric NOT NULL,
+		gas_premium_p10			numeric NOT NULL,
+		gas_premium_p50			numeric NOT NULL,
+		gas_premium_p90			numeric NOT NULL,
+		gas_premium_p99			numeric NOT NULL,
+		gas_fee_cap_p10			numeric NOT NULL,
+		gas_fee_cap_p50			numeric NOT NULL,
+		gas_fee_cap_p90			numeric NOT NULL,
+		gas_fee_cap_p99			numeric NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.gas_fee_percentiles ADD CONSTRAINT gas_fee_percentiles_pkey PRIMARY KEY (height, state_root, actor_family, method);
+	CREATE INDEX gas_fee_percentiles_height_idx ON {{ .SchemaName | default "public"}}.gas_fee_percentiles USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.gas_fee_percentiles IS 'Percentiles of the gas prices of the messages executed in each tipset, by actor family and method of the receiver, and for every message of the tipset.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.height IS 'Height the messages were executed at.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.state_root IS 'CID of the parent state root at which the messages were executed.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.actor_family IS 'Actor family of the receivers of the messages, all for every message of the tipset.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.method IS 'Method called by the messages, -1 for every method.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.base_fee IS 'Base fee (in attoFIL per unit gas) paid by the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.message_count IS 'Number of messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.below_base_fee_count IS 'Number of messages with a gas fee cap below the base fee.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.effective_gas_price_p10 IS '10th percentile of the effective gas price (in attoFIL per unit gas), the lesser of the gas fee cap and the base fee plus gas premium of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.effective_gas_price_p50 IS '50th percentile of the effective gas price (in attoFIL per unit gas), the lesser of the gas fee cap and the base fee plus gas premium of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.effective_gas_price_p90 IS '90th percentile of the effective gas price (in attoFIL per unit gas), the lesser of the gas fee cap and the base fee plus gas premium of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.effective_gas_price_p99 IS '99th percentile of the effective gas price (in attoFIL per unit gas), the lesser of the gas fee cap and the base fee plus gas premium of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.gas_premium_p10 IS '10th percentile of the gas premium (in attoFIL per unit gas) of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.gas_premium_p50 IS '50th percentile of the gas premium (in attoFIL per unit gas) of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.gas_premium_p90 IS '90th percentile of the gas premium (in attoFIL per unit gas) of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.gas_premium_p99 IS '99th percentile of the gas premium (in attoFIL per unit gas) of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.gas_fee_cap_p10 IS '10th percentile of the gas fee cap (in attoFIL per unit gas) of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.gas_fee_cap_p50 IS '50th percentile of the gas fee cap (in attoFIL per unit gas) of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.gas_fee_cap_p90 IS '90th percentile of the gas fee cap (in attoFIL per unit gas) of the messages.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.gas_fee_percentiles.gas_fee_cap_p99 IS '99th percentile of the gas fee cap (in attoFIL per unit gas) of the messages.';
+`)
+}
@@ -0,0 +1,150 @@
+package v1
+
+// Schema patch 49 adds periodic actor dumps of the market, power, multisig, verified registry and datacap actors
+
+func init() {
+	patches.Register(
+		49,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: market_deal_dumps
+	-- Model: actordumps.MarketDealDump
+	-- Growth: One row per deal per dump
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.market_deal_dumps (
+		height					bigint NOT NULL,
+		deal_id					bigint NOT NULL,
+		state_root				text NOT NULL,
+		piece_cid				text NOT NULL,
+		padded_piece_size		bigint NOT NULL,
+		unpadded_piece_size		bigint NOT NULL,
+		is_verified				boolean NOT NULL,
+		client_id				text NOT NULL,
+		provider_id				text NOT NULL,
+		start_epoch				bigint NOT NULL,
+		end_epoch				bigint NOT NULL,
+		storage_price_per_epoch	numeric NOT NULL,
+		provider_collateral		numeric NOT NULL,
+		client_collateral		numeric NOT NULL,
+		sector_id				bigint NOT NULL,
+		sector_start_epoch		bigint NOT NULL,
+		last_update_epoch		bigint NOT NULL,
+		slash_epoch				bigint NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.market_deal_dumps ADD CONSTRAINT market_deal_dumps_pkey PRIMARY KEY (height, deal_id);
+	CREATE INDEX market_deal_dumps_height_idx ON {{ .SchemaName | default "public"}}.market_deal_dumps USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.market_deal_dumps IS 'Periodic snapshot of every deal proposal and deal state in the storage market actor.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.market_deal_dumps.height IS 'Epoch at which the snapshot was taken.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.market_deal_dumps.deal_id IS 'Identifier of the deal.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.market_deal_dumps.state_root IS 'CID of the parent state root the snapshot was taken from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.market_deal_dumps.sector_id IS 'Sector the deal is stored in, 0 if the deal is not activated or the network version does not record it.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.market_deal_dumps.sector_start_epoch IS 'Epoch the deal was activated at, -1 if not yet activated.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.market_deal_dumps.last_update_epoch IS 'Epoch the deal was last updated at, -1 if never updated.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.market_deal_dumps.slash_epoch IS 'Epoch the deal was slashed at, -1 if never slashed.';
+
+	-- ----------------------------------------------------------------
+	-- Name: power_claim_dumps
+	-- Model: actordumps.PowerClaimDump
+	-- Growth: One row per miner with a claim per dump
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.power_claim_dumps (
+		height				bigint NOT NULL,
+		miner_id			text NOT NULL,
+		state_root			text NOT NULL,
+		raw_byte_power		numeric NOT NULL,
+		quality_adj_power	numeric NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.power_claim_dumps ADD CONSTRAINT power_claim_dumps_pkey PRIMARY KEY (height, miner_id);
+	CREATE INDEX power_claim_dumps_height_idx ON {{ .SchemaName | default "public"}}.power_claim_dumps USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.power_claim_dumps IS 'Periodic snapshot of every miner power claim in the storage power actor.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.power_claim_dumps.height IS 'Epoch at which the snapshot was taken.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.power_claim_dumps.miner_id IS 'ID address of the miner.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.power_claim_dumps.state_root IS 'CID of the parent state root the snapshot was taken from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.power_claim_dumps.raw_byte_power IS 'Raw byte power of the miner in bytes.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.power_claim_dumps.quality_adj_power IS 'Quality adjusted power of the miner in bytes.';
+
+	-- ----------------------------------------------------------------
+	-- Name: multisig_actor_dumps
+	-- Model: actordumps.MultisigActorDump
+	-- Growth: One row per multisig actor per dump
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.multisig_actor_dumps (
+		height					bigint NOT NULL,
+		multisig_id				text NOT NULL,
+		state_root				text NOT NULL,
+		balance					numeric NOT NULL,
+		signers					jsonb,
+		threshold				bigint NOT NULL,
+		initial_balance			numeric NOT NULL,
+		locked_balance			numeric NOT NULL,
+		start_epoch				bigint NOT NULL,
+		unlock_duration			bigint NOT NULL,
+		pending_transactions	bigint NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.multisig_actor_dumps ADD CONSTRAINT multisig_actor_dumps_pkey PRIMARY KEY (height, multisig_id);
+	CREATE INDEX multisig_actor_dumps_height_idx ON {{ .SchemaName | default "public"}}.multisig_actor_dumps USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.multisig_actor_dumps IS 'Periodic snapshot of the state of every multisig actor.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.height IS 'Epoch at which the snapshot was taken.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.multisig_id IS 'ID address of the multisig actor.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.state_root IS 'CID of the parent state root the snapshot was taken from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.balance IS 'Balance of the multisig in attoFIL.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.signers IS 'JSON array of the ID addresses of the signers.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.threshold IS 'Number of signers required to approve a transaction.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.initial_balance IS 'Balance subject to vesting in attoFIL.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.locked_balance IS 'Balance still locked by vesting at the height of the snapshot in attoFIL.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.start_epoch IS 'Epoch vesting started at.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.unlock_duration IS 'Number of epochs the initial balance vests over.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_actor_dumps.pending_transactions IS 'Number of transactions awaiting approval.';
+
+	-- ----------------------------------------------------------------
+	-- Name: verified_registry_dumps
+	-- Model: actordumps.VerifiedRegistryDump
+	-- Growth: One row per verifier and verified client per dump
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.verified_registry_dumps (
+		height		bigint NOT NULL,
+		address		text NOT NULL,
+		kind		text NOT NULL,
+		state_root	text NOT NULL,
+		data_cap	numeric NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.verified_registry_dumps ADD CONSTRAINT verified_registry_dumps_pkey PRIMARY KEY (height, address, kind);
+	CREATE INDEX verified_registry_dumps_height_idx ON {{ .SchemaName | default "public"}}.verified_registry_dumps USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.verified_registry_dumps IS 'Periodic snapshot of the verifiers and, before actors v9, the verified clients in the verified registry actor.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.verified_registry_dumps.height IS 'Epoch at which the snapshot was taken.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.verified_registry_dumps.address IS 'ID address of the verifier or client.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.verified_registry_dumps.kind IS 'Role of the address, either verifier or client.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.verified_registry_dumps.state_root IS 'CID of the parent state root the snapshot was taken from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.verified_registry_dumps.data_cap IS 'DataCap allowance of the verifier or client in bytes.';
+
+	-- ----------------------------------------------------------------
+	-- Name: data_cap_balance_dumps
+	-- Model: actordumps.DataCapBalanceDump
+	-- Growth: One row per DataCap holder per dump
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.data_cap_balance_dumps (
+		height		bigint NOT NULL,
+		address		text NOT NULL,
+		state_root	text NOT NULL,
+		data_cap	numeric NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.data_cap_balance_dumps ADD CONSTRAINT data_cap_balance_dumps_pkey PRIMARY KEY (height, address);
+	CREATE INDEX data_cap_balance_dumps_height_idx ON {{ .SchemaName | default "public"}}.data_cap_balance_dumps USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.data_cap_balance_dumps IS 'Periodic snapshot of every DataCap balance in the datacap actor.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.data_cap_balance_dumps.height IS 'Epoch at which the snapshot was taken.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.data_cap_balance_dumps.address IS 'ID address of the DataCap holder.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.data_cap_balance_dumps.state_root IS 'CID of the parent state root the snapshot was taken from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.data_cap_balance_dumps.data_cap IS 'DataCap balance in bytes.';
+`,
+	)
+}
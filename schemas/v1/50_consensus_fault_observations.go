@@ -0,0 +1,54 @@
+package v1
+
+// Schema patch 50 adds surveyed consensus fault observations
+
+func init() {
+	patches.Register(
+		50,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: consensus_fault_observations
+	-- Model: surveyed.ConsensusFaultObservation
+	-- Growth: About 1 row per consensus fault per surveyer
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.consensus_fault_observations (
+		height				bigint NOT NULL,
+		block1_cid			text NOT NULL,
+		block2_cid			text NOT NULL,
+		source				text NOT NULL,
+		surveyer_peer_id	text NOT NULL,
+		fault_type			text NOT NULL,
+		miner				text NOT NULL,
+		block2_height		bigint NOT NULL,
+		block_extra_cid		text,
+		block1_canonical	boolean NOT NULL,
+		block2_canonical	boolean NOT NULL,
+		report_message_cid	text,
+		report_height		bigint,
+		reporter			text,
+		observed_at			timestamp with time zone NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.consensus_fault_observations ADD CONSTRAINT consensus_fault_observations_pkey PRIMARY KEY (height, block1_cid, block2_cid, source, surveyer_peer_id);
+	CREATE INDEX consensus_fault_observations_height_idx ON {{ .SchemaName | default "public"}}.consensus_fault_observations USING BTREE (height);
+	CREATE INDEX consensus_fault_observations_miner_idx ON {{ .SchemaName | default "public"}}.consensus_fault_observations USING HASH (miner);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.consensus_fault_observations IS 'Consensus faults detected from the block headers observed by the surveyer, including headers that were never synced, and consensus faults reported to miner actors by messages in the canonical chain.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.height IS 'Epoch of the first block of the fault.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.block1_cid IS 'CID of the first block of the fault.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.block2_cid IS 'CID of the second block of the fault.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.source IS 'Either observed, for faults detected from the block headers observed by the surveyer, or reported, for faults reported by a ReportConsensusFault message.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.surveyer_peer_id IS 'PeerID of the node that observed the fault.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.fault_type IS 'Type of the fault, one of double_fork, time_offset or parent_grinding.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.miner IS 'Address of the miner that mined both blocks.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.block2_height IS 'Epoch of the second block of the fault.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.block_extra_cid IS 'CID of the block the second block was mined on instead of the first, null unless the fault is parent grinding.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.block1_canonical IS 'True if the first block was included in the canonical chain once final.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.block2_canonical IS 'True if the second block was included in the canonical chain once final.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.report_message_cid IS 'CID of the ReportConsensusFault message, null unless the fault was reported.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.report_height IS 'Epoch the ReportConsensusFault message was included at, null unless the fault was reported.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.reporter IS 'Address of the sender of the ReportConsensusFault message, null unless the fault was reported.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.consensus_fault_observations.observed_at IS 'Time the fault was detected by the surveyer.';
+`,
+	)
+}
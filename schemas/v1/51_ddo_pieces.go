@@ -0,0 +1,50 @@
+package v1
+
+// Schema patch 51 adds direct data onboarding pieces
+
+func init() {
+	patches.Register(
+		51,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: ddo_pieces
+	-- Model: builtinactor.DDOPiece
+	-- Growth: About 1 row per piece onboarded into a sector
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.ddo_pieces (
+		height			bigint NOT NULL,
+		miner			text NOT NULL,
+		sector_id		bigint NOT NULL,
+		piece_cid		text NOT NULL,
+		state_root		text NOT NULL,
+		message_cid		text NOT NULL,
+		event_type		text NOT NULL,
+		piece_size		bigint NOT NULL,
+		unsealed_cid	text,
+		verified		boolean NOT NULL,
+		claim_id		bigint,
+		deal_id			bigint,
+		client			text
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.ddo_pieces ADD CONSTRAINT ddo_pieces_pkey PRIMARY KEY (height, miner, sector_id, piece_cid);
+	CREATE INDEX ddo_pieces_height_idx ON {{ .SchemaName | default "public"}}.ddo_pieces USING BTREE (height);
+	CREATE INDEX ddo_pieces_piece_cid_idx ON {{ .SchemaName | default "public"}}.ddo_pieces USING HASH (piece_cid);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.ddo_pieces IS 'Pieces onboarded into sectors, decoded from the piece lists of sector-activated and sector-updated events and matched with their verified registry claim and market deal.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.height IS 'Epoch the piece was activated at.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.miner IS 'ID address of the miner the sector belongs to.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.sector_id IS 'Number of the sector the piece was activated in.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.piece_cid IS 'CID of the piece.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.state_root IS 'CID of the parent state root the activating message was applied to.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.message_cid IS 'CID of the message that activated the piece.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.event_type IS 'Either sector-activated for new sectors or sector-updated for snapped sectors.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.piece_size IS 'Padded size of the piece in bytes.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.unsealed_cid IS 'CID of the unsealed data of the sector, null for sectors without data.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.verified IS 'True when the piece is covered by a verified registry claim or a verified deal.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.claim_id IS 'Identifier of the verified registry claim for the piece, null if the piece is not claimed.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.deal_id IS 'Identifier of the market deal for the piece, null if the piece was onboarded without a deal.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.ddo_pieces.client IS 'ID address of the client of the claim or deal, null if the piece has neither.';
+`,
+	)
+}
@@ -0,0 +1,177 @@
+package v1
+
+// Schema patch 52 adds tables for builtin actor events decoded into typed columns
+
+func init() {
+	patches.Register(
+		52,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: builtin_verifier_balance_events
+	-- Model: builtinactor.VerifierBalanceEvent
+	-- Growth: About 1 row per change of the datacap allowance of a verifier
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.builtin_verifier_balance_events (
+		height			bigint NOT NULL,
+		message_cid		text NOT NULL,
+		event_idx		bigint NOT NULL,
+		emitter			text NOT NULL,
+		event_type		text NOT NULL,
+		verifier		text NOT NULL,
+		balance			numeric NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.builtin_verifier_balance_events ADD CONSTRAINT builtin_verifier_balance_events_pkey PRIMARY KEY (height, message_cid, event_idx);
+	CREATE INDEX builtin_verifier_balance_events_height_idx ON {{ .SchemaName | default "public"}}.builtin_verifier_balance_events USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.builtin_verifier_balance_events IS 'Verifier-balance events emitted by the verified registry actor when the datacap allowance of a verifier changes.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_verifier_balance_events.height IS 'Height of the tipset whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_verifier_balance_events.message_cid IS 'CID of the message whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_verifier_balance_events.event_idx IS 'Index of the event among the events emitted when executing the tipset.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_verifier_balance_events.emitter IS 'Address of the actor that emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_verifier_balance_events.event_type IS 'Type of the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_verifier_balance_events.verifier IS 'ID address of the verifier.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_verifier_balance_events.balance IS 'Datacap allowance of the verifier after the change.';
+
+	-- ----------------------------------------------------------------
+	-- Name: builtin_allocation_events
+	-- Model: builtinactor.AllocationEvent
+	-- Growth: About 1 row per verified registry allocation made or removed
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.builtin_allocation_events (
+		height			bigint NOT NULL,
+		message_cid		text NOT NULL,
+		event_idx		bigint NOT NULL,
+		emitter			text NOT NULL,
+		event_type		text NOT NULL,
+		allocation_id		bigint NOT NULL,
+		client			text NOT NULL,
+		provider		text NOT NULL,
+		piece_cid		text,
+		piece_size		bigint NOT NULL,
+		term_min		bigint NOT NULL,
+		term_max		bigint NOT NULL,
+		expiration		bigint NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.builtin_allocation_events ADD CONSTRAINT builtin_allocation_events_pkey PRIMARY KEY (height, message_cid, event_idx);
+	CREATE INDEX builtin_allocation_events_height_idx ON {{ .SchemaName | default "public"}}.builtin_allocation_events USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.builtin_allocation_events IS 'Allocation and allocation-removed events emitted by the verified registry actor when datacap is allocated to a piece or an expired allocation is removed.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.height IS 'Height of the tipset whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.message_cid IS 'CID of the message whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.event_idx IS 'Index of the event among the events emitted when executing the tipset.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.emitter IS 'Address of the actor that emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.event_type IS 'Type of the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.allocation_id IS 'Identifier of the allocation.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.client IS 'ID address of the client the datacap is allocated from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.provider IS 'ID address of the provider the piece is allocated to.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.piece_cid IS 'CID of the allocated piece.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.piece_size IS 'Padded size of the allocated piece in bytes.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.term_min IS 'Minimum duration in epochs the provider must commit to store the piece for.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.term_max IS 'Maximum duration in epochs the client may extend the claim of the piece to.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_allocation_events.expiration IS 'Epoch by which the provider must claim the allocation.';
+
+	-- ----------------------------------------------------------------
+	-- Name: builtin_claim_events
+	-- Model: builtinactor.ClaimEvent
+	-- Growth: About 1 row per verified registry claim made, extended or removed
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.builtin_claim_events (
+		height			bigint NOT NULL,
+		message_cid		text NOT NULL,
+		event_idx		bigint NOT NULL,
+		emitter			text NOT NULL,
+		event_type		text NOT NULL,
+		claim_id		bigint NOT NULL,
+		client			text NOT NULL,
+		provider		text NOT NULL,
+		piece_cid		text,
+		piece_size		bigint NOT NULL,
+		term_min		bigint NOT NULL,
+		term_max		bigint NOT NULL,
+		term_start		bigint NOT NULL,
+		sector			bigint NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.builtin_claim_events ADD CONSTRAINT builtin_claim_events_pkey PRIMARY KEY (height, message_cid, event_idx);
+	CREATE INDEX builtin_claim_events_height_idx ON {{ .SchemaName | default "public"}}.builtin_claim_events USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.builtin_claim_events IS 'Claim, claim-updated and claim-removed events emitted by the verified registry actor.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.height IS 'Height of the tipset whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.message_cid IS 'CID of the message whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.event_idx IS 'Index of the event among the events emitted when executing the tipset.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.emitter IS 'Address of the actor that emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.event_type IS 'Type of the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.claim_id IS 'Identifier of the claim.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.client IS 'ID address of the client the datacap of the claim was allocated from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.provider IS 'ID address of the provider storing the claimed piece.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.piece_cid IS 'CID of the claimed piece.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.piece_size IS 'Padded size of the claimed piece in bytes.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.term_min IS 'Minimum duration in epochs the provider committed to store the piece for.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.term_max IS 'Maximum duration in epochs the client may extend the claim to.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.term_start IS 'Epoch the claim started at.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_claim_events.sector IS 'Number of the sector holding the claimed piece.';
+
+	-- ----------------------------------------------------------------
+	-- Name: builtin_deal_events
+	-- Model: builtinactor.DealEvent
+	-- Growth: About 1 row per storage market deal state change
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.builtin_deal_events (
+		height			bigint NOT NULL,
+		message_cid		text NOT NULL,
+		event_idx		bigint NOT NULL,
+		emitter			text NOT NULL,
+		event_type		text NOT NULL,
+		deal_id		bigint NOT NULL,
+		client			text NOT NULL,
+		provider		text NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.builtin_deal_events ADD CONSTRAINT builtin_deal_events_pkey PRIMARY KEY (height, message_cid, event_idx);
+	CREATE INDEX builtin_deal_events_height_idx ON {{ .SchemaName | default "public"}}.builtin_deal_events USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.builtin_deal_events IS 'Deal-published, deal-activated, deal-terminated and deal-completed events emitted by the storage market actor.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_deal_events.height IS 'Height of the tipset whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_deal_events.message_cid IS 'CID of the message whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_deal_events.event_idx IS 'Index of the event among the events emitted when executing the tipset.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_deal_events.emitter IS 'Address of the actor that emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_deal_events.event_type IS 'Type of the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_deal_events.deal_id IS 'Identifier of the deal.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_deal_events.client IS 'ID address of the client of the deal.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_deal_events.provider IS 'ID address of the provider of the deal.';
+
+	-- ----------------------------------------------------------------
+	-- Name: builtin_sector_events
+	-- Model: builtinactor.SectorEvent
+	-- Growth: About 1 row per sector precommitted, activated, updated or terminated
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.builtin_sector_events (
+		height			bigint NOT NULL,
+		message_cid		text NOT NULL,
+		event_idx		bigint NOT NULL,
+		emitter			text NOT NULL,
+		event_type		text NOT NULL,
+		sector			bigint NOT NULL,
+		unsealed_cid	text,
+		piece_cids		text[],
+		piece_sizes		bigint[]
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.builtin_sector_events ADD CONSTRAINT builtin_sector_events_pkey PRIMARY KEY (height, message_cid, event_idx);
+	CREATE INDEX builtin_sector_events_height_idx ON {{ .SchemaName | default "public"}}.builtin_sector_events USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.builtin_sector_events IS 'Sector-precommitted, sector-activated, sector-updated and sector-terminated events emitted by miner actors.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_sector_events.height IS 'Height of the tipset whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_sector_events.message_cid IS 'CID of the message whose execution emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_sector_events.event_idx IS 'Index of the event among the events emitted when executing the tipset.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_sector_events.emitter IS 'Address of the actor that emitted the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_sector_events.event_type IS 'Type of the event.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_sector_events.sector IS 'Number of the sector.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_sector_events.unsealed_cid IS 'CID of the unsealed data of an activated or updated sector, null for sectors without data.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_sector_events.piece_cids IS 'CIDs of the pieces of an activated or updated sector.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.builtin_sector_events.piece_sizes IS 'Padded sizes in bytes of the pieces of an activated or updated sector.';
+`,
+	)
+}
@@ -0,0 +1,47 @@
+package v1
+
+// Schema patch 53 adds chain economics projections derived from the reward and power actor states
+
+func init() {
+	patches.Register(
+		53,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: chain_economics_projections
+	-- Model: chain.ChainEconomicsProjection
+	-- Growth: One row per tipset
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.chain_economics_projections (
+		height					bigint NOT NULL,
+		state_root				text NOT NULL,
+		baseline_power			numeric NOT NULL,
+		network_raw_power		numeric NOT NULL,
+		network_qa_power		numeric NOT NULL,
+		baseline_crossed		boolean NOT NULL,
+		effective_network_time	bigint NOT NULL,
+		simple_minted			numeric NOT NULL,
+		baseline_minted			numeric NOT NULL,
+		simple_reward			numeric NOT NULL,
+		baseline_reward			numeric NOT NULL,
+		sector_initial_pledge	numeric NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.chain_economics_projections ADD CONSTRAINT chain_economics_projections_pkey PRIMARY KEY (height, state_root);
+	CREATE INDEX chain_economics_projections_height_idx ON {{ .SchemaName | default "public"}}.chain_economics_projections USING BTREE (height);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.chain_economics_projections IS 'Figures derived from the reward and power actor states: network power against the baseline, storage mining minted by the simple and baseline schedules and the initial pledge of a new sector.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.height IS 'Epoch of the tipset the figures are derived from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.state_root IS 'CID of the parent state root the figures are derived from.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.baseline_power IS 'Baseline power of the epoch in bytes.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.network_raw_power IS 'Raw byte power of the network in bytes.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.network_qa_power IS 'Quality adjusted power of the network in bytes.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.baseline_crossed IS 'True when the raw byte power of the network, which the reward actor compares to the baseline, is at or above the baseline power.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.effective_network_time IS 'Epoch at which the cumulative baseline power reaches the cumulative realized power.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.simple_minted IS 'AttoFIL minted by the simple schedule, which only depends on time.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.baseline_minted IS 'AttoFIL minted by the baseline schedule, which depends on the effective network time.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.simple_reward IS 'AttoFIL of the reward of the epoch minted by the simple schedule.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.baseline_reward IS 'AttoFIL of the reward of the epoch minted by the baseline schedule.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.chain_economics_projections.sector_initial_pledge IS 'Initial pledge in attoFIL of a 32GiB sector without deals.';
+`,
+	)
+}
@@ -0,0 +1,46 @@
+package v1
+
+// Schema patch 54 adds the history of the configuration and vesting schedule of multisig actors
+
+func init() {
+	patches.Register(
+		54,
+		`
+	-- ----------------------------------------------------------------
+	-- Name: multisig_info
+	-- Model: multisig.MultisigInfo
+	-- Growth: About 1 row per multisig created or reconfigured
+	-- ----------------------------------------------------------------
+
+	CREATE TABLE {{ .SchemaName | default "public"}}.multisig_info (
+		height				bigint NOT NULL,
+		multisig_id			text NOT NULL,
+		state_root			text NOT NULL,
+		signers				jsonb NOT NULL,
+		threshold			bigint NOT NULL,
+		start_epoch			bigint NOT NULL,
+		unlock_duration		bigint NOT NULL,
+		initial_balance		numeric NOT NULL,
+		balance				numeric NOT NULL,
+		locked_balance		numeric NOT NULL,
+		unlocked_balance	numeric NOT NULL
+	);
+	ALTER TABLE ONLY {{ .SchemaName | default "public"}}.multisig_info ADD CONSTRAINT multisig_info_pkey PRIMARY KEY (height, multisig_id, state_root);
+	CREATE INDEX multisig_info_height_idx ON {{ .SchemaName | default "public"}}.multisig_info USING BTREE (height);
+	CREATE INDEX multisig_info_multisig_id_idx ON {{ .SchemaName | default "public"}}.multisig_info USING HASH (multisig_id);
+
+	COMMENT ON TABLE {{ .SchemaName | default "public"}}.multisig_info IS 'Configuration of multisig actors, recorded when a multisig is created and whenever its signers, threshold or vesting parameters change, with its locked and unlocked balance at that time.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.height IS 'Epoch the configuration was observed at.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.multisig_id IS 'ID address of the multisig actor.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.state_root IS 'CID of the parent state root the configuration was observed in.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.signers IS 'JSON array of the addresses of the signers of the multisig.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.threshold IS 'Number of signers required to approve a transaction.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.start_epoch IS 'Epoch the vesting of the initial balance started at.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.unlock_duration IS 'Number of epochs over which the initial balance vests.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.initial_balance IS 'AttoFIL locked at the start of the vesting.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.balance IS 'Balance of the multisig in attoFIL.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.locked_balance IS 'Part of the initial balance in attoFIL that has not vested yet.';
+	COMMENT ON COLUMN {{ .SchemaName | default "public"}}.multisig_info.unlocked_balance IS 'Part of the balance in attoFIL that may be spent.';
+`,
+	)
+}
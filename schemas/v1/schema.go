@@ -41,6 +41,11 @@ func Version() model.Version {
 	}
 }
 
+// GetPatchSQL returns the SQL executed by patch `seq` when migrating up to it.
+func GetPatchSQL(cfg schemas.Config, seq int) (string, error) {
+	return patches.SQL(cfg, seq)
+}
+
 var patches = NewPatchList()
 
 type patch struct {
@@ -77,6 +82,20 @@ func (pl *patchList) Register(seq int, text string) {
 	}
 }
 
+// SQL renders the SQL of patch `seq` for the given config.
+func (pl *patchList) SQL(cfg schemas.Config, seq int) (string, error) {
+	p, exists := pl.pm[seq]
+	if !exists {
+		return "", fmt.Errorf("missing patch %d", seq)
+	}
+
+	var buf strings.Builder
+	if err := p.tmpl.Execute(&buf, cfg); err != nil {
+		return "", fmt.Errorf("execute patch template: %w", err)
+	}
+	return buf.String(), nil
+}
+
 func (pl *patchList) Collection(cfg schemas.Config) (*migrations.Collection, error) {
 	// Check patch list is consistent with no gaps
 	count := len(pl.pm)
@@ -95,13 +114,10 @@ func (pl *patchList) Collection(cfg schemas.Config) (*migrations.Collection, err
 
 	migs := make([]*migrations.Migration, 0, count)
 	for i := 1; i <= count; i++ {
-		p := pl.pm[i]
-
-		var buf strings.Builder
-		if err := p.tmpl.Execute(&buf, cfg); err != nil {
-			return nil, fmt.Errorf("execute patch template: %w", err)
+		sql, err := pl.SQL(cfg, i)
+		if err != nil {
+			return nil, err
 		}
-		sql := buf.String()
 
 		migs = append(migs, &migrations.Migration{
 			Version: int64(i),
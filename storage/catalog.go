@@ -15,6 +15,11 @@ type Connector interface {
 	Close(context.Context) error
 }
 
+// A Flusher buffers data before persisting it. Flush persists the data that is still buffered.
+type Flusher interface {
+	Flush(context.Context) error
+}
+
 func NewCatalog(cfg config.StorageConf) (*Catalog, error) {
 	c := &Catalog{
 		storages: make(map[string]model.Storage),
@@ -39,6 +44,18 @@ func NewCatalog(cfg config.StorageConf) (*Catalog, error) {
 			return nil, fmt.Errorf("failed to create postgresql storage %q: %w", name, err)
 		}
 
+		db.BulkCopy = sc.BulkCopy
+
+		if err := db.SetPartitioning(PartitionConfig{
+			Ahead: sc.Partitioning.Ahead,
+		}); err != nil {
+			return nil, fmt.Errorf("invalid partitioning of postgresql storage %q: %w", name, err)
+		}
+
+		if err := db.SetRetention(sc.Retention); err != nil {
+			return nil, fmt.Errorf("invalid retention of postgresql storage %q: %w", name, err)
+		}
+
 		c.storages[name] = db
 	}
 
@@ -49,6 +66,22 @@ func NewCatalog(cfg config.StorageConf) (*Catalog, error) {
 
 		switch sc.Format {
 		case "CSV":
+			if IsS3URL(sc.Path) {
+				log.Debugw("registering storage", "name", name, "type", "s3csv")
+
+				db, err := NewS3CSVStorageLatest(sc.Path, S3CSVStorageOptions{
+					OmitHeader:  sc.OmitHeader,
+					FilePattern: sc.FilePattern,
+					HeightRange: sc.HeightRange,
+					FlushAfter:  sc.FlushAfter,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to create s3 storage %q: %w", name, err)
+				}
+				c.storages[name] = db
+				continue
+			}
+
 			log.Debugw("registering storage", "name", name, "type", "csv")
 
 			opts := DefaultCSVStorageOptions()
@@ -67,6 +100,39 @@ func NewCatalog(cfg config.StorageConf) (*Catalog, error) {
 
 	}
 
+	// composite storages are registered last since their children are the storages registered above.
+	composites := map[string]model.Storage{}
+	for name, sc := range cfg.Composite {
+		if _, exists := c.storages[name]; exists {
+			return nil, fmt.Errorf("duplicate storage name: %q", name)
+		}
+		log.Debugw("registering storage", "name", name, "type", "composite")
+
+		children := make([]*CompositeChild, 0, len(sc.Children))
+		for _, cc := range sc.Children {
+			child, exists := c.storages[cc.Storage]
+			if !exists {
+				return nil, fmt.Errorf("unknown child storage %q of composite storage %q", cc.Storage, name)
+			}
+			children = append(children, &CompositeChild{
+				Name:          cc.Storage,
+				Storage:       child,
+				BestEffort:    cc.BestEffort,
+				Tables:        cc.Tables,
+				ExcludeTables: cc.ExcludeTables,
+			})
+		}
+
+		cs, err := NewCompositeStorage(children...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create composite storage %q: %w", name, err)
+		}
+		composites[name] = cs
+	}
+	for name, cs := range composites {
+		c.storages[name] = cs
+	}
+
 	return c, nil
 }
 
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/go-pg/pg/v10/orm"
+
+	"github.com/filecoin-project/lily/model"
+)
+
+// A CompositeChild is a storage that a CompositeStorage persists data to.
+type CompositeChild struct {
+	Name    string
+	Storage model.Storage
+	// BestEffort is true if failing to persist to the storage is logged rather than failing the batch.
+	BestEffort bool
+	// Tables are the only tables persisted to the storage, all tables are persisted if empty.
+	Tables []string
+	// ExcludeTables are tables that are not persisted to the storage.
+	ExcludeTables []string
+}
+
+// routes returns true if models of `table` should be persisted to the storage.
+func (c *CompositeChild) routes(table string) bool {
+	for _, t := range c.ExcludeTables {
+		if t == table {
+			return false
+		}
+	}
+	if len(c.Tables) == 0 {
+		return true
+	}
+	for _, t := range c.Tables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+func NewCompositeStorage(children ...*CompositeChild) (*CompositeStorage, error) {
+	if len(children) == 0 {
+		return nil, fmt.Errorf("composite storage requires at least one child storage")
+	}
+	for _, c := range children {
+		if _, ok := c.Storage.(*CompositeStorage); ok {
+			return nil, fmt.Errorf("child storage %q is a composite storage", c.Name)
+		}
+	}
+	return &CompositeStorage{children: children}, nil
+}
+
+var (
+	_ model.Storage            = (*CompositeStorage)(nil)
+	_ model.PartitionedStorage = (*CompositeStorage)(nil)
+	_ Connector                = (*CompositeStorage)(nil)
+	_ StorageWithMetadata      = (*CompositeStorage)(nil)
+)
+
+// A CompositeStorage persists each batch to several child storages concurrently, routing the models of each table to
+// the children configured to receive them.
+type CompositeStorage struct {
+	children []*CompositeChild
+}
+
+// PersistBatch persists the batch to every child storage. An error is returned if persisting to any child that is not
+// best effort fails.
+func (s *CompositeStorage) PersistBatch(ctx context.Context, ps ...model.Persistable) error {
+	errs := make([]error, len(s.children))
+	var wg sync.WaitGroup
+	for i, c := range s.children {
+		wg.Add(1)
+		go func(i int, c *CompositeChild) {
+			defer wg.Done()
+			routed := make([]model.Persistable, len(ps))
+			for j, p := range ps {
+				routed[j] = &routedPersistable{Persistable: p, child: c}
+			}
+			errs[i] = c.Storage.PersistBatch(ctx, routed...)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range s.children {
+		if errs[i] == nil {
+			continue
+		}
+		if c.BestEffort {
+			log.Errorw("failed to persist batch to best effort storage", "storage", c.Name, "error", errs[i])
+			continue
+		}
+		return fmt.Errorf("persisting to storage %q: %w", c.Name, errs[i])
+	}
+	return nil
+}
+
+// EnsurePartitions creates the partitions of the children that partition tables by height.
+func (s *CompositeStorage) EnsurePartitions(ctx context.Context, height int64) error {
+	for _, c := range s.children {
+		ps, ok := c.Storage.(model.PartitionedStorage)
+		if !ok {
+			continue
+		}
+		if err := ps.EnsurePartitions(ctx, height); err != nil {
+			if c.BestEffort {
+				log.Errorw("failed to ensure partitions of best effort storage", "storage", c.Name, "error", err)
+				continue
+			}
+			return fmt.Errorf("ensuring partitions of storage %q: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// WithMetadata returns a composite storage whose children are configured with the supplied metadata.
+func (s *CompositeStorage) WithMetadata(md Metadata) model.Storage {
+	children := make([]*CompositeChild, len(s.children))
+	for i, c := range s.children {
+		cc := *c
+		if ms, ok := c.Storage.(StorageWithMetadata); ok {
+			cc.Storage = ms.WithMetadata(md)
+		}
+		children[i] = &cc
+	}
+	return &CompositeStorage{children: children}
+}
+
+// Connect connects each child storage that needs to be connected. A best effort child that fails to connect is
+// logged and is still persisted to, failing each batch until it is able to.
+func (s *CompositeStorage) Connect(ctx context.Context) error {
+	for _, c := range s.children {
+		cs, ok := c.Storage.(Connector)
+		if !ok || cs.IsConnected(ctx) {
+			continue
+		}
+		if err := cs.Connect(ctx); err != nil {
+			if c.BestEffort {
+				log.Errorw("failed to connect best effort storage", "storage", c.Name, "error", err)
+				continue
+			}
+			return fmt.Errorf("connecting storage %q: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// IsConnected returns true if every child storage that is not best effort is connected.
+func (s *CompositeStorage) IsConnected(ctx context.Context) bool {
+	for _, c := range s.children {
+		if cs, ok := c.Storage.(Connector); ok && !c.BestEffort && !cs.IsConnected(ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// Flush persists the data buffered by the children that buffer data.
+func (s *CompositeStorage) Flush(ctx context.Context) error {
+	var firstErr error
+	for _, c := range s.children {
+		fs, ok := c.Storage.(Flusher)
+		if !ok {
+			continue
+		}
+		if err := fs.Flush(ctx); err != nil {
+			if c.BestEffort {
+				log.Errorw("failed to flush best effort storage", "storage", c.Name, "error", err)
+				continue
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("flushing storage %q: %w", c.Name, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+func (s *CompositeStorage) Close(ctx context.Context) error {
+	var firstErr error
+	for _, c := range s.children {
+		if cs, ok := c.Storage.(Connector); ok {
+			if err := cs.Close(ctx); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("closing storage %q: %w", c.Name, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// routedPersistable persists only the models of the tables routed to a child storage.
+type routedPersistable struct {
+	model.Persistable
+	child *CompositeChild
+}
+
+func (r *routedPersistable) Persist(ctx context.Context, s model.StorageBatch, version model.Version) error {
+	return r.Persistable.Persist(ctx, &routedBatch{StorageBatch: s, child: r.child}, version)
+}
+
+type routedBatch struct {
+	model.StorageBatch
+	child *CompositeChild
+}
+
+func (b *routedBatch) PersistModel(ctx context.Context, m interface{}) error {
+	table, ok := modelTable(m)
+	if ok && !b.child.routes(table) {
+		return nil
+	}
+	return b.StorageBatch.PersistModel(ctx, m)
+}
+
+// modelTable returns the name of the table of a model or of the models of a slice, false if the value is not a model.
+func modelTable(m interface{}) (string, bool) {
+	typ := reflect.TypeOf(m)
+	for typ != nil && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return "", false
+	}
+	return stripQuotes(orm.GetTable(typ).SQLNameForSelects), true
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/messages"
+)
+
+type failingStorage struct{}
+
+func (failingStorage) PersistBatch(context.Context, ...model.Persistable) error {
+	return errors.New("unavailable")
+}
+
+func TestCompositeStorageRouting(t *testing.T) {
+	ctx := context.Background()
+	db := NewMemStorageLatest()
+	files := NewMemStorageLatest()
+
+	cs, err := NewCompositeStorage(
+		&CompositeChild{Name: "db", Storage: db, ExcludeTables: []string{"vm_messages"}},
+		&CompositeChild{Name: "files", Storage: files, Tables: []string{"vm_messages"}},
+		&CompositeChild{Name: "archive", Storage: failingStorage{}, BestEffort: true},
+	)
+	require.NoError(t, err)
+
+	batch := model.PersistableList{
+		messages.VMMessageList{{Height: 1, Cid: "vm"}},
+		&messages.Message{Height: 1, Cid: "msg"},
+	}
+	require.NoError(t, cs.PersistBatch(ctx, batch))
+
+	assert.Len(t, db.Data["messages"], 1)
+	assert.Empty(t, db.Data["vm_messages"])
+	assert.Len(t, files.Data["vm_messages"], 1)
+	assert.Empty(t, files.Data["messages"])
+
+	required, err := NewCompositeStorage(
+		&CompositeChild{Name: "db", Storage: db},
+		&CompositeChild{Name: "archive", Storage: failingStorage{}},
+	)
+	require.NoError(t, err)
+	assert.Error(t, required.PersistBatch(ctx, batch))
+}
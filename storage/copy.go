@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// copyModel persists the models held in `value`, a struct or a slice of structs or pointers to structs, by streaming
+// them into a temporary table with COPY FROM STDIN and merging that table into the model's table. Conflicting rows are
+// ignored, or updated when upsert is true, as they are when models are inserted.
+func copyModel(ctx context.Context, tx *pg.Tx, value reflect.Value, upsert bool) error {
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	typ := value.Type()
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot copy %s", value.Type())
+	}
+
+	tbl := orm.GetTable(typ)
+	table := stripQuotes(tbl.SQLName)
+	temp := quoteIdent("lily_copy_" + table)
+
+	columns := make([]string, len(tbl.Fields))
+	for i, f := range tbl.Fields {
+		columns[i] = string(f.Column)
+	}
+	cols := strings.Join(columns, ", ")
+
+	// the temporary table is dropped once merged so that the same model may be copied again in the transaction.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TEMPORARY TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP`, temp, tbl.SQLName)); err != nil {
+		return fmt.Errorf("creating temporary table: %w", err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(writeCopyRows(pw, tbl, value))
+	}()
+	if _, err := tx.CopyFrom(pr, fmt.Sprintf(`COPY %s (%s) FROM STDIN`, temp, cols)); err != nil {
+		_ = pr.CloseWithError(err)
+		return fmt.Errorf("copying rows: %w", err)
+	}
+
+	merge := fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT DO NOTHING`, tbl.SQLName, cols, cols, temp)
+	if upsert {
+		// If the upsert string is blank all fields are primary keys and conflicting rows are identical.
+		if conflict, set := GenerateUpsertStrings(reflect.New(typ).Interface()); len(set) > 0 {
+			merge = fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM %s ON CONFLICT %s SET %s`, tbl.SQLName, cols, cols, temp, conflict, set)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, merge); err != nil {
+		return fmt.Errorf("merging rows: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DROP TABLE %s`, temp)); err != nil {
+		return fmt.Errorf("dropping temporary table: %w", err)
+	}
+	return nil
+}
+
+// writeCopyRows writes the models held in `value` to `w` in the text format of COPY, one row per model.
+func writeCopyRows(w io.Writer, tbl *orm.Table, value reflect.Value) error {
+	bw := bufio.NewWriter(w)
+	var row, buf []byte
+
+	writeRow := func(strct reflect.Value) error {
+		for strct.Kind() == reflect.Ptr {
+			strct = strct.Elem()
+		}
+		row = row[:0]
+		for i, f := range tbl.Fields {
+			if i > 0 {
+				row = append(row, '\t')
+			}
+			// Unquoted values are appended without escaping and a null value is appended as a nil slice, which
+			// is distinguished from an empty value by appending to a non-nil buffer.
+			if buf == nil {
+				buf = make([]byte, 0, 64)
+			}
+			v := f.AppendValue(buf[:0], strct, 0)
+			if v == nil {
+				row = append(row, `\N`...)
+				continue
+			}
+			row = appendCopyText(row, v)
+			buf = v
+		}
+		row = append(row, '\n')
+		_, err := bw.Write(row)
+		return err
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		for i := 0; i < value.Len(); i++ {
+			if err := writeRow(value.Index(i)); err != nil {
+				return err
+			}
+		}
+	} else if err := writeRow(value); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// appendCopyText appends `v` to `b`, escaping the characters with a special meaning in the text format of COPY.
+func appendCopyText(b []byte, v []byte) []byte {
+	for _, c := range v {
+		switch c {
+		case '\\':
+			b = append(b, '\\', '\\')
+		case '\t':
+			b = append(b, '\\', 't')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/model/messages"
+	"github.com/filecoin-project/lily/testutil"
+)
+
+type copyTestModel struct {
+	tableName struct{} `pg:"copy_test_models"` // nolint: structcheck,unused
+
+	Height    int64 `pg:",pk,use_zero"`
+	Name      string
+	Label     string `pg:",use_zero"`
+	Note      *string
+	Tags      []string          `pg:",array"`
+	Params    map[string]string `pg:",type:jsonb"`
+	Timestamp time.Time
+}
+
+func TestWriteCopyRows(t *testing.T) {
+	note := "back\\slash"
+	rows := []*copyTestModel{
+		{
+			Height:    1,
+			Name:      "tab\there\nnewline",
+			Note:      &note,
+			Tags:      []string{"a", "b c"},
+			Params:    map[string]string{"k": "v"},
+			Timestamp: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			Height: 0,
+			Name:   "",
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeCopyRows(&buf, orm.GetTable(reflect.TypeOf(copyTestModel{})), reflect.ValueOf(rows)))
+
+	assert.Equal(t,
+		"1\ttab\\there\\nnewline\t\tback\\\\slash\t{\"a\",\"b c\"}\t{\"k\":\"v\"}\t2021-01-02 03:04:05+00:00:00\n"+
+			"0\t\\N\t\t\\N\t\\N\t\\N\t\\N\n",
+		buf.String())
+}
+
+func BenchmarkPersistBatch(b *testing.B) {
+	if testing.Short() {
+		b.Skip("short testing requested")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDatabaseWaitTime)
+	defer cancel()
+
+	db, cleanup, err := testutil.WaitForExclusiveDatabase(ctx, b)
+	require.NoError(b, err)
+	defer func() { require.NoError(b, cleanup()) }()
+
+	msgs := make(messages.VMMessageList, 10_000)
+	for i := range msgs {
+		msgs[i] = &messages.VMMessage{
+			Height:    1,
+			StateRoot: "stateroot",
+			Cid:       fmt.Sprintf("cid-%d", i),
+			Source:    "source",
+			From:      "f01",
+			To:        "f02",
+			Value:     "0",
+			Method:    2,
+			ActorCode: "code",
+			ExitCode:  0,
+			GasUsed:   100,
+			Params:    `{"a":1}`,
+			Returns:   `{"b":2}`,
+			Index:     uint64(i),
+		}
+	}
+
+	for _, bulkCopy := range []bool{false, true} {
+		b.Run(fmt.Sprintf("copy=%t", bulkCopy), func(b *testing.B) {
+			d := &Database{
+				db:       db,
+				Clock:    testutil.NewMockClock(),
+				BulkCopy: bulkCopy,
+				version:  LatestSchemaVersion(),
+			}
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				_, err := db.Exec(`TRUNCATE TABLE vm_messages`)
+				require.NoError(b, err)
+				b.StartTimer()
+
+				require.NoError(b, d.PersistBatch(ctx, msgs))
+			}
+			var count int
+			_, err := db.QueryOne(pg.Scan(&count), `SELECT COUNT(*) FROM vm_messages`)
+			require.NoError(b, err)
+			assert.Equal(b, len(msgs), count)
+		})
+	}
+}
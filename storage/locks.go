@@ -67,3 +67,21 @@ func (l AdvisoryLock) UnlockShared(ctx context.Context, db *pg.DB) error {
 	}
 	return nil
 }
+
+// LockExclusiveTx acquires a transaction scoped exclusive advisory lock, waiting until it is available. The lock is
+// released when the transaction ends.
+func (l AdvisoryLock) LockExclusiveTx(ctx context.Context, tx *pg.Tx) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(?);`, int64(l)); err != nil {
+		return fmt.Errorf("acquiring exclusive transaction lock: %w", err)
+	}
+	return nil
+}
+
+// LockSharedTx acquires a transaction scoped shared advisory lock, waiting until it is available. The lock is
+// released when the transaction ends.
+func (l AdvisoryLock) LockSharedTx(ctx context.Context, tx *pg.Tx) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock_shared(?);`, int64(l)); err != nil {
+		return fmt.Errorf("acquiring shared transaction lock: %w", err)
+	}
+	return nil
+}
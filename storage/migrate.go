@@ -185,18 +185,8 @@ func (d *Database) MigrateSchemaTo(ctx context.Context, target model.Version) er
 	}
 	log.Infof("current database schema is version %s", dbVersion)
 
-	// Check that we are not trying to migrate to a different major version of an already installed schema
-	if initialized && target.Major != dbVersion.Major {
-		return fmt.Errorf("cannot migrate to a different major schema version. database version=%s, target version=%s", dbVersion, target)
-	}
-
-	latestVersion := latestSchemaVersionForMajor(target.Major)
-	if latestVersion.Patch < target.Patch {
-		return fmt.Errorf("no migrations found for version %s", target)
-	}
-
-	if dbVersion == target {
-		return fmt.Errorf("database schema is already at version %d", dbVersion)
+	if err := checkMigrationTarget(dbVersion, initialized, target); err != nil {
+		return err
 	}
 
 	coll, err := collectionForVersion(target, d.SchemaConfig())
@@ -269,6 +259,24 @@ func (d *Database) MigrateSchemaTo(ctx context.Context, target model.Version) er
 	return nil
 }
 
+// checkMigrationTarget returns an error if a database with schema version `dbVersion` cannot be migrated to `target`.
+func checkMigrationTarget(dbVersion model.Version, initialized bool, target model.Version) error {
+	// Check that we are not trying to migrate to a different major version of an already installed schema
+	if initialized && target.Major != dbVersion.Major {
+		return fmt.Errorf("cannot migrate to a different major schema version. database version=%s, target version=%s", dbVersion, target)
+	}
+
+	latestVersion := latestSchemaVersionForMajor(target.Major)
+	if latestVersion.Patch < target.Patch {
+		return fmt.Errorf("no migrations found for version %s", target)
+	}
+
+	if dbVersion == target {
+		return fmt.Errorf("database schema is already at version %d", dbVersion)
+	}
+	return nil
+}
+
 func checkMigrationSequence(_ context.Context, coll *migrations.Collection, from, to int) error {
 	versions := map[int64]bool{}
 	ms := coll.Migrations()
@@ -310,6 +318,15 @@ func collectionForVersion(version model.Version, cfg schemas.Config) (*migration
 	}
 }
 
+func patchSQLForVersion(version model.Version, cfg schemas.Config, seq int) (string, error) {
+	switch version.Major {
+	case 1:
+		return v1.GetPatchSQL(cfg, seq)
+	default:
+		return "", fmt.Errorf("unsupported major version: %d", version.Major)
+	}
+}
+
 func baseForVersion(version model.Version, cfg schemas.Config) (string, error) {
 	switch version.Major {
 	case 1:
@@ -0,0 +1,315 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+
+	"github.com/filecoin-project/lily/model"
+)
+
+// A MigrationPlan describes the changes a schema migration would make to a database without making them.
+type MigrationPlan struct {
+	From model.Version
+	To   model.Version
+	// Steps are the patches that would be applied, in the order they would be applied.
+	Steps []*MigrationStep
+	// Tables are size estimates of the existing tables affected by the migration.
+	Tables []*TableEstimate
+}
+
+// Destructive returns true if any statement of the plan is destructive.
+func (p *MigrationPlan) Destructive() bool {
+	for _, step := range p.Steps {
+		for _, stmt := range step.Statements {
+			if stmt.Destructive() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// A MigrationStep is a single schema patch of a MigrationPlan.
+type MigrationStep struct {
+	// Patch is the schema patch number, zero for the base schema.
+	Patch int
+	// Down is true if the patch would be rolled back. Patches have no down migrations so only the recorded schema
+	// version is changed.
+	Down       bool
+	Statements []*PlannedStatement
+}
+
+// A PlannedStatement is a single SQL statement of a MigrationStep.
+type PlannedStatement struct {
+	SQL string
+	// Objects are the schema qualified names of the tables, types and other objects referenced by the statement.
+	Objects []string
+	// Warnings describe why the statement is destructive, empty if it is not.
+	Warnings []string
+}
+
+// Destructive returns true if the statement drops objects or data, alters a type or rewrites a table.
+func (s *PlannedStatement) Destructive() bool {
+	return len(s.Warnings) > 0
+}
+
+// A TableEstimate is the size of a table as estimated by the postgres statistics collector.
+type TableEstimate struct {
+	Table string
+	// Rows is the estimated number of rows in the table, -1 if the table has never been analyzed.
+	Rows  int64
+	Bytes int64
+}
+
+// PlanSchemaMigrationTo returns the plan of migrating the database schema to a specific version without modifying the
+// database.
+func (d *Database) PlanSchemaMigrationTo(ctx context.Context, target model.Version) (*MigrationPlan, error) {
+	db, err := connect(ctx, d.opt)
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	defer db.Close() // nolint: errcheck
+
+	cfg := d.SchemaConfig()
+	dbVersion, initialized, err := getDatabaseSchemaVersion(ctx, db, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("get schema versions: %w", err)
+	}
+
+	if err := checkMigrationTarget(dbVersion, initialized, target); err != nil {
+		return nil, err
+	}
+
+	plan := &MigrationPlan{
+		From: dbVersion,
+		To:   target,
+	}
+
+	if !initialized {
+		base, err := baseForVersion(target, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("no base schema defined for version %s: %w", target, err)
+		}
+		plan.Steps = append(plan.Steps, &MigrationStep{Patch: 0, Statements: planStatements(base, cfg.SchemaName)})
+	}
+
+	if dbVersion.Patch > target.Patch {
+		for patch := dbVersion.Patch; patch > target.Patch; patch-- {
+			plan.Steps = append(plan.Steps, &MigrationStep{Patch: patch, Down: true})
+		}
+		return plan, nil
+	}
+
+	for patch := dbVersion.Patch + 1; patch <= target.Patch; patch++ {
+		sql, err := patchSQLForVersion(target, cfg, patch)
+		if err != nil {
+			return nil, fmt.Errorf("patch %d: %w", patch, err)
+		}
+		plan.Steps = append(plan.Steps, &MigrationStep{Patch: patch, Statements: planStatements(sql, cfg.SchemaName)})
+	}
+
+	plan.Tables, err = estimateTables(ctx, db, cfg.SchemaName, plan.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("estimate tables: %w", err)
+	}
+
+	return plan, nil
+}
+
+// estimateTables returns size estimates of the existing tables referenced by the statements of `steps`. Referenced
+// objects that are not tables, or do not exist yet, are omitted.
+func estimateTables(ctx context.Context, db *pg.DB, schemaName string, steps []*MigrationStep) ([]*TableEstimate, error) {
+	seen := map[string]bool{}
+	var names []string
+	for _, step := range steps {
+		for _, stmt := range step.Statements {
+			for _, obj := range stmt.Objects {
+				if !seen[obj] {
+					seen[obj] = true
+					names = append(names, obj)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var out []*TableEstimate
+	for _, name := range names {
+		relname := strings.Trim(strings.TrimPrefix(name, schemaName+"."), `"`)
+		est := &TableEstimate{Table: name}
+		_, err := db.QueryOneContext(ctx, pg.Scan(&est.Rows, &est.Bytes), `
+			SELECT c.reltuples::bigint, pg_total_relation_size(c.oid)
+			FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = ? AND c.relname = ? AND c.relkind IN ('r', 'p', 'm')`, schemaName, relname)
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				continue
+			}
+			return nil, fmt.Errorf("querying pg_class for %s: %w", name, err)
+		}
+		out = append(out, est)
+	}
+	return out, nil
+}
+
+// destructivePatterns match the statements that drop objects or data, alter a type or rewrite a table, along with a
+// description of each.
+var destructivePatterns = []struct {
+	re      *regexp.Regexp
+	warning string
+}{
+	{regexp.MustCompile(`(?i)\bDROP\s+(TABLE|COLUMN|INDEX|MATERIALIZED\s+VIEW|VIEW|TYPE|SCHEMA|FUNCTION|CONSTRAINT|TRIGGER)\b`), "drops %s"},
+	{regexp.MustCompile(`(?i)\bTRUNCATE\b`), "truncates table"},
+	{regexp.MustCompile(`(?i)\bDELETE\s+FROM\b`), "deletes rows"},
+	{regexp.MustCompile(`(?i)\bALTER\s+TYPE\b`), "alters type"},
+	{regexp.MustCompile(`(?i)\bALTER\s+COLUMN\s+\S+\s+(SET\s+DATA\s+)?TYPE\b`), "changes column type, rewriting the table"},
+	{regexp.MustCompile(`(?i)^UPDATE\b`), "updates rows, rewriting them"},
+	{regexp.MustCompile(`(?i)\b(VACUUM\s+FULL|CLUSTER)\b`), "rewrites table"},
+}
+
+// planStatements splits `sql` into statements, noting the objects in schema `schemaName` each references and why it
+// is destructive.
+func planStatements(sql string, schemaName string) []*PlannedStatement {
+	objectPattern := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(schemaName) + `\.("[^"]+"|[a-z_][a-z0-9_$]*)`)
+
+	var out []*PlannedStatement
+	for _, stmt := range splitStatements(sql) {
+		ps := &PlannedStatement{SQL: stmt.text}
+
+		seen := map[string]bool{}
+		for _, m := range objectPattern.FindAllStringSubmatch(stmt.code, -1) {
+			name := schemaName + "." + m[1]
+			if !seen[name] {
+				seen[name] = true
+				ps.Objects = append(ps.Objects, name)
+			}
+		}
+
+		for _, dp := range destructivePatterns {
+			for _, m := range dp.re.FindAllStringSubmatch(stmt.code, -1) {
+				w := dp.warning
+				if strings.Contains(w, "%s") {
+					w = fmt.Sprintf(w, strings.ToLower(strings.Join(strings.Fields(m[1]), " ")))
+				}
+				ps.Warnings = append(ps.Warnings, w)
+			}
+		}
+
+		out = append(out, ps)
+	}
+	return out
+}
+
+type sqlStatement struct {
+	// text is the statement as written.
+	text string
+	// code is the statement with comments removed and the contents of string literals blanked, so that it can be
+	// matched against without matching comments or literal values. The contents of dollar quoted strings are kept.
+	code string
+}
+
+// splitStatements splits `sql` on the semicolons that terminate statements, ignoring those in comments, quoted
+// identifiers, string literals and dollar quoted strings. Statements consisting only of comments are dropped.
+// The code of a statement includes the code of the statements within its dollar quoted strings.
+func splitStatements(sql string) []sqlStatement {
+	var (
+		out        []sqlStatement
+		text, code strings.Builder
+	)
+	flush := func() {
+		if strings.TrimSpace(code.String()) != "" {
+			out = append(out, sqlStatement{text: strings.TrimSpace(text.String()), code: strings.TrimSpace(code.String())})
+		}
+		text.Reset()
+		code.Reset()
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ';':
+			flush()
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			text.WriteString(sql[i : i+end])
+			code.WriteByte(' ')
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				end = len(sql) - i
+			} else {
+				end += 4
+			}
+			text.WriteString(sql[i : i+end])
+			code.WriteByte(' ')
+			i += end
+		case c == '\'':
+			end := i + 1
+			for end < len(sql) {
+				if sql[end] == '\'' {
+					// a doubled quote is an escaped quote within the literal
+					if end+1 < len(sql) && sql[end+1] == '\'' {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end+1, len(sql))
+			text.WriteString(sql[i:end])
+			code.WriteString("''")
+			i = end
+		case c == '"':
+			end := strings.IndexByte(sql[i+1:], '"')
+			if end < 0 {
+				end = len(sql)
+			} else {
+				end += i + 2
+			}
+			text.WriteString(sql[i:end])
+			code.WriteString(sql[i:end])
+			i = end
+		case c == '$':
+			if tag := dollarQuoteTag.FindString(sql[i:]); tag != "" {
+				end := strings.Index(sql[i+len(tag):], tag)
+				if end < 0 {
+					end = len(sql)
+				} else {
+					end += i + 2*len(tag)
+				}
+				text.WriteString(sql[i:end])
+				// dollar quoted strings are the bodies of functions and DO blocks, keep the statements they execute
+				// so they are analyzed with the statement.
+				code.WriteString(tag)
+				for _, inner := range splitStatements(sql[min(i+len(tag), end):max(end-len(tag), i+len(tag))]) {
+					code.WriteString(inner.code)
+					code.WriteString("; ")
+				}
+				code.WriteString(tag)
+				i = end
+				continue
+			}
+			fallthrough
+		default:
+			text.WriteByte(c)
+			code.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return out
+}
+
+var dollarQuoteTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
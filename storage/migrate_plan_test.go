@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/schemas"
+	v1 "github.com/filecoin-project/lily/schemas/v1"
+)
+
+func TestPlanStatements(t *testing.T) {
+	sql := `
+	-- drop the old column; it is unused
+	ALTER TABLE public.chain_economics DROP COLUMN IF EXISTS locked_fil_v2;
+	COMMENT ON TABLE public.chain_economics IS 'DROP TABLE is not a statement; here';
+	CREATE FUNCTION public.f() RETURNS void AS $body$ BEGIN DELETE FROM public.t; END $body$ LANGUAGE plpgsql;
+	ALTER TABLE public."miner_info" ALTER COLUMN owner_id TYPE bigint;
+	-- trailing comment
+`
+	stmts := planStatements(sql, "public")
+	require.Len(t, stmts, 4)
+
+	assert.Equal(t, []string{"public.chain_economics"}, stmts[0].Objects)
+	assert.Equal(t, []string{"drops column"}, stmts[0].Warnings)
+	assert.Contains(t, stmts[0].SQL, "-- drop the old column; it is unused")
+
+	assert.False(t, stmts[1].Destructive())
+	assert.Equal(t, "COMMENT ON TABLE public.chain_economics IS 'DROP TABLE is not a statement; here'", stmts[1].SQL)
+
+	// statements within function bodies are analyzed with the statement creating the function
+	assert.Equal(t, []string{"public.f", "public.t"}, stmts[2].Objects)
+	assert.Equal(t, []string{"deletes rows"}, stmts[2].Warnings)
+
+	assert.Equal(t, []string{`public."miner_info"`}, stmts[3].Objects)
+	assert.Equal(t, []string{"changes column type, rewriting the table"}, stmts[3].Warnings)
+}
+
+func TestPlanStatementsForPatches(t *testing.T) {
+	cfg := schemas.Config{SchemaName: "public"}
+	for patch := 1; patch <= v1.Version().Patch; patch++ {
+		sql, err := v1.GetPatchSQL(cfg, patch)
+		require.NoError(t, err)
+		assert.NotEmpty(t, planStatements(sql, cfg.SchemaName), "patch %d", patch)
+	}
+
+	sql, err := v1.GetPatchSQL(cfg, 5)
+	require.NoError(t, err)
+	stmts := planStatements(sql, cfg.SchemaName)
+	require.NotEmpty(t, stmts)
+	assert.Equal(t, []string{"alters type"}, stmts[0].Warnings)
+	for _, stmt := range stmts[1:] {
+		assert.False(t, stmt.Destructive(), stmt.SQL)
+	}
+}
+
+func TestPartitionedTablesPatch(t *testing.T) {
+	// the patch only records partitioned tables, tables are partitioned by PartitionTable
+	sql, err := v1.GetPatchSQL(schemas.Config{SchemaName: "lily"}, 44)
+	require.NoError(t, err)
+	assert.NotContains(t, sql, "PARTITION BY")
+
+	stmts := planStatements(sql, "lily")
+	require.Len(t, stmts, 4)
+	for _, stmt := range stmts {
+		assert.Equal(t, []string{"lily.visor_partitioned_tables"}, stmt.Objects)
+		assert.False(t, stmt.Destructive(), stmt.SQL)
+	}
+}
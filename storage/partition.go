@@ -0,0 +1,263 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// PartitionableTables are the high volume tables that may be range partitioned by height.
+var PartitionableTables = []string{"messages", "vm_messages", "actor_events", "fevm_traces", "miner_sector_events"}
+
+const (
+	DefaultPartitionSize   = 100_000
+	DefaultPartitionsAhead = 2
+)
+
+// partitionedTablesReload is how often the partitioned tables are reloaded so that tables partitioned while the
+// database is in use get their partitions created.
+const partitionedTablesReload = time.Minute
+
+var (
+	ErrTableAlreadyPartitioned = errors.New("table is already partitioned")
+	ErrTableIsHypertable       = errors.New("table is a timescaledb hypertable")
+	ErrTableNotEmpty           = errors.New("table contains rows")
+)
+
+// PartitionConfig configures how a Database creates the partitions of its tables that are range partitioned by height.
+type PartitionConfig struct {
+	// Ahead is the number of partitions created ahead of the partition containing the height being persisted.
+	Ahead int
+}
+
+// partitioner creates the partitions of the partitioned tables of a database before they are written to.
+type partitioner struct {
+	mu    sync.Mutex
+	ahead int64
+	// sizes holds the partition size of each partitioned table, loaded from the database on first use and reloaded
+	// every partitionedTablesReload.
+	sizes  map[string]int64
+	loaded time.Time
+	// created holds the upper bound of the partitions created for each partitioned table.
+	created map[string]int64
+	// ensured is the height from which partitions must be created before data is persisted.
+	ensured int64
+}
+
+// SetPartitioning configures how partitions of the tables of the database that are range partitioned by height are
+// created by EnsurePartitions. Tables are partitioned by PartitionTable.
+func (d *Database) SetPartitioning(cfg PartitionConfig) error {
+	if cfg.Ahead < 0 {
+		return fmt.Errorf("partitions ahead must not be negative: %d", cfg.Ahead)
+	}
+	if cfg.Ahead == 0 {
+		cfg.Ahead = DefaultPartitionsAhead
+	}
+	d.partitions = &partitioner{ahead: int64(cfg.Ahead)}
+	return nil
+}
+
+// PartitionTable recreates `table` as a table range partitioned by height with partitions of `size` epochs and
+// records it in visor_partitioned_tables so partitions are created by EnsurePartitions. The schema must have been
+// migrated to a version that includes visor_partitioned_tables. Only empty tables that are not timescaledb
+// hypertables can be partitioned.
+func (d *Database) PartitionTable(ctx context.Context, table string, size int64) error {
+	if !isPartitionable(table) {
+		return fmt.Errorf("table %q cannot be partitioned, must be one of %v", table, PartitionableTables)
+	}
+	if size <= 0 {
+		return fmt.Errorf("partition size must be greater than zero: %d", size)
+	}
+
+	schemaName := d.schemaConfig.SchemaName
+	var recorded *string
+	if _, err := d.db.QueryOneContext(ctx, pg.Scan(&recorded), `SELECT to_regclass(?)::text`, quoteIdent(schemaName)+".visor_partitioned_tables"); err != nil {
+		return fmt.Errorf("checking for visor_partitioned_tables: %w", err)
+	}
+	if recorded == nil {
+		return fmt.Errorf("visor_partitioned_tables does not exist in schema %s, migrate the schema before partitioning tables", schemaName)
+	}
+
+	var kind *string
+	if _, err := d.db.QueryOneContext(ctx, pg.Scan(&kind), `
+		SELECT c.relkind::text
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = ? AND c.relname = ?`, schemaName, table); err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return fmt.Errorf("querying table %s: %w", table, err)
+	}
+	if kind == nil {
+		return fmt.Errorf("table %s does not exist in schema %s", table, schemaName)
+	}
+	if *kind == "p" {
+		return fmt.Errorf("cannot partition %s: %w", table, ErrTableAlreadyPartitioned)
+	}
+
+	hypertable, err := d.isHypertable(ctx, table)
+	if err != nil {
+		return err
+	}
+	if hypertable {
+		return fmt.Errorf("cannot partition %s: %w, drop or convert the hypertable before partitioning it", table, ErrTableIsHypertable)
+	}
+
+	qualified := quoteIdent(schemaName) + "." + quoteIdent(table)
+	unpartitioned := table + "_unpartitioned"
+	return d.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		// block writers until the table has been replaced so no rows are written after it is found to be empty.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`LOCK TABLE %s IN ACCESS EXCLUSIVE MODE`, qualified)); err != nil {
+			return fmt.Errorf("locking %s: %w", table, err)
+		}
+		var exists bool
+		if _, err := tx.QueryOneContext(ctx, pg.Scan(&exists), fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s)`, qualified)); err != nil {
+			return fmt.Errorf("querying rows of %s: %w", table, err)
+		}
+		if exists {
+			return fmt.Errorf("cannot partition %s: %w, only empty tables can be partitioned", table, ErrTableNotEmpty)
+		}
+
+		// move the existing table and its indexes aside so the partitioned table takes their names.
+		var indexes []string
+		if _, err := tx.QueryContext(ctx, &indexes, `SELECT indexname FROM pg_indexes WHERE schemaname = ? AND tablename = ?`, schemaName, table); err != nil {
+			return fmt.Errorf("querying indexes of %s: %w", table, err)
+		}
+		stmts := []string{fmt.Sprintf(`ALTER TABLE %s RENAME TO %s`, qualified, quoteIdent(unpartitioned))}
+		for _, idx := range indexes {
+			stmts = append(stmts, fmt.Sprintf(`ALTER INDEX %s.%s RENAME TO %s`, quoteIdent(schemaName), quoteIdent(idx), quoteIdent("unpartitioned_"+idx)))
+		}
+		stmts = append(stmts,
+			fmt.Sprintf(`CREATE TABLE %s (LIKE %s.%s INCLUDING ALL) PARTITION BY RANGE (height)`, qualified, quoteIdent(schemaName), quoteIdent(unpartitioned)),
+			fmt.Sprintf(`DROP TABLE %s.%s`, quoteIdent(schemaName), quoteIdent(unpartitioned)),
+		)
+		for _, stmt := range stmts {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("partitioning %s: %w", table, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO ?.visor_partitioned_tables (table_name, partition_size) VALUES (?, ?)`, pg.Ident(schemaName), table, size); err != nil {
+			return fmt.Errorf("recording partitioned table %s: %w", table, err)
+		}
+		return nil
+	})
+}
+
+// isHypertable reports whether `table` has been converted to a timescaledb hypertable.
+func (d *Database) isHypertable(ctx context.Context, table string) (bool, error) {
+	var timescale bool
+	if _, err := d.db.QueryOneContext(ctx, pg.Scan(&timescale), `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')`); err != nil {
+		return false, fmt.Errorf("querying extensions: %w", err)
+	}
+	if !timescale {
+		return false, nil
+	}
+	var hypertable bool
+	if _, err := d.db.QueryOneContext(ctx, pg.Scan(&hypertable), `
+		SELECT EXISTS (
+			SELECT 1 FROM timescaledb_information.hypertables WHERE hypertable_schema = ? AND hypertable_name = ?
+		)`, d.schemaConfig.SchemaName, table); err != nil {
+		return false, fmt.Errorf("querying hypertables: %w", err)
+	}
+	return hypertable, nil
+}
+
+func isPartitionable(table string) bool {
+	for _, t := range PartitionableTables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsurePartitions creates the partitions of the partitioned tables from genesis, or the lowest partition remaining
+// after pruning, up to the configured number of partitions ahead of `height`. It must be called before persisting
+// data at `height`.
+func (d *Database) EnsurePartitions(ctx context.Context, height int64) error {
+	if d.partitions == nil {
+		return nil
+	}
+	p := d.partitions
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	reload := p.sizes == nil || time.Since(p.loaded) >= partitionedTablesReload
+	if height < p.ensured && !reload {
+		return nil
+	}
+
+	if reload {
+		sizes, err := d.partitionedTables(ctx)
+		if err != nil {
+			return err
+		}
+		if p.created == nil {
+			p.created = map[string]int64{}
+		}
+		// partitions below the lowest existing partition of a newly loaded table have been pruned, don't recreate them.
+		for table := range sizes {
+			if _, ok := p.created[table]; ok {
+				continue
+			}
+			starts, err := d.partitionStarts(ctx, table)
+			if err != nil {
+				return err
+			}
+			p.created[table] = 0
+			if len(starts) > 0 {
+				p.created[table] = starts[0]
+			}
+		}
+		p.sizes = sizes
+		p.loaded = time.Now()
+	}
+
+	tables := make([]string, 0, len(p.sizes))
+	for table := range p.sizes {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	ensured := int64(-1)
+	for _, table := range tables {
+		size := p.sizes[table]
+		upper := (height/size + 1 + p.ahead) * size
+		for start := p.created[table]; start < upper; start += size {
+			name := fmt.Sprintf("%s_h%d", table, start)
+			if _, err := d.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s.%s PARTITION OF %s.%s FOR VALUES FROM (%d) TO (%d)`,
+				quoteIdent(d.schemaConfig.SchemaName), quoteIdent(name), quoteIdent(d.schemaConfig.SchemaName), quoteIdent(table), start, start+size)); err != nil {
+				return fmt.Errorf("creating partition %s: %w", name, err)
+			}
+			p.created[table] = start + size
+		}
+		// more partitions are created once height leaves the partition it is in now.
+		if next := upper - p.ahead*size; ensured < 0 || next < ensured {
+			ensured = next
+		}
+	}
+	if ensured >= 0 {
+		log.Debugw("ensured partitions", "height", height, "until", ensured)
+		p.ensured = ensured
+	}
+	return nil
+}
+
+// partitionedTables returns the partition size of every table recorded in visor_partitioned_tables.
+func (d *Database) partitionedTables(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		TableName     string
+		PartitionSize int64
+	}
+	if _, err := d.db.QueryContext(ctx, &rows, `SELECT table_name, partition_size FROM ?.visor_partitioned_tables`, pg.Ident(d.schemaConfig.SchemaName)); err != nil {
+		return nil, fmt.Errorf("querying partitioned tables: %w", err)
+	}
+	sizes := map[string]int64{}
+	for _, r := range rows {
+		sizes[r.TableName] = r.PartitionSize
+	}
+	return sizes, nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/schemas"
+	v1 "github.com/filecoin-project/lily/schemas/v1"
+	"github.com/filecoin-project/lily/testutil"
+)
+
+const partitionTestSchema = "lily_partition_test"
+
+// partitionTestDatabase returns a Database using a scratch schema to which schema patch 44 has been applied and in
+// which vm_messages and messages exist as simplified, empty tables.
+func partitionTestDatabase(ctx context.Context, t *testing.T, db *pg.DB) *Database {
+	_, err := db.Exec(`DROP SCHEMA IF EXISTS ? CASCADE`, pg.Ident(partitionTestSchema))
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE SCHEMA ?`, pg.Ident(partitionTestSchema))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := db.Exec(`DROP SCHEMA IF EXISTS ? CASCADE`, pg.Ident(partitionTestSchema))
+		assert.NoError(t, err)
+	})
+
+	sql, err := v1.GetPatchSQL(schemas.Config{SchemaName: partitionTestSchema}, 44)
+	require.NoError(t, err)
+	_, err = db.Exec(sql)
+	require.NoError(t, err, "applying patch 44")
+
+	for _, table := range []string{"vm_messages", "messages"} {
+		_, err = db.Exec(`CREATE TABLE ?.? (height bigint NOT NULL, cid text NOT NULL, PRIMARY KEY (height, cid))`, pg.Ident(partitionTestSchema), pg.Ident(table))
+		require.NoError(t, err)
+	}
+
+	return &Database{
+		db:           db,
+		schemaConfig: schemas.Config{SchemaName: partitionTestSchema},
+		Clock:        testutil.NewMockClock(),
+		partitions:   &partitioner{ahead: 1},
+	}
+}
+
+func TestPartitionTable(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short testing requested")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDatabaseWaitTime)
+	defer cancel()
+
+	db, cleanup, err := testutil.WaitForExclusiveDatabase(ctx, t)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, cleanup()) }()
+
+	d := partitionTestDatabase(ctx, t, db)
+
+	require.NoError(t, d.PartitionTable(ctx, "vm_messages", 100))
+
+	sizes, err := d.partitionedTables(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"vm_messages": 100}, sizes)
+
+	// rows can be written once the partitions for their height exist
+	require.NoError(t, d.EnsurePartitions(ctx, 150))
+	starts, err := d.partitionStarts(ctx, "vm_messages")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 100, 200}, starts)
+	_, err = db.Exec(`INSERT INTO ?.vm_messages (height, cid) VALUES (150, 'a')`, pg.Ident(partitionTestSchema))
+	require.NoError(t, err)
+
+	// tables partitioned while the database is in use get their partitions once the partitioned tables are reloaded
+	require.NoError(t, d.PartitionTable(ctx, "fevm_traces", 100))
+	require.NoError(t, d.EnsurePartitions(ctx, 150))
+	starts, err = d.partitionStarts(ctx, "fevm_traces")
+	require.NoError(t, err)
+	assert.Empty(t, starts)
+	d.partitions.loaded = time.Time{}
+	require.NoError(t, d.EnsurePartitions(ctx, 150))
+	starts, err = d.partitionStarts(ctx, "fevm_traces")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 100, 200}, starts)
+
+	// partitioned tables are refused
+	assert.ErrorIs(t, d.PartitionTable(ctx, "vm_messages", 100), ErrTableAlreadyPartitioned)
+
+	// tables with rows are refused and left as they were
+	_, err = db.Exec(`INSERT INTO ?.messages (height, cid) VALUES (1, 'a')`, pg.Ident(partitionTestSchema))
+	require.NoError(t, err)
+	assert.ErrorIs(t, d.PartitionTable(ctx, "messages", 100), ErrTableNotEmpty)
+	var count int
+	_, err = db.QueryOne(pg.Scan(&count), `SELECT count(*) FROM ?.messages`, pg.Ident(partitionTestSchema))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// tables that cannot be partitioned and invalid sizes are refused
+	assert.Error(t, d.PartitionTable(ctx, "block_headers", 100))
+	assert.Error(t, d.PartitionTable(ctx, "actor_events", 0))
+}
+
+func TestPartitionTableRefusesHypertables(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short testing requested")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDatabaseWaitTime)
+	defer cancel()
+
+	db, cleanup, err := testutil.WaitForExclusiveDatabase(ctx, t)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, cleanup()) }()
+
+	var timescale bool
+	_, err = db.QueryOne(pg.Scan(&timescale), `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')`)
+	require.NoError(t, err)
+	if !timescale {
+		t.Skip("timescaledb extension is not installed")
+	}
+
+	d := partitionTestDatabase(ctx, t, db)
+	_, err = db.Exec(`SELECT create_hypertable(?, 'height', chunk_time_interval => 100)`, partitionTestSchema+".vm_messages")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, d.PartitionTable(ctx, "vm_messages", 100), ErrTableIsHypertable)
+}
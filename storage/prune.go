@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// PruneResult describes the data removed from a table by Prune.
+type PruneResult struct {
+	Table string
+	// Below is the height below which data was removed.
+	Below int64
+	// Partitions are the names of the partitions that were detached and dropped.
+	Partitions []string
+	// Rows is the number of rows deleted, excluding those of dropped partitions.
+	Rows int64
+}
+
+// SetRetention configures the number of epochs of data kept in each table, keyed by table name. Only tables with a
+// height column may have a retention.
+func (d *Database) SetRetention(retention map[string]int64) error {
+	prunable := PrunableTables()
+	for table, epochs := range retention {
+		if !prunable[table] {
+			return fmt.Errorf("table %q cannot be pruned, it is not a table with a height column", table)
+		}
+		if epochs <= 0 {
+			return fmt.Errorf("retention of table %q must be greater than zero: %d", table, epochs)
+		}
+	}
+	d.retention = retention
+	return nil
+}
+
+// Retention returns the number of epochs of data kept in each table with a retention, keyed by table name.
+func (d *Database) Retention() map[string]int64 {
+	return d.retention
+}
+
+// PrunableTables returns the names of the tables of Models that have a height column.
+func PrunableTables() map[string]bool {
+	out := map[string]bool{}
+	for _, m := range Models {
+		tbl := orm.GetTable(reflect.TypeOf(m).Elem())
+		if _, ok := tbl.FieldsMap["height"]; ok {
+			out[stripQuotes(tbl.SQLNameForSelects)] = true
+		}
+	}
+	return out
+}
+
+// Prune removes the rows of `table` with a height below `below`. Partitions of a partitioned table that lie entirely
+// below `below` are detached and dropped, remaining rows are deleted in batches of at most `batchSize` epochs. Each
+// batch is a transaction holding the PruneLock of the table exclusively so data is not pruned while it is being
+// written.
+func (d *Database) Prune(ctx context.Context, table string, below int64, batchSize int64) (*PruneResult, error) {
+	if !PrunableTables()[table] {
+		return nil, fmt.Errorf("table %q cannot be pruned", table)
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be greater than zero: %d", batchSize)
+	}
+
+	res := &PruneResult{Table: table, Below: below}
+	schemaName := d.schemaConfig.SchemaName
+	qualified := quoteIdent(schemaName) + "." + quoteIdent(table)
+
+	sizes, err := d.partitionedTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if size, ok := sizes[table]; ok {
+		starts, err := d.partitionStarts(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		for _, start := range starts {
+			if start+size > below {
+				break
+			}
+			name := fmt.Sprintf("%s_h%d", table, start)
+			partition := quoteIdent(schemaName) + "." + quoteIdent(name)
+			if err := d.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+				if err := PruneLock(table).LockExclusiveTx(ctx, tx); err != nil {
+					return err
+				}
+				if _, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s DETACH PARTITION %s`, qualified, partition)); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, fmt.Sprintf(`DROP TABLE %s`, partition))
+				return err
+			}); err != nil {
+				return res, fmt.Errorf("dropping partition %s: %w", name, err)
+			}
+			res.Partitions = append(res.Partitions, name)
+		}
+	}
+
+	for {
+		// find the lowest remaining height rather than stepping through ranges of epochs that hold no data.
+		var lowest *int64
+		if _, err := d.db.QueryOneContext(ctx, pg.Scan(&lowest), fmt.Sprintf(`SELECT min(height) FROM %s WHERE height < ?`, qualified), below); err != nil {
+			return res, fmt.Errorf("querying lowest height: %w", err)
+		}
+		if lowest == nil {
+			return res, nil
+		}
+
+		upper := min(*lowest+batchSize, below)
+		if err := d.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			if err := PruneLock(table).LockExclusiveTx(ctx, tx); err != nil {
+				return err
+			}
+			r, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE height >= ? AND height < ?`, qualified), *lowest, upper)
+			if err != nil {
+				return err
+			}
+			res.Rows += int64(r.RowsAffected())
+			return nil
+		}); err != nil {
+			return res, fmt.Errorf("deleting heights %d to %d: %w", *lowest, upper, err)
+		}
+		log.Debugw("pruned batch", "table", table, "from", *lowest, "to", upper)
+	}
+}
+
+// partitionStarts returns the lowest height of each partition of `table` created by EnsurePartitions, in ascending
+// order.
+func (d *Database) partitionStarts(ctx context.Context, table string) ([]int64, error) {
+	var names []string
+	if _, err := d.db.QueryContext(ctx, &names, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		JOIN pg_namespace n ON n.oid = p.relnamespace
+		WHERE n.nspname = ? AND p.relname = ?`, d.schemaConfig.SchemaName, table); err != nil {
+		return nil, fmt.Errorf("querying partitions of %s: %w", table, err)
+	}
+
+	var starts []int64
+	for _, name := range names {
+		var start int64
+		if _, err := fmt.Sscanf(name, table+"_h%d", &start); err != nil || name != fmt.Sprintf("%s_h%d", table, start) {
+			continue
+		}
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+	return starts, nil
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/model/messages"
+	"github.com/filecoin-project/lily/testutil"
+)
+
+func TestSetRetention(t *testing.T) {
+	prunable := PrunableTables()
+	assert.True(t, prunable["vm_messages"])
+	assert.True(t, prunable["actor_states"])
+	assert.False(t, prunable["no_such_table"])
+
+	d := &Database{}
+	require.NoError(t, d.SetRetention(map[string]int64{"vm_messages": 259200, "actor_states": 259200}))
+	assert.Equal(t, int64(259200), d.Retention()["vm_messages"])
+
+	assert.Error(t, d.SetRetention(map[string]int64{"no_such_table": 10}))
+	assert.Error(t, d.SetRetention(map[string]int64{"vm_messages": 0}))
+}
+
+func TestPruneLock(t *testing.T) {
+	assert.Equal(t, PruneLock("vm_messages"), PruneLock("vm_messages"))
+	assert.NotEqual(t, PruneLock("vm_messages"), PruneLock("messages"))
+	assert.NotEqual(t, SchemaLock, PruneLock("vm_messages"))
+}
+
+func TestLockPrunableTableWithoutRetention(t *testing.T) {
+	// tables without a retention are never pruned so they are written without taking their prune lock, a
+	// transaction is not needed.
+	txs := &TxStorage{retention: map[string]int64{"actor_states": 259200}}
+	require.NoError(t, txs.lockPrunableTable(context.Background(), &messages.VMMessage{}))
+	require.NoError(t, txs.lockPrunableTable(context.Background(), messages.VMMessageList{}))
+	assert.Empty(t, txs.locked)
+
+	txs = &TxStorage{}
+	require.NoError(t, txs.lockPrunableTable(context.Background(), &messages.VMMessage{}))
+	assert.Empty(t, txs.locked)
+}
+
+func TestPruneDropsPartitions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short testing requested")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDatabaseWaitTime)
+	defer cancel()
+
+	db, cleanup, err := testutil.WaitForExclusiveDatabase(ctx, t)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, cleanup()) }()
+
+	d := partitionTestDatabase(ctx, t, db)
+	require.NoError(t, d.PartitionTable(ctx, "vm_messages", 100))
+	require.NoError(t, d.EnsurePartitions(ctx, 350))
+	for _, height := range []int64{10, 150, 250, 260, 350} {
+		_, err := db.Exec(`INSERT INTO ?.vm_messages (height, cid) VALUES (?, 'a')`, pg.Ident(partitionTestSchema), height)
+		require.NoError(t, err)
+	}
+
+	// partitions entirely below 260 are dropped, rows of the partition containing 260 are deleted
+	res, err := d.Prune(ctx, "vm_messages", 260, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vm_messages_h0", "vm_messages_h100"}, res.Partitions)
+	assert.Equal(t, int64(1), res.Rows)
+
+	starts, err := d.partitionStarts(ctx, "vm_messages")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{200, 300, 400}, starts)
+	assert.Equal(t, []int64{260, 350}, pruneTestHeights(t, db, "vm_messages"))
+}
+
+func TestPruneDeletesInBatches(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short testing requested")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDatabaseWaitTime)
+	defer cancel()
+
+	db, cleanup, err := testutil.WaitForExclusiveDatabase(ctx, t)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, cleanup()) }()
+
+	d := partitionTestDatabase(ctx, t, db)
+	for _, height := range []int64{1, 2, 3, 5, 8, 13, 21} {
+		_, err := db.Exec(`INSERT INTO ?.messages (height, cid) VALUES (?, 'a')`, pg.Ident(partitionTestSchema), height)
+		require.NoError(t, err)
+	}
+
+	// rows at or above the retention boundary are kept whatever the batch size
+	res, err := d.Prune(ctx, "messages", 8, 2)
+	require.NoError(t, err)
+	assert.Empty(t, res.Partitions)
+	assert.Equal(t, int64(4), res.Rows)
+	assert.Equal(t, []int64{8, 13, 21}, pruneTestHeights(t, db, "messages"))
+
+	// pruning again removes nothing
+	res, err = d.Prune(ctx, "messages", 8, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Rows)
+}
+
+func pruneTestHeights(t *testing.T, db *pg.DB, table string) []int64 {
+	var heights []int64
+	_, err := db.Query(&heights, `SELECT height FROM ?.? ORDER BY height`, pg.Ident(partitionTestSchema), pg.Ident(table))
+	require.NoError(t, err)
+	return heights
+}
@@ -0,0 +1,145 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-pg/pg/v10/orm"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/storage"
+)
+
+// Filters supported by the query API. Each filter matches rows where any of the filter's columns present in a table
+// equals the requested value.
+const (
+	FilterAddress  = "address"
+	FilterCid      = "cid"
+	FilterDealID   = "deal_id"
+	FilterSectorID = "sector_id"
+)
+
+// HeightColumn is the column used to filter rows by a height range.
+const HeightColumn = "height"
+
+var filterColumns = map[string][]string{
+	FilterAddress:  {"address", "miner_id", "actor_id", "from", "to", "emitter", "client_id", "provider_id", "multisig_id", "owner_id", "worker_id"},
+	FilterCid:      {"cid", "message", "message_cid"},
+	FilterDealID:   {"deal_id"},
+	FilterSectorID: {"sector_id"},
+}
+
+// Column describes a column of a table served by the query API.
+type Column struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	PrimaryKey bool   `json:"primary_key"`
+}
+
+// Table describes a table served by the query API and the filters that may be applied to it.
+type Table struct {
+	Name    string   `json:"name"`
+	Columns []Column `json:"columns"`
+	Filters []string `json:"filters"`
+
+	// filterColumns maps each supported filter to the columns of the table it matches.
+	filterColumns map[string][]string
+	// columnTypes maps each column of the table to its SQL type.
+	columnTypes map[string]string
+	primaryKeys []string
+	hasHeight   bool
+}
+
+// Schema describes the tables served by the query API.
+type Schema struct {
+	Version string   `json:"version"`
+	Tables  []*Table `json:"tables"`
+
+	byName map[string]*Table
+}
+
+// NewSchema returns the schema of storage.Models at `version`, derived from the go-pg model definitions.
+func NewSchema(version model.Version) (*Schema, error) {
+	type versionable interface {
+		AsVersion(model.Version) (interface{}, bool)
+	}
+
+	s := &Schema{
+		Version: version.String(),
+		byName:  make(map[string]*Table),
+	}
+	for _, m := range storage.Models {
+		if vm, ok := m.(versionable); ok {
+			vmodel, ok := vm.AsVersion(version)
+			if !ok {
+				return nil, fmt.Errorf("model %T does not support version %s", m, version)
+			}
+			m = vmodel
+		}
+
+		t := newTable(orm.GetTable(reflect.TypeOf(m).Elem()))
+		if _, exists := s.byName[t.Name]; exists {
+			continue
+		}
+		s.byName[t.Name] = t
+		s.Tables = append(s.Tables, t)
+	}
+	sort.Slice(s.Tables, func(i, j int) bool {
+		return s.Tables[i].Name < s.Tables[j].Name
+	})
+	return s, nil
+}
+
+// Table returns the table called `name`, or false if the schema has no such table.
+func (s *Schema) Table(name string) (*Table, bool) {
+	t, ok := s.byName[name]
+	return t, ok
+}
+
+func newTable(ot *orm.Table) *Table {
+	t := &Table{
+		Name:          strings.Trim(string(ot.SQLNameForSelects), `"`),
+		Filters:       []string{},
+		filterColumns: make(map[string][]string),
+		columnTypes:   make(map[string]string),
+	}
+
+	pks := make(map[string]struct{}, len(ot.PKs))
+	for _, pk := range ot.PKs {
+		pks[pk.SQLName] = struct{}{}
+		t.primaryKeys = append(t.primaryKeys, pk.SQLName)
+	}
+
+	columns := make(map[string]struct{}, len(ot.Fields))
+	for _, f := range ot.Fields {
+		_, isPK := pks[f.SQLName]
+		columns[f.SQLName] = struct{}{}
+		t.columnTypes[f.SQLName] = f.SQLType
+		t.Columns = append(t.Columns, Column{
+			Name:       f.SQLName,
+			Type:       f.SQLType,
+			PrimaryKey: isPK,
+		})
+		if f.SQLName == HeightColumn {
+			t.hasHeight = true
+		}
+	}
+
+	for filter, candidates := range filterColumns {
+		for _, c := range candidates {
+			if _, ok := columns[c]; ok {
+				t.filterColumns[filter] = append(t.filterColumns[filter], c)
+			}
+		}
+		if len(t.filterColumns[filter]) > 0 {
+			t.Filters = append(t.Filters, filter)
+		}
+	}
+	if t.hasHeight {
+		t.Filters = append(t.Filters, "min_height", "max_height")
+	}
+	sort.Strings(t.Filters)
+	return t
+}
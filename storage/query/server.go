@@ -0,0 +1,283 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("lily/query")
+
+const (
+	// DefaultPageSize is the number of rows returned by a request that does not specify a limit.
+	DefaultPageSize = 100
+	// DefaultMaxPageSize is the maximum number of rows returned by a single request when none is configured.
+	DefaultMaxPageSize = 1000
+)
+
+type ServerOpt func(s *Server)
+
+// WithMaxPageSize sets the maximum number of rows returned by a single request.
+func WithMaxPageSize(m int) ServerOpt {
+	return func(s *Server) {
+		if m > 0 {
+			s.maxPageSize = m
+		}
+	}
+}
+
+// Server is a read-only HTTP API serving paged queries over the tables of a lily database. It serves:
+//
+//	GET /api/v1/schema          the tables, columns and filters available to queries
+//	GET /api/v1/schema/{table}  the columns and filters of a single table
+//	GET /api/v1/tables/{table}  a page of rows from a table
+//
+// Rows may be filtered with the min_height, max_height, address, cid, deal_id and sector_id query parameters and paged
+// with limit and offset. Only tables and columns known to the schema are ever referenced by a query and all values are
+// checked against the type of their column and passed as query parameters.
+type Server struct {
+	db          *pg.DB
+	schema      *Schema
+	maxPageSize int
+	mux         *http.ServeMux
+}
+
+var _ http.Handler = (*Server)(nil)
+
+// NewServer returns a Server that queries `db`, which must hold the tables described by `schema`.
+func NewServer(db *pg.DB, schema *Schema, opts ...ServerOpt) *Server {
+	s := &Server{
+		db:          db,
+		schema:      schema,
+		maxPageSize: DefaultMaxPageSize,
+		mux:         http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.mux.HandleFunc("GET /api/v1/schema", s.handleSchema)
+	s.mux.HandleFunc("GET /api/v1/schema/{table}", s.handleTableSchema)
+	s.mux.HandleFunc("GET /api/v1/tables/{table}", s.handleRows)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Page is a page of rows returned by a table query.
+type Page struct {
+	Table  string                   `json:"table"`
+	Limit  int                      `json:"limit"`
+	Offset int                      `json:"offset"`
+	Rows   []map[string]interface{} `json:"rows"`
+	// NextOffset is the offset of the next page, it is omitted when there are no more rows.
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+func (s *Server) handleSchema(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.schema)
+}
+
+func (s *Server) handleTableSchema(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.schema.Table(r.PathValue("table"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown table %q", r.PathValue("table")))
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) handleRows(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.schema.Table(r.PathValue("table"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown table %q", r.PathValue("table")))
+		return
+	}
+
+	req, err := parseRequest(t, r.URL.Query(), s.maxPageSize)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := s.query(r.Context(), t, req)
+	if err != nil {
+		log.Errorw("query failed", "table", t.Name, "error", err)
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("query failed"))
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) query(ctx context.Context, t *Table, req *request) (*Page, error) {
+	// fetch one more row than requested to learn if there is a next page.
+	rows := []map[string]interface{}{}
+	q := s.db.ModelContext(ctx, &rows).Table(t.Name)
+	if req.minHeight != nil {
+		q = q.Where("? >= ?", pg.Ident(HeightColumn), *req.minHeight)
+	}
+	if req.maxHeight != nil {
+		q = q.Where("? <= ?", pg.Ident(HeightColumn), *req.maxHeight)
+	}
+	for _, f := range req.filters {
+		f := f
+		q = q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			for i, c := range f.columns {
+				q = q.WhereOr("? = ?", pg.Ident(c), f.values[i])
+			}
+			return q, nil
+		})
+	}
+	for _, pk := range t.primaryKeys {
+		q = q.OrderExpr("? ASC", pg.Ident(pk))
+	}
+	if err := q.Limit(req.limit + 1).Offset(req.offset).Select(); err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		Table:  t.Name,
+		Limit:  req.limit,
+		Offset: req.offset,
+		Rows:   rows,
+	}
+	if len(rows) > req.limit {
+		page.Rows = rows[:req.limit]
+		next := req.offset + req.limit
+		page.NextOffset = &next
+	}
+	return page, nil
+}
+
+// filter matches rows where any of its columns equals the value of the same index.
+type filter struct {
+	columns []string
+	values  []interface{}
+}
+
+type request struct {
+	minHeight *int64
+	maxHeight *int64
+	filters   []filter
+	limit     int
+	offset    int
+}
+
+// parseRequest validates the query parameters of a request for rows from `t`.
+func parseRequest(t *Table, values url.Values, maxPageSize int) (*request, error) {
+	req := &request{
+		limit: DefaultPageSize,
+	}
+	if req.limit > maxPageSize {
+		req.limit = maxPageSize
+	}
+
+	for key := range values {
+		switch key {
+		case "limit", "offset", "min_height", "max_height":
+		case FilterAddress, FilterCid, FilterDealID, FilterSectorID:
+			columns, ok := t.filterColumns[key]
+			if !ok {
+				return nil, fmt.Errorf("table %s does not support filter %s", t.Name, key)
+			}
+			f := filter{columns: columns}
+			for _, c := range columns {
+				v, err := columnValue(t.columnTypes[c], values.Get(key))
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s %q: %w", key, values.Get(key), err)
+				}
+				f.values = append(f.values, v)
+			}
+			req.filters = append(req.filters, f)
+		default:
+			return nil, fmt.Errorf("unknown query parameter %s", key)
+		}
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **int64
+	}{
+		{name: "min_height", dst: &req.minHeight},
+		{name: "max_height", dst: &req.maxHeight},
+	} {
+		if !values.Has(p.name) {
+			continue
+		}
+		if !t.hasHeight {
+			return nil, fmt.Errorf("table %s does not support filter %s", t.Name, p.name)
+		}
+		h, err := strconv.ParseInt(values.Get(p.name), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", p.name, err)
+		}
+		*p.dst = &h
+	}
+
+	if values.Has("limit") {
+		limit, err := strconv.Atoi(values.Get("limit"))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid limit %q: must be a positive integer", values.Get("limit"))
+		}
+		if limit > maxPageSize {
+			return nil, fmt.Errorf("invalid limit %d: must not exceed %d", limit, maxPageSize)
+		}
+		req.limit = limit
+	}
+	if values.Has("offset") {
+		offset, err := strconv.Atoi(values.Get("offset"))
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid offset %q: must be a non-negative integer", values.Get("offset"))
+		}
+		req.offset = offset
+	}
+
+	return req, nil
+}
+
+// columnValue parses `value` as a value of a column of SQL type `sqlType` so that values of the wrong type are rejected
+// before reaching the database.
+func columnValue(sqlType string, value string) (interface{}, error) {
+	switch sqlType {
+	case "smallint", "integer", "bigint":
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return v, nil
+	case "numeric":
+		if _, ok := new(big.Int).SetString(value, 10); !ok {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return value, nil
+	case "boolean":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warnw("failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
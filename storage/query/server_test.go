@@ -0,0 +1,145 @@
+package query
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/storage"
+)
+
+func TestNewSchema(t *testing.T) {
+	schema, err := NewSchema(storage.LatestSchemaVersion())
+	require.NoError(t, err)
+
+	messages, ok := schema.Table("messages")
+	require.True(t, ok)
+	assert.Equal(t, []string{"address", "cid", "max_height", "min_height"}, messages.Filters)
+	assert.Equal(t, []string{"from", "to"}, messages.filterColumns[FilterAddress])
+
+	deals, ok := schema.Table("miner_sector_deals")
+	require.True(t, ok)
+	assert.Contains(t, deals.Filters, FilterDealID)
+	assert.Contains(t, deals.Filters, FilterSectorID)
+
+	_, ok = schema.Table("no_such_table")
+	assert.False(t, ok)
+}
+
+func TestParseRequest(t *testing.T) {
+	schema, err := NewSchema(storage.LatestSchemaVersion())
+	require.NoError(t, err)
+	messages, ok := schema.Table("messages")
+	require.True(t, ok)
+
+	t.Run("defaults", func(t *testing.T) {
+		req, err := parseRequest(messages, url.Values{}, DefaultMaxPageSize)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPageSize, req.limit)
+		assert.Equal(t, 0, req.offset)
+		assert.Nil(t, req.minHeight)
+		assert.Nil(t, req.maxHeight)
+		assert.Empty(t, req.filters)
+	})
+
+	t.Run("filters and paging", func(t *testing.T) {
+		req, err := parseRequest(messages, url.Values{
+			"min_height": {"10"},
+			"max_height": {"20"},
+			"address":    {"f01000"},
+			"limit":      {"5"},
+			"offset":     {"15"},
+		}, DefaultMaxPageSize)
+		require.NoError(t, err)
+		require.NotNil(t, req.minHeight)
+		require.NotNil(t, req.maxHeight)
+		assert.EqualValues(t, 10, *req.minHeight)
+		assert.EqualValues(t, 20, *req.maxHeight)
+		require.Len(t, req.filters, 1)
+		assert.Equal(t, []string{"from", "to"}, req.filters[0].columns)
+		assert.Equal(t, []interface{}{"f01000", "f01000"}, req.filters[0].values)
+		assert.Equal(t, 5, req.limit)
+		assert.Equal(t, 15, req.offset)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		for _, values := range []url.Values{
+			{"deal_id": {"1"}},
+			{"unknown": {"1"}},
+			{"min_height": {"ten"}},
+			{"limit": {"0"}},
+			{"limit": {"1001"}},
+			{"offset": {"-1"}},
+		} {
+			_, err := parseRequest(messages, values, DefaultMaxPageSize)
+			assert.Error(t, err, values.Encode())
+		}
+	})
+}
+
+func TestFilterValueTypes(t *testing.T) {
+	schema, err := NewSchema(storage.LatestSchemaVersion())
+	require.NoError(t, err)
+	deals, ok := schema.Table("miner_sector_deals")
+	require.True(t, ok)
+
+	req, err := parseRequest(deals, url.Values{"deal_id": {"42"}, "sector_id": {"7"}}, DefaultMaxPageSize)
+	require.NoError(t, err)
+	require.Len(t, req.filters, 2)
+	for _, f := range req.filters {
+		switch f.columns[0] {
+		case "deal_id":
+			assert.Equal(t, []interface{}{int64(42)}, f.values)
+		case "sector_id":
+			assert.Equal(t, []interface{}{int64(7)}, f.values)
+		}
+	}
+
+	for _, values := range []url.Values{
+		{"deal_id": {"abc"}},
+		{"deal_id": {"1.5"}},
+		{"sector_id": {""}},
+		{"sector_id": {"99999999999999999999"}},
+	} {
+		_, err := parseRequest(deals, values, DefaultMaxPageSize)
+		assert.Error(t, err, values.Encode())
+	}
+
+	testCases := []struct {
+		sqlType string
+		value   string
+		valid   bool
+	}{
+		{sqlType: "text", value: "abc", valid: true},
+		{sqlType: "bigint", value: "-12", valid: true},
+		{sqlType: "bigint", value: "12a", valid: false},
+		{sqlType: "numeric", value: "123456789012345678901234567890", valid: true},
+		{sqlType: "numeric", value: "1e3", valid: false},
+		{sqlType: "boolean", value: "true", valid: true},
+		{sqlType: "boolean", value: "yes", valid: false},
+	}
+	for _, tc := range testCases {
+		_, err := columnValue(tc.sqlType, tc.value)
+		assert.Equal(t, tc.valid, err == nil, "%s %q", tc.sqlType, tc.value)
+	}
+}
+
+func TestServerRejectsInvalidFilterValues(t *testing.T) {
+	schema, err := NewSchema(storage.LatestSchemaVersion())
+	require.NoError(t, err)
+	// invalid requests are rejected before the database is queried.
+	s := NewServer(nil, schema)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tables/miner_sector_deals?deal_id=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `invalid deal_id \"abc\": must be an integer`)
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tables/no_such_table", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
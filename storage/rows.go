@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/lily/model"
+)
+
+// TableRows holds the rows of a single table, formatted as they would be written by CSVStorage.
+type TableRows struct {
+	Table   string
+	Columns []string
+	Rows    [][]string
+}
+
+// ModelRows formats the models held by `ps` as rows grouped by table without persisting them anywhere. Tables are
+// returned in order of name.
+func ModelRows(ctx context.Context, version model.Version, ps ...model.Persistable) ([]*TableRows, error) {
+	batch := &CSVBatch{
+		data:    map[string][][]string{},
+		version: version,
+	}
+
+	for _, p := range ps {
+		if err := p.Persist(ctx, batch, version); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]*TableRows, 0, len(batch.data))
+	for name, rows := range batch.data {
+		if len(rows) == 0 {
+			continue
+		}
+		t, ok := getCSVModelTableByName(name, version)
+		if !ok {
+			return nil, fmt.Errorf("unknown table name: %s", name)
+		}
+		out = append(out, &TableRows{
+			Table:   name,
+			Columns: t.columns,
+			Rows:    rows,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Table < out[j].Table
+	})
+	return out, nil
+}
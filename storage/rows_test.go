@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/blocks"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+)
+
+func TestModelRows(t *testing.T) {
+	ctx := context.Background()
+	headers := blocks.BlockHeaders{
+		{Height: 10, Cid: "blocka", Miner: "f01000", ParentWeight: "100", ParentBaseFee: "1", ParentStateRoot: "root", WinCount: 1, Timestamp: 1600000000},
+		{Height: 10, Cid: "blockb", Miner: "f01001", ParentWeight: "100", ParentBaseFee: "1", ParentStateRoot: "root", WinCount: 2, Timestamp: 1600000000},
+	}
+	report := &visormodel.ProcessingReport{Height: 10, StateRoot: "root", Reporter: "extract", Task: "block_header", Status: visormodel.ProcessingStatusOK}
+
+	tables, err := ModelRows(ctx, LatestSchemaVersion(), model.PersistableList{report, headers})
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+
+	// tables are ordered by name
+	require.Equal(t, "block_headers", tables[0].Table)
+	require.Equal(t, []string{"height", "cid", "miner", "parent_weight", "parent_base_fee", "parent_state_root", "win_count", "timestamp", "fork_signaling"}, tables[0].Columns)
+	require.Equal(t, [][]string{
+		{"10", "blocka", "f01000", "100", "1", "root", "1", "1600000000", "0"},
+		{"10", "blockb", "f01001", "100", "1", "root", "2", "1600000000", "0"},
+	}, tables[0].Rows)
+
+	require.Equal(t, "visor_processing_reports", tables[1].Table)
+	require.Len(t, tables[1].Rows, 1)
+	require.Len(t, tables[1].Rows[0], len(tables[1].Columns))
+	require.Equal(t, []string{"10", "root", "extract", "block_header"}, tables[1].Rows[0][:4])
+
+	// nothing to format
+	tables, err = ModelRows(ctx, LatestSchemaVersion())
+	require.NoError(t, err)
+	require.Empty(t, tables)
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3DefaultRegion = "us-east-1"
+	s3TimeFormat    = "20060102T150405Z"
+	s3DateFormat    = "20060102"
+)
+
+// An S3Location is a bucket and key prefix of an S3 compatible object store, parsed from a URL of the form
+// s3://bucket/prefix?endpoint=http://localhost:9000&region=us-east-1. The endpoint defaults to AWS S3 in the region,
+// objects are addressed in the path of the endpoint when it is set and in the host otherwise.
+type S3Location struct {
+	Bucket   string
+	Prefix   string
+	Endpoint string
+	Region   string
+}
+
+// IsS3URL returns true if `path` is the URL of an S3 compatible object store rather than a local path.
+func IsS3URL(path string) bool {
+	return strings.HasPrefix(path, "s3://")
+}
+
+func ParseS3URL(s string) (*S3Location, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse s3 url: %w", err)
+	}
+	if u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("s3 url must be of the form s3://bucket/prefix: %q", s)
+	}
+	loc := &S3Location{
+		Bucket:   u.Host,
+		Prefix:   strings.Trim(u.Path, "/"),
+		Endpoint: u.Query().Get("endpoint"),
+		Region:   u.Query().Get("region"),
+	}
+	if loc.Region == "" {
+		loc.Region = s3DefaultRegion
+	}
+	return loc, nil
+}
+
+// s3Client uploads objects to an S3 compatible object store, signing requests with AWS signature version 4.
+type s3Client struct {
+	loc          S3Location
+	endpoint     *url.URL
+	pathStyle    bool
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	http         *http.Client
+	now          func() time.Time
+}
+
+// newS3Client returns a client for the object store at `loc` using the credentials in the standard
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+func newS3Client(loc S3Location) (*s3Client, error) {
+	c := &s3Client{
+		loc:          loc,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		http:         &http.Client{Timeout: 5 * time.Minute},
+		now:          time.Now,
+	}
+	if c.accessKey == "" || c.secretKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to use s3 storage")
+	}
+
+	endpoint := loc.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", loc.Region)
+	} else {
+		c.pathStyle = true
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse s3 endpoint: %w", err)
+	}
+	c.endpoint = u
+	return c, nil
+}
+
+// PutObject uploads `body` as the object `key`, replacing any existing object with the same key.
+func (c *s3Client) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	u := *c.endpoint
+	if c.pathStyle {
+		u.Path = "/" + c.loc.Bucket + "/" + key
+	} else {
+		u.Host = c.loc.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", contentType)
+	if c.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.sessionToken)
+	}
+	sum := sha256.Sum256(body)
+	signS3Request(req, hex.EncodeToString(sum[:]), c.accessKey, c.secretKey, c.loc.Region, c.now())
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("put object %s: %w", key, err)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("put object %s: %s: %s", key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// signS3Request adds the headers of an AWS signature version 4 to a request to S3, signing the host, range,
+// content-type and x-amz-* headers of the request.
+func signS3Request(req *http.Request, payloadHash string, accessKey, secretKey, region string, t time.Time) {
+	t = t.UTC()
+	req.Header.Set("X-Amz-Date", t.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "range" || name == "content-type" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := t.Format(s3DateFormat) + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + t.Format(s3TimeFormat) + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{t.Format(s3DateFormat), region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data)) // nolint: errcheck
+	return h.Sum(nil)
+}
+
+// s3EscapePath escapes every byte of an object path other than unreserved characters and slashes.
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,420 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/lily/model"
+)
+
+const (
+	DefaultS3HeightRange = 2880
+	DefaultS3FlushAfter  = time.Minute
+)
+
+type S3CSVStorageOptions struct {
+	OmitHeader  bool
+	FilePattern string
+	// HeightRange is the number of epochs of data in each uploaded object.
+	HeightRange int64
+	// FlushAfter is how long a height range is buffered without being written to before it is uploaded.
+	FlushAfter time.Duration
+}
+
+// S3CSVStorage buffers models as CSV and uploads them to an S3 compatible object store as immutable objects, one per
+// table and range of heights. Once the objects of a range are uploaded a manifest listing them is uploaded alongside.
+//
+// Objects are written to {prefix}/{table}/{from}-{to}/{file}-{flush}.csv and manifests to
+// {prefix}/manifests/{from}-{to}/{jobname}-{flush}.json, where {file} is the storage's file pattern without its
+// extension and {flush} identifies the upload. A range is uploaded as soon as data of a later range is persisted, or of
+// an earlier range when walking backwards, or once it has not been written to for FlushAfter. Data persisted to a range after it is uploaded is
+// uploaded as further objects with a new manifest. Data still buffered when the job persisting to the storage stops is
+// flushed by the scheduler.
+type S3CSVStorage struct {
+	client   *s3Client
+	version  model.Version
+	opts     S3CSVStorageOptions
+	metadata Metadata
+
+	mu      sync.Mutex
+	ranges  map[int64]*s3Range // buffered data keyed by the lowest height of each range
+	last    int64              // highest height persisted, used for tables without a height column
+	lastErr error              // error of the last background upload, returned by the next PersistBatch
+}
+
+var (
+	_ StorageWithMetadata = (*S3CSVStorage)(nil)
+	_ Connector           = (*S3CSVStorage)(nil)
+)
+
+type s3Range struct {
+	from   int64
+	tables map[string]*s3TableBuffer
+	timer  *time.Timer
+	// uploaded lists the objects of the range that were uploaded without a manifest, they are listed by the next
+	// manifest uploaded for the range.
+	uploaded []*S3ManifestTable
+}
+
+type s3TableBuffer struct {
+	t        table
+	rows     [][]string
+	min, max int64
+}
+
+// An S3Manifest lists the objects uploaded for a range of heights.
+type S3Manifest struct {
+	JobName    string             `json:"job_name"`
+	From       int64              `json:"from"`
+	To         int64              `json:"to"`
+	MinHeight  int64              `json:"min_height"`
+	MaxHeight  int64              `json:"max_height"`
+	UploadedAt time.Time          `json:"uploaded_at"`
+	Tables     []*S3ManifestTable `json:"tables"`
+}
+
+type S3ManifestTable struct {
+	Table     string `json:"table"`
+	Key       string `json:"key"`
+	Rows      int    `json:"rows"`
+	MinHeight int64  `json:"min_height"`
+	MaxHeight int64  `json:"max_height"`
+}
+
+func NewS3CSVStorage(url string, version model.Version, opts S3CSVStorageOptions) (*S3CSVStorage, error) {
+	loc, err := ParseS3URL(url)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newS3Client(*loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.FilePattern == "" {
+		opts.FilePattern = DefaultFilePattern
+	}
+	if opts.HeightRange <= 0 {
+		opts.HeightRange = DefaultS3HeightRange
+	}
+	if opts.FlushAfter <= 0 {
+		opts.FlushAfter = DefaultS3FlushAfter
+	}
+
+	return &S3CSVStorage{
+		client:  client,
+		version: version,
+		opts:    opts,
+		ranges:  map[int64]*s3Range{},
+	}, nil
+}
+
+func NewS3CSVStorageLatest(url string, opts S3CSVStorageOptions) (*S3CSVStorage, error) {
+	return NewS3CSVStorage(url, LatestSchemaVersion(), opts)
+}
+
+// WithMetadata returns a storage with its own buffers that names the objects it uploads using the supplied metadata.
+func (s *S3CSVStorage) WithMetadata(md Metadata) model.Storage {
+	return &S3CSVStorage{
+		client:   s.client,
+		version:  s.version,
+		opts:     s.opts,
+		metadata: md,
+		ranges:   map[int64]*s3Range{},
+	}
+}
+
+func (s *S3CSVStorage) Connect(context.Context) error {
+	return nil
+}
+
+func (s *S3CSVStorage) IsConnected(context.Context) bool {
+	return true
+}
+
+// Close uploads all buffered data.
+func (s *S3CSVStorage) Close(ctx context.Context) error {
+	return s.Flush(ctx)
+}
+
+// PersistBatch buffers a batch of models and uploads the ranges of heights that are complete.
+func (s *S3CSVStorage) PersistBatch(ctx context.Context, ps ...model.Persistable) error {
+	batch := &CSVBatch{
+		data:    map[string][][]string{},
+		version: s.version,
+	}
+	for _, p := range ps {
+		if err := p.Persist(ctx, batch, s.version); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	if err := s.lastErr; err != nil {
+		s.lastErr = nil
+		s.mu.Unlock()
+		return fmt.Errorf("uploading buffered data: %w", err)
+	}
+
+	// rows of tables without a height are buffered with the highest height of the batch.
+	batchMin, batchMax := int64(-1), int64(-1)
+	heights := map[string][]int64{}
+	for name, rows := range batch.data {
+		t, ok := getCSVModelTableByName(name, s.version)
+		if !ok {
+			continue
+		}
+		col := columnIndex(t.columns, "height")
+		if col < 0 {
+			continue
+		}
+		hs := make([]int64, len(rows))
+		for i, row := range rows {
+			h, err := strconv.ParseInt(row[col], 10, 64)
+			if err != nil || h < 0 {
+				hs[i] = -1
+				continue
+			}
+			hs[i] = h
+			if batchMin < 0 || h < batchMin {
+				batchMin = h
+			}
+			batchMax = max(batchMax, h)
+		}
+		heights[name] = hs
+	}
+	if batchMax >= 0 {
+		s.last = max(s.last, batchMax)
+	} else {
+		batchMin, batchMax = s.last, s.last
+	}
+
+	for name, rows := range batch.data {
+		t, ok := getCSVModelTableByName(name, s.version)
+		if !ok {
+			log.Errorf("unknown table name: %s", name)
+			continue
+		}
+		for i, row := range rows {
+			h := batchMax
+			if hs, ok := heights[name]; ok && hs[i] >= 0 {
+				h = hs[i]
+			}
+			s.buffer(t, row, h)
+		}
+	}
+
+	// ranges are complete once data of another range follows them.
+	var complete []*s3Range
+	for from, r := range s.ranges {
+		if batchMax >= from+s.opts.HeightRange || batchMin < from {
+			complete = append(complete, r)
+			s.detach(r)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, r := range complete {
+		if err := s.upload(ctx, r); err != nil {
+			s.restore(r)
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush uploads all buffered data.
+func (s *S3CSVStorage) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	ranges := make([]*s3Range, 0, len(s.ranges))
+	for _, r := range s.ranges {
+		ranges = append(ranges, r)
+		s.detach(r)
+	}
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, r := range ranges {
+		if err := s.upload(ctx, r); err != nil {
+			s.restore(r)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// buffer adds a row of table `t` at height `h` to the buffer of its range. It must be called with the lock held.
+func (s *S3CSVStorage) buffer(t table, row []string, h int64) {
+	r := s.bufferRange(h - h%s.opts.HeightRange)
+	tb, ok := r.tables[t.name]
+	if !ok {
+		tb = &s3TableBuffer{t: t, min: h, max: h}
+		r.tables[t.name] = tb
+	}
+	tb.rows = append(tb.rows, row)
+	tb.min = min(tb.min, h)
+	tb.max = max(tb.max, h)
+}
+
+// bufferRange returns the buffer of the range starting at `from`, creating it if needed, and restarts its idle timer.
+// It must be called with the lock held.
+func (s *S3CSVStorage) bufferRange(from int64) *s3Range {
+	r, ok := s.ranges[from]
+	if !ok {
+		r = &s3Range{from: from, tables: map[string]*s3TableBuffer{}}
+		s.ranges[from] = r
+	}
+	if r.timer == nil {
+		r.timer = time.AfterFunc(s.opts.FlushAfter, func() { s.flushIdle(r) })
+	} else {
+		r.timer.Reset(s.opts.FlushAfter)
+	}
+	return r
+}
+
+// detach removes a range from the buffered ranges so that it can be uploaded. It must be called with the lock held.
+func (s *S3CSVStorage) detach(r *s3Range) {
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	delete(s.ranges, r.from)
+}
+
+// flushIdle uploads a range that has not been written to for FlushAfter.
+func (s *S3CSVStorage) flushIdle(r *s3Range) {
+	s.mu.Lock()
+	if s.ranges[r.from] != r {
+		// the range has already been uploaded
+		s.mu.Unlock()
+		return
+	}
+	s.detach(r)
+	s.mu.Unlock()
+
+	if err := s.upload(context.Background(), r); err != nil {
+		log.Errorw("failed to upload buffered data", "from", r.from, "error", err)
+		s.restore(r)
+		s.mu.Lock()
+		s.lastErr = err
+		s.mu.Unlock()
+	}
+}
+
+// restore buffers the data of a range that failed to upload so that its upload is retried. Only the tables that were
+// not uploaded are buffered again, the objects that were uploaded are listed by the manifest of the retry.
+func (s *S3CSVStorage) restore(r *s3Range) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur := s.bufferRange(r.from)
+	cur.uploaded = append(cur.uploaded, r.uploaded...)
+	for _, tb := range r.tables {
+		for _, row := range tb.rows {
+			// rows are restored at the bounds of their table since the height of each row is not retained.
+			s.buffer(tb.t, row, tb.min)
+		}
+		if ctb := cur.tables[tb.t.name]; ctb.max < tb.max {
+			ctb.max = tb.max
+		}
+	}
+}
+
+// upload uploads an object for each table of a range followed by the manifest of the range. Tables are removed from the
+// range as they are uploaded so that when an upload fails the range holds only the data that remains to be uploaded.
+func (s *S3CSVStorage) upload(ctx context.Context, r *s3Range) error {
+	jobName := s.metadata.JobName
+	if jobName == "" {
+		jobName = "lily"
+	}
+	now := time.Now().UTC()
+	flush := strconv.FormatInt(now.UnixMilli(), 10)
+	rangeDir := fmt.Sprintf("%d-%d", r.from, r.from+s.opts.HeightRange-1)
+
+	names := make([]string, 0, len(r.tables))
+	for name := range r.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		tb := r.tables[name]
+
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if !s.opts.OmitHeader {
+			if err := w.Write(tb.t.columns); err != nil {
+				return err
+			}
+		}
+		if err := w.WriteAll(tb.rows); err != nil {
+			return err
+		}
+
+		file := strings.NewReplacer(
+			FilePatternTokenTable, name,
+			FilePatternTokenJobName, jobName,
+		).Replace(s.opts.FilePattern)
+		file = strings.TrimSuffix(path.Base(file), path.Ext(file))
+		key := path.Join(s.client.loc.Prefix, name, rangeDir, file+"-"+flush+".csv")
+
+		if err := s.client.PutObject(ctx, key, buf.Bytes(), "text/csv"); err != nil {
+			return err
+		}
+
+		r.uploaded = append(r.uploaded, &S3ManifestTable{
+			Table:     name,
+			Key:       key,
+			Rows:      len(tb.rows),
+			MinHeight: tb.min,
+			MaxHeight: tb.max,
+		})
+		delete(r.tables, name)
+	}
+
+	manifest := &S3Manifest{
+		JobName:    jobName,
+		From:       r.from,
+		To:         r.from + s.opts.HeightRange - 1,
+		MinHeight:  -1,
+		UploadedAt: now,
+		Tables:     r.uploaded,
+	}
+	sort.Slice(manifest.Tables, func(i, j int) bool { return manifest.Tables[i].Key < manifest.Tables[j].Key })
+	for _, mt := range manifest.Tables {
+		if manifest.MinHeight < 0 || mt.MinHeight < manifest.MinHeight {
+			manifest.MinHeight = mt.MinHeight
+		}
+		manifest.MaxHeight = max(manifest.MaxHeight, mt.MaxHeight)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	key := path.Join(s.client.loc.Prefix, "manifests", rangeDir, jobName+"-"+flush+".json")
+	if err := s.client.PutObject(ctx, key, data, "application/json"); err != nil {
+		return err
+	}
+	r.uploaded = nil
+	log.Debugw("uploaded height range", "from", manifest.From, "to", manifest.To, "tables", len(manifest.Tables), "manifest", key)
+	return nil
+}
+
+func columnIndex(columns []string, name string) int {
+	for i, c := range columns {
+		if c == name {
+			return i
+		}
+	}
+	return -1
+}
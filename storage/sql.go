@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"reflect"
 	"sort"
 	"strings"
@@ -108,6 +109,22 @@ var Models = []interface{}{
 	(*actordumps.FEVMActorDump)(nil),
 	(*actordumps.MinerActorDump)(nil),
 	(*builtinactor.BuiltInActorEvent)(nil),
+	(*derived.FilLedgerEntry)(nil),
+	(*derived.MinerBlockReward)(nil),
+	(*derived.GasFeePercentiles)(nil),
+	(*actordumps.MarketDealDump)(nil),
+	(*actordumps.PowerClaimDump)(nil),
+	(*actordumps.MultisigActorDump)(nil),
+	(*actordumps.VerifiedRegistryDump)(nil),
+	(*actordumps.DataCapBalanceDump)(nil),
+	(*builtinactor.DDOPiece)(nil),
+	(*builtinactor.VerifierBalanceEvent)(nil),
+	(*builtinactor.AllocationEvent)(nil),
+	(*builtinactor.ClaimEvent)(nil),
+	(*builtinactor.DealEvent)(nil),
+	(*builtinactor.SectorEvent)(nil),
+	(*chain.ChainEconomicsProjection)(nil),
+	(*multisig.MultisigInfo)(nil),
 }
 
 var log = logging.Logger("lily/storage")
@@ -117,6 +134,15 @@ var (
 	SchemaLock AdvisoryLock = 1
 )
 
+// PruneLock returns the lock of `table` held shared by transactions persisting data to the table and exclusively by
+// transactions pruning data from it. The lock is derived from a hash of the table name so pruning a table does not
+// block writers of other tables.
+func PruneLock(table string) AdvisoryLock {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("prune:" + table))
+	return AdvisoryLock(h.Sum64())
+}
+
 var (
 	ErrSchemaTooOld = errors.New("database schema is too old and requires migration")
 	ErrSchemaTooNew = errors.New("database schema is too new for this version of lily")
@@ -165,8 +191,9 @@ func NewDatabase(_ context.Context, url string, poolSize int, name string, schem
 		schemaConfig: schemas.Config{
 			SchemaName: schemaName,
 		},
-		Clock:  clock.New(),
-		Upsert: upsert,
+		Clock:      clock.New(),
+		Upsert:     upsert,
+		partitions: &partitioner{ahead: DefaultPartitionsAhead},
 	}, nil
 }
 
@@ -196,7 +223,10 @@ type Database struct {
 	schemaConfig schemas.Config
 	Clock        clock.Clock
 	Upsert       bool
+	BulkCopy     bool          // persist models with COPY rather than INSERT
 	version      model.Version // schema version identified in the database
+	partitions   *partitioner  // creates partitions of partitioned tables, nil if partitions are not created
+	retention    map[string]int64
 }
 
 // Connect opens a connection to the database and checks that the schema is compatible with the version required
@@ -368,15 +398,7 @@ func verifyModel(ctx context.Context, db *pg.DB, schemaName string, m *orm.Table
 			}
 		}
 
-		// Some common aliases
-		switch datatype {
-		case "timestamp with time zone":
-			fallthrough
-		case "timestamp without time zone":
-			datatype = "timestamptz"
-		case "ARRAY":
-			datatype = "bigint[]"
-		}
+		datatype = normalizeDataType(datatype)
 
 		if datatype != fld.SQLType {
 			return fmt.Errorf("column %s.%s had datatype %s, expected %s", tableName, fld.SQLName, datatype, fld.SQLType)
@@ -387,6 +409,19 @@ func verifyModel(ctx context.Context, db *pg.DB, schemaName string, m *orm.Table
 	return nil
 }
 
+// normalizeDataType maps common aliases of a data type reported by information_schema to the SQL type used by models.
+func normalizeDataType(datatype string) string {
+	switch datatype {
+	case "timestamp with time zone":
+		fallthrough
+	case "timestamp without time zone":
+		return "timestamptz"
+	case "ARRAY":
+		return "bigint[]"
+	}
+	return datatype
+}
+
 func tableExists(ctx context.Context, db *pg.DB, schemaName string, tableName string) (bool, error) {
 	var exists bool
 	_, err := db.QueryOneContext(ctx, pg.Scan(&exists), `SELECT EXISTS (SELECT FROM information_schema.tables WHERE table_schema=? AND table_name=?)`, schemaName, tableName)
@@ -405,8 +440,10 @@ func stripQuotes(s types.Safe) string {
 func (d *Database) PersistBatch(ctx context.Context, ps ...model.Persistable) error {
 	return d.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
 		txs := &TxStorage{
-			tx:     tx,
-			upsert: d.Upsert,
+			tx:        tx,
+			upsert:    d.Upsert,
+			bulkCopy:  d.BulkCopy,
+			retention: d.retention,
 		}
 
 		for _, p := range ps {
@@ -419,17 +456,52 @@ func (d *Database) PersistBatch(ctx context.Context, ps ...model.Persistable) er
 	})
 }
 
+// lockPrunableTable takes the shared prune lock of the table of model `m` for the remainder of the transaction if the
+// table has a retention, and so may be pruned, and its lock is not already held.
+func (s *TxStorage) lockPrunableTable(ctx context.Context, m interface{}) error {
+	if len(s.retention) == 0 {
+		return nil
+	}
+	typ := reflect.TypeOf(m)
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	table := stripQuotes(orm.GetTable(typ).SQLNameForSelects)
+	if _, ok := s.retention[table]; !ok || s.locked[table] {
+		return nil
+	}
+	if err := PruneLock(table).LockSharedTx(ctx, s.tx); err != nil {
+		return fmt.Errorf("locking %s: %w", table, err)
+	}
+	if s.locked == nil {
+		s.locked = make(map[string]bool)
+	}
+	s.locked[table] = true
+	return nil
+}
+
 func (d *Database) ExecContext(c context.Context, query interface{}, params ...interface{}) (pg.Result, error) {
 	return d.db.ExecContext(c, query, params...)
 }
 
 type TxStorage struct {
-	tx     *pg.Tx
-	upsert bool
+	tx        *pg.Tx
+	upsert    bool
+	bulkCopy  bool
+	locked    map[string]bool  // tables whose prune lock is held by tx
+	retention map[string]int64 // retention of the tables that may be pruned, keyed by table name
 }
 
 // PersistModel persists a single model
 func (s *TxStorage) PersistModel(ctx context.Context, m interface{}) error {
+	// prevent data being pruned from the table while it is written
+	if err := s.lockPrunableTable(ctx, m); err != nil {
+		return err
+	}
+
 	value := reflect.ValueOf(m)
 
 	elemKind := value.Kind()
@@ -452,6 +524,13 @@ func (s *TxStorage) PersistModel(ctx context.Context, m interface{}) error {
 
 	}
 
+	if s.bulkCopy {
+		if err := copyModel(ctx, s.tx, reflect.ValueOf(m), s.upsert); err != nil {
+			return fmt.Errorf("copying model: %w", err)
+		}
+		return nil
+	}
+
 	// Prepare the conflict and upsert sql string
 	conflict := ""
 	upsert := ""
@@ -0,0 +1,340 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/schemas"
+)
+
+// Kinds of difference between a model and the database table it is persisted to.
+const (
+	DiffMissingTable       = "missing_table"
+	DiffMissingColumn      = "missing_column"
+	DiffTypeMismatch       = "type_mismatch"
+	DiffMissingPrimaryKey  = "missing_primary_key"
+	DiffPrimaryKeyMismatch = "primary_key_mismatch"
+	DiffExtraColumn        = "extra_column"
+)
+
+// A SchemaReport is the difference between the models at the installed schema version and the live database.
+type SchemaReport struct {
+	Version model.Version  `json:"version"`
+	Tables  []*TableReport `json:"tables"`
+}
+
+// Valid returns true if no table of the report differs from its model.
+func (r *SchemaReport) Valid() bool {
+	for _, t := range r.Tables {
+		if len(t.Diffs) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SQL returns the statements that reconcile the database with the models, in table order.
+func (r *SchemaReport) SQL() []string {
+	var out []string
+	for _, t := range r.Tables {
+		for _, d := range t.Diffs {
+			if d.SQL != "" {
+				out = append(out, d.SQL)
+			}
+		}
+	}
+	return out
+}
+
+// A TableReport lists the differences between a model and its table.
+type TableReport struct {
+	Table string       `json:"table"`
+	Model string       `json:"model"`
+	Diffs []*TableDiff `json:"diffs"`
+}
+
+// A TableDiff is a single difference between a model and its table, with the SQL that reconciles it.
+type TableDiff struct {
+	Kind string `json:"kind"`
+	// Column is the column that differs, empty for differences of the whole table.
+	Column   string `json:"column,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	SQL      string `json:"sql,omitempty"`
+}
+
+// SchemaReport compares the schema present in the database with the models used by visor at the installed schema
+// version and reports every difference between them.
+func (d *Database) SchemaReport(ctx context.Context) (*SchemaReport, error) {
+	// If we're already connected then use that connection
+	if d.db != nil {
+		return schemaReport(ctx, d.db, d.SchemaConfig())
+	}
+
+	// Temporarily connect
+	db, err := connect(ctx, d.opt)
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	defer db.Close() // nolint: errcheck
+	return schemaReport(ctx, db, d.SchemaConfig())
+}
+
+func schemaReport(ctx context.Context, db *pg.DB, cfg schemas.Config) (*SchemaReport, error) {
+	type versionable interface {
+		AsVersion(model.Version) (interface{}, bool)
+	}
+
+	version, initialized, err := getDatabaseSchemaVersion(ctx, db, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("get schema version: %w", err)
+	}
+	if !initialized {
+		return nil, fmt.Errorf("schema not installed in database")
+	}
+
+	installed, err := tablesForVersion(version, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("tables of schema version %s: %w", version, err)
+	}
+
+	report := &SchemaReport{Version: version}
+	for _, m := range Models {
+		if vm, ok := m.(versionable); ok {
+			vm, ok := vm.AsVersion(version)
+			if !ok {
+				return nil, fmt.Errorf("model %T does not support version %s", m, version)
+			}
+			m = vm
+		}
+
+		// models of tables added by patches newer than the installed version are not expected to be present.
+		table := db.Model(m).TableModel().Table()
+		if !installed[stripQuotes(table.SQLNameForSelects)] {
+			continue
+		}
+
+		tr, err := tableReport(ctx, db, cfg.SchemaName, table)
+		if err != nil {
+			return nil, fmt.Errorf("verify %T: %w", m, err)
+		}
+		tr.Model = fmt.Sprintf("%T", m)
+		report.Tables = append(report.Tables, tr)
+	}
+	return report, nil
+}
+
+func tableReport(ctx context.Context, db *pg.DB, schemaName string, m *orm.Table) (*TableReport, error) {
+	tableName := stripQuotes(m.SQLNameForSelects)
+	qualified := schemaName + "." + string(m.SQLName)
+	tr := &TableReport{Table: tableName}
+
+	var pks, pkCols []string
+	for _, pk := range m.PKs {
+		pks = append(pks, pk.SQLName)
+		pkCols = append(pkCols, string(pk.Column))
+	}
+
+	exists, err := tableExists(ctx, db, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		tr.Diffs = append(tr.Diffs, &TableDiff{
+			Kind: DiffMissingTable,
+			SQL:  createTableSQL(qualified, m),
+		})
+		return tr, nil
+	}
+
+	var columns []column
+	if _, err := db.QueryContext(ctx, &columns, `SELECT column_name, data_type, udt_name FROM information_schema.columns WHERE table_schema=? AND table_name=? ORDER BY ordinal_position`, schemaName, tableName); err != nil {
+		return nil, fmt.Errorf("querying columns: %w", err)
+	}
+	tr.Diffs = append(tr.Diffs, columnDiffs(qualified, m, columns)...)
+
+	if len(pks) == 0 {
+		return tr, nil
+	}
+
+	var constraint []struct {
+		ConstraintName string
+		ColumnName     string
+	}
+	if _, err := db.QueryContext(ctx, &constraint, `
+		SELECT tc.constraint_name, kcu.column_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+		  ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema AND kcu.table_name = tc.table_name
+		WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema=? AND tc.table_name=?
+		ORDER BY kcu.ordinal_position`, schemaName, tableName); err != nil {
+		return nil, fmt.Errorf("querying primary key: %w", err)
+	}
+	var actualPKs []string
+	for _, c := range constraint {
+		actualPKs = append(actualPKs, c.ColumnName)
+	}
+
+	switch {
+	case len(actualPKs) == 0:
+		tr.Diffs = append(tr.Diffs, &TableDiff{
+			Kind:     DiffMissingPrimaryKey,
+			Expected: strings.Join(pks, ","),
+			SQL:      fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s);", qualified, strings.Join(pkCols, ", ")),
+		})
+	case strings.Join(actualPKs, ",") != strings.Join(pks, ","):
+		tr.Diffs = append(tr.Diffs, &TableDiff{
+			Kind:     DiffPrimaryKeyMismatch,
+			Expected: strings.Join(pks, ","),
+			Actual:   strings.Join(actualPKs, ","),
+			SQL:      fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s, ADD PRIMARY KEY (%s);", qualified, quoteIdent(constraint[0].ConstraintName), strings.Join(pkCols, ", ")),
+		})
+	}
+	return tr, nil
+}
+
+// A column of a table as reported by information_schema.columns.
+type column struct {
+	ColumnName string
+	DataType   string
+	UdtName    string
+}
+
+// columnDiffs returns the differences between the fields of model `m` and the `columns` of its table, in field order
+// followed by the columns the model does not have.
+func columnDiffs(qualified string, m *orm.Table, columns []column) []*TableDiff {
+	var diffs []*TableDiff
+	actual := map[string]string{}
+	for _, c := range columns {
+		datatype := c.DataType
+		if datatype == "USER-DEFINED" {
+			datatype = c.UdtName
+		}
+		actual[c.ColumnName] = normalizeDataType(datatype)
+	}
+
+	expected := map[string]bool{}
+	for _, fld := range m.Fields {
+		expected[fld.SQLName] = true
+		datatype, ok := actual[fld.SQLName]
+		if !ok {
+			diffs = append(diffs, &TableDiff{
+				Kind:     DiffMissingColumn,
+				Column:   fld.SQLName,
+				Expected: fld.SQLType,
+				SQL:      fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", qualified, fld.Column, fld.SQLType),
+			})
+			continue
+		}
+		if datatype != fld.SQLType {
+			diffs = append(diffs, &TableDiff{
+				Kind:     DiffTypeMismatch,
+				Column:   fld.SQLName,
+				Expected: fld.SQLType,
+				Actual:   datatype,
+				SQL:      fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;", qualified, fld.Column, fld.SQLType, fld.Column, fld.SQLType),
+			})
+		}
+	}
+	for _, c := range columns {
+		if !expected[c.ColumnName] {
+			diffs = append(diffs, &TableDiff{
+				Kind:   DiffExtraColumn,
+				Column: c.ColumnName,
+				Actual: actual[c.ColumnName],
+				SQL:    fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", qualified, quoteIdent(c.ColumnName)),
+			})
+		}
+	}
+	return diffs
+}
+
+var (
+	createTableRe = regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:"?\w+"?\.)?"?(\w+)"?`)
+	renameTableRe = regexp.MustCompile(`(?i)ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(?:"?\w+"?\.)?"?(\w+)"?\s+RENAME\s+TO\s+"?(\w+)"?`)
+	dropTableRe   = regexp.MustCompile(`(?i)DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:"?\w+"?\.)?"?(\w+)"?`)
+)
+
+// tablesForVersion returns the names of the tables created by the base schema and the patches up to and including
+// `version`.
+func tablesForVersion(version model.Version, cfg schemas.Config) (map[string]bool, error) {
+	base, err := baseForVersion(version, cfg)
+	if err != nil {
+		return nil, err
+	}
+	tables := map[string]bool{}
+	applySchemaTables(tables, base)
+	for seq := 1; seq <= version.Patch; seq++ {
+		sql, err := patchSQLForVersion(version, cfg, seq)
+		if err != nil {
+			return nil, err
+		}
+		applySchemaTables(tables, sql)
+	}
+	return tables, nil
+}
+
+// applySchemaTables updates `tables` with the tables created, renamed and dropped by `sql`, in statement order.
+func applySchemaTables(tables map[string]bool, sql string) {
+	type change struct {
+		pos   int
+		apply func()
+	}
+	var changes []change
+	for _, m := range createTableRe.FindAllStringSubmatchIndex(sql, -1) {
+		name := sql[m[2]:m[3]]
+		changes = append(changes, change{pos: m[0], apply: func() { tables[name] = true }})
+	}
+	for _, m := range renameTableRe.FindAllStringSubmatchIndex(sql, -1) {
+		from, to := sql[m[2]:m[3]], sql[m[4]:m[5]]
+		changes = append(changes, change{pos: m[0], apply: func() {
+			if tables[from] {
+				delete(tables, from)
+				tables[to] = true
+			}
+		}})
+	}
+	for _, m := range dropTableRe.FindAllStringSubmatchIndex(sql, -1) {
+		name := sql[m[2]:m[3]]
+		changes = append(changes, change{pos: m[0], apply: func() { delete(tables, name) }})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].pos < changes[j].pos })
+	for _, c := range changes {
+		c.apply()
+	}
+}
+
+// createTableSQL returns a statement creating the table of model `m`. Only primary key columns are declared NOT NULL
+// since the nullability of other columns is not known from the model.
+func createTableSQL(qualified string, m *orm.Table) string {
+	isPK := map[string]bool{}
+	var pks []string
+	for _, pk := range m.PKs {
+		isPK[pk.SQLName] = true
+		pks = append(pks, string(pk.Column))
+	}
+
+	var cols []string
+	for _, fld := range m.Fields {
+		col := fmt.Sprintf("\t%s %s", fld.Column, fld.SQLType)
+		if isPK[fld.SQLName] {
+			col += " NOT NULL"
+		}
+		cols = append(cols, col)
+	}
+	if len(pks) > 0 {
+		cols = append(cols, fmt.Sprintf("\tPRIMARY KEY (%s)", strings.Join(pks, ", ")))
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n);", qualified, strings.Join(cols, ",\n"))
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/schemas"
+)
+
+func TestNormalizeDataType(t *testing.T) {
+	testCases := []struct {
+		datatype string
+		expected string
+	}{
+		{datatype: "timestamp with time zone", expected: "timestamptz"},
+		{datatype: "timestamp without time zone", expected: "timestamptz"},
+		{datatype: "ARRAY", expected: "bigint[]"},
+		{datatype: "bigint", expected: "bigint"},
+		{datatype: "jsonb", expected: "jsonb"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.datatype, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizeDataType(tc.datatype))
+		})
+	}
+}
+
+type verifyTestModel struct {
+	tableName struct{} `pg:"verify_test"` // nolint: structcheck
+	Height    int64    `pg:",pk,notnull,use_zero"`
+	Cid       string   `pg:",pk,notnull"`
+	Value     string   `pg:"type:numeric,notnull"`
+	Added     int64    `pg:",use_zero"`
+}
+
+func TestColumnDiffs(t *testing.T) {
+	m := orm.GetTable(reflect.TypeOf(verifyTestModel{}))
+	const qualified = "lily.verify_test"
+
+	testCases := []struct {
+		name     string
+		columns  []column
+		expected []*TableDiff
+	}{
+		{
+			name: "matching",
+			columns: []column{
+				{ColumnName: "height", DataType: "bigint"},
+				{ColumnName: "cid", DataType: "text"},
+				{ColumnName: "value", DataType: "numeric"},
+				{ColumnName: "added", DataType: "bigint"},
+			},
+		},
+		{
+			name: "missing column",
+			columns: []column{
+				{ColumnName: "height", DataType: "bigint"},
+				{ColumnName: "cid", DataType: "text"},
+				{ColumnName: "value", DataType: "numeric"},
+			},
+			expected: []*TableDiff{
+				{Kind: DiffMissingColumn, Column: "added", Expected: "bigint", SQL: `ALTER TABLE lily.verify_test ADD COLUMN "added" bigint;`},
+			},
+		},
+		{
+			name: "type mismatch",
+			columns: []column{
+				{ColumnName: "height", DataType: "bigint"},
+				{ColumnName: "cid", DataType: "text"},
+				{ColumnName: "value", DataType: "text"},
+				{ColumnName: "added", DataType: "bigint"},
+			},
+			expected: []*TableDiff{
+				{Kind: DiffTypeMismatch, Column: "value", Expected: "numeric", Actual: "text", SQL: `ALTER TABLE lily.verify_test ALTER COLUMN "value" TYPE numeric USING "value"::numeric;`},
+			},
+		},
+		{
+			name: "user defined type",
+			columns: []column{
+				{ColumnName: "height", DataType: "bigint"},
+				{ColumnName: "cid", DataType: "text"},
+				{ColumnName: "value", DataType: "USER-DEFINED", UdtName: "numeric"},
+				{ColumnName: "added", DataType: "bigint"},
+			},
+		},
+		{
+			name: "extra column",
+			columns: []column{
+				{ColumnName: "height", DataType: "bigint"},
+				{ColumnName: "cid", DataType: "text"},
+				{ColumnName: "value", DataType: "numeric"},
+				{ColumnName: "added", DataType: "bigint"},
+				{ColumnName: "removed", DataType: "timestamp with time zone"},
+			},
+			expected: []*TableDiff{
+				{Kind: DiffExtraColumn, Column: "removed", Actual: "timestamptz", SQL: `ALTER TABLE lily.verify_test DROP COLUMN "removed";`},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, columnDiffs(qualified, m, tc.columns))
+		})
+	}
+}
+
+func TestTablesForVersion(t *testing.T) {
+	cfg := schemas.Config{SchemaName: "lily"}
+
+	// every model has a table at the latest version
+	latest, err := tablesForVersion(LatestSchemaVersion(), cfg)
+	require.NoError(t, err)
+	for _, m := range Models {
+		table := orm.GetTable(reflect.TypeOf(m).Elem())
+		assert.True(t, latest[stripQuotes(table.SQLNameForSelects)], "table of model %T", m)
+	}
+
+	// tables added by newer patches are not part of older versions
+	v53, err := tablesForVersion(model.Version{Major: 1, Patch: 53}, cfg)
+	require.NoError(t, err)
+	assert.False(t, v53["multisig_info"])
+	assert.True(t, v53["chain_economics_projections"])
+
+	// renamed tables are known by the name of the version
+	v25, err := tablesForVersion(model.Version{Major: 1, Patch: 25}, cfg)
+	require.NoError(t, err)
+	assert.True(t, v25["fevm_block_header"])
+	assert.False(t, v25["fevm_block_headers"])
+	v26, err := tablesForVersion(model.Version{Major: 1, Patch: 26}, cfg)
+	require.NoError(t, err)
+	assert.False(t, v26["fevm_block_header"])
+	assert.True(t, v26["fevm_block_headers"])
+}
@@ -0,0 +1,136 @@
+package multisig
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lily/chain/actors/builtin/multisig"
+	"github.com/filecoin-project/lily/model"
+	multisigmodel "github.com/filecoin-project/lily/model/actors/multisig"
+	"github.com/filecoin-project/lily/tasks/actorstate"
+)
+
+// InfoExtractor extracts the configuration of multisig actors when they are created and whenever their signers,
+// threshold or vesting parameters change.
+type InfoExtractor struct{}
+
+func (InfoExtractor) Extract(ctx context.Context, a actorstate.ActorInfo, node actorstate.ActorStateAPI) (model.Persistable, error) {
+	log.Debugw("extract", zap.String("extractor", "InfoExtractor"), zap.Inline(a))
+	ctx, span := otel.Tracer("").Start(ctx, "InfoExtractor.Extract")
+	defer span.End()
+	if span.IsRecording() {
+		span.SetAttributes(a.Attributes()...)
+	}
+
+	ec, err := NewMultiSigExtractionContext(ctx, a, node)
+	if err != nil {
+		return nil, err
+	}
+
+	curr, err := loadInfo(ec.CurrState)
+	if err != nil {
+		return nil, fmt.Errorf("loading multisig %s info: %w", a.Address, err)
+	}
+	if ec.HasPreviousState() {
+		prev, err := loadInfo(ec.PrevState)
+		if err != nil {
+			return nil, fmt.Errorf("loading previous multisig %s info: %w", a.Address, err)
+		}
+		if !prev.changed(curr) {
+			return nil, nil
+		}
+	}
+
+	locked, err := ec.CurrState.LockedBalance(ec.CurrTs.Height())
+	if err != nil {
+		return nil, fmt.Errorf("computing multisig %s locked balance: %w", a.Address, err)
+	}
+	unlocked := unlockedBalance(ec.CurrActor.Balance, locked)
+
+	return &multisigmodel.MultisigInfo{
+		Height:          int64(ec.CurrTs.Height()),
+		MultisigID:      a.Address.String(),
+		StateRoot:       a.Current.ParentState().String(),
+		Signers:         curr.signers,
+		Threshold:       curr.threshold,
+		StartEpoch:      int64(curr.startEpoch),
+		UnlockDuration:  int64(curr.unlockDuration),
+		InitialBalance:  curr.initialBalance.String(),
+		Balance:         ec.CurrActor.Balance.String(),
+		LockedBalance:   locked.String(),
+		UnlockedBalance: unlocked.String(),
+	}, nil
+}
+
+// unlockedBalance returns the part of `balance` that is not `locked`, which is zero when the balance is below the
+// locked amount.
+func unlockedBalance(balance, locked abi.TokenAmount) abi.TokenAmount {
+	return big.Max(big.Sub(balance, locked), big.Zero())
+}
+
+// info holds the parts of a multisig state recorded by the InfoExtractor.
+type info struct {
+	signers        []string
+	threshold      uint64
+	startEpoch     abi.ChainEpoch
+	unlockDuration abi.ChainEpoch
+	initialBalance abi.TokenAmount
+}
+
+func loadInfo(s multisig.State) (*info, error) {
+	signers, err := s.Signers()
+	if err != nil {
+		return nil, fmt.Errorf("getting signers: %w", err)
+	}
+	threshold, err := s.Threshold()
+	if err != nil {
+		return nil, fmt.Errorf("getting threshold: %w", err)
+	}
+	startEpoch, err := s.StartEpoch()
+	if err != nil {
+		return nil, fmt.Errorf("getting start epoch: %w", err)
+	}
+	unlockDuration, err := s.UnlockDuration()
+	if err != nil {
+		return nil, fmt.Errorf("getting unlock duration: %w", err)
+	}
+	initialBalance, err := s.InitialBalance()
+	if err != nil {
+		return nil, fmt.Errorf("getting initial balance: %w", err)
+	}
+
+	out := &info{
+		signers:        make([]string, len(signers)),
+		threshold:      threshold,
+		startEpoch:     startEpoch,
+		unlockDuration: unlockDuration,
+		initialBalance: initialBalance,
+	}
+	for i, signer := range signers {
+		out.signers[i] = signer.String()
+	}
+	return out, nil
+}
+
+// changed returns true when the signers, threshold or vesting parameters of `other` differ from those of `i`. The
+// order of the signers is significant as it is the order of the signers in the state.
+func (i *info) changed(other *info) bool {
+	if len(i.signers) != len(other.signers) {
+		return true
+	}
+	for idx := range i.signers {
+		if i.signers[idx] != other.signers[idx] {
+			return true
+		}
+	}
+	return i.threshold != other.threshold ||
+		i.startEpoch != other.startEpoch ||
+		i.unlockDuration != other.unlockDuration ||
+		!i.initialBalance.Equals(other.initialBalance)
+}
@@ -0,0 +1,64 @@
+package multisig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+func testInfo() *info {
+	return &info{
+		signers:        []string{"f0100", "f0101", "f0102"},
+		threshold:      2,
+		startEpoch:     1000,
+		unlockDuration: 2880,
+		initialBalance: abi.NewTokenAmount(5000),
+	}
+}
+
+func TestInfoChanged(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modify  func(i *info)
+		changed bool
+	}{
+		{name: "unchanged", modify: func(i *info) {}, changed: false},
+		{name: "signer order", modify: func(i *info) { i.signers = []string{"f0101", "f0100", "f0102"} }, changed: true},
+		{name: "signer replaced", modify: func(i *info) { i.signers[2] = "f0103" }, changed: true},
+		{name: "signer added", modify: func(i *info) { i.signers = append(i.signers, "f0103") }, changed: true},
+		{name: "signer removed", modify: func(i *info) { i.signers = i.signers[:2] }, changed: true},
+		{name: "threshold", modify: func(i *info) { i.threshold = 3 }, changed: true},
+		{name: "start epoch", modify: func(i *info) { i.startEpoch = 2000 }, changed: true},
+		{name: "unlock duration", modify: func(i *info) { i.unlockDuration = 5760 }, changed: true},
+		{name: "initial balance", modify: func(i *info) { i.initialBalance = abi.NewTokenAmount(6000) }, changed: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prev, curr := testInfo(), testInfo()
+			tc.modify(curr)
+			assert.Equal(t, tc.changed, prev.changed(curr))
+			assert.Equal(t, tc.changed, curr.changed(prev))
+		})
+	}
+}
+
+func TestUnlockedBalance(t *testing.T) {
+	testCases := []struct {
+		name     string
+		balance  int64
+		locked   int64
+		unlocked int64
+	}{
+		{name: "fully locked", balance: 5000, locked: 5000, unlocked: 0},
+		{name: "partially vested", balance: 5000, locked: 2000, unlocked: 3000},
+		{name: "fully vested", balance: 5000, locked: 0, unlocked: 5000},
+		{name: "balance below locked", balance: 1000, locked: 2000, unlocked: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, abi.NewTokenAmount(tc.unlocked).String(), unlockedBalance(abi.NewTokenAmount(tc.balance), abi.NewTokenAmount(tc.locked)).String())
+		})
+	}
+}
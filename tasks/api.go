@@ -47,7 +47,8 @@ type ActorStateChange struct {
 
 type ActorStateChangeDiff map[address.Address]ActorStateChange
 
-type ActorStatesByType map[string][]*types.ActorV5
+// ActorStatesByType maps the manifest key of an actor family to the states of its actors by ID address.
+type ActorStatesByType map[string]map[address.Address]*types.ActorV5
 
 type ActorInfo struct {
 	Actor       *types.Actor
@@ -0,0 +1,176 @@
+package economicsprojection
+
+import (
+	"context"
+	"fmt"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	reward15 "github.com/filecoin-project/go-state-types/builtin/v15/reward"
+	"github.com/filecoin-project/go-state-types/builtin/v15/util/math"
+
+	"github.com/filecoin-project/lily/chain/actors/builtin/power"
+	"github.com/filecoin-project/lily/chain/actors/builtin/reward"
+	"github.com/filecoin-project/lily/model"
+	chainmodel "github.com/filecoin-project/lily/model/chain"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/economicsprojection")
+
+// sectorQaPower is the quality adjusted power of a 32GiB sector without deals.
+var sectorQaPower = abi.NewStoragePower(int64(abi.SectorSize(32 << 30)))
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+func (p *Task) ProcessTipSet(ctx context.Context, ts *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSet")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("tipset", ts.Key().String()),
+			attribute.Int64("height", int64(ts.Height())),
+			attribute.String("processor", "chain_economics_projections"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(ts.Height()),
+		StateRoot: ts.ParentState().String(),
+	}
+
+	out, err := p.projection(ctx, ts)
+	if err != nil {
+		log.Errorw("projecting chain economics", "height", ts.Height(), "error", err)
+		report.ErrorsDetected = err
+		return nil, report, nil
+	}
+	return out, report, nil
+}
+
+func (p *Task) projection(ctx context.Context, ts *types.TipSet) (*chainmodel.ChainEconomicsProjection, error) {
+	rewardActor, err := p.node.Actor(ctx, reward.Address, ts.Key())
+	if err != nil {
+		return nil, fmt.Errorf("loading reward actor: %w", err)
+	}
+	rewardState, err := reward.Load(p.node.Store(), rewardActor)
+	if err != nil {
+		return nil, fmt.Errorf("loading reward actor state: %w", err)
+	}
+	powerActor, err := p.node.Actor(ctx, power.Address, ts.Key())
+	if err != nil {
+		return nil, fmt.Errorf("loading power actor: %w", err)
+	}
+	powerState, err := power.Load(p.node.Store(), powerActor)
+	if err != nil {
+		return nil, fmt.Errorf("loading power actor state: %w", err)
+	}
+
+	baselinePower, err := rewardState.ThisEpochBaselinePower()
+	if err != nil {
+		return nil, fmt.Errorf("getting baseline power: %w", err)
+	}
+	effectiveNetworkTime, err := rewardState.EffectiveNetworkTime()
+	if err != nil {
+		return nil, fmt.Errorf("getting effective network time: %w", err)
+	}
+	effectiveBaselinePower, err := rewardState.EffectiveBaselinePower()
+	if err != nil {
+		return nil, fmt.Errorf("getting effective baseline power: %w", err)
+	}
+	cumsumBaseline, err := rewardState.CumsumBaseline()
+	if err != nil {
+		return nil, fmt.Errorf("getting cumsum baseline: %w", err)
+	}
+	cumsumRealized, err := rewardState.CumsumRealized()
+	if err != nil {
+		return nil, fmt.Errorf("getting cumsum realized: %w", err)
+	}
+	thisEpochReward, err := rewardState.ThisEpochReward()
+	if err != nil {
+		return nil, fmt.Errorf("getting epoch reward: %w", err)
+	}
+
+	networkPower, err := powerState.TotalPower()
+	if err != nil {
+		return nil, fmt.Errorf("getting network power: %w", err)
+	}
+	pledgeCollateral, err := powerState.TotalLocked()
+	if err != nil {
+		return nil, fmt.Errorf("getting network pledge collateral: %w", err)
+	}
+	powerSmoothed, err := powerState.TotalPowerSmoothed()
+	if err != nil {
+		return nil, fmt.Errorf("getting smoothed network power: %w", err)
+	}
+
+	supply, err := p.node.CirculatingSupply(ctx, ts)
+	if err != nil {
+		return nil, fmt.Errorf("getting circulating supply: %w", err)
+	}
+
+	// the pledge ramp introduced by FIP-0081 only applies once the power actor has a ramp start epoch.
+	var (
+		epochsSinceRampStart int64
+		rampDurationEpochs   uint64
+	)
+	if powerState.RampStartEpoch() > 0 {
+		epochsSinceRampStart = int64(ts.Height()) - powerState.RampStartEpoch()
+		rampDurationEpochs = powerState.RampDurationEpochs()
+	}
+	pledge, err := rewardState.InitialPledgeForPower(sectorQaPower, pledgeCollateral, &powerSmoothed, supply.FilCirculating, epochsSinceRampStart, rampDurationEpochs)
+	if err != nil {
+		return nil, fmt.Errorf("computing sector initial pledge: %w", err)
+	}
+
+	theta := reward15.ComputeRTheta(effectiveNetworkTime, effectiveBaselinePower, cumsumRealized, cumsumBaseline)
+	simpleReward := simpleEpochReward(ts.Height())
+
+	return &chainmodel.ChainEconomicsProjection{
+		Height:               int64(ts.Height()),
+		StateRoot:            ts.ParentState().String(),
+		BaselinePower:        baselinePower.String(),
+		NetworkRawPower:      networkPower.RawBytePower.String(),
+		NetworkQaPower:       networkPower.QualityAdjPower.String(),
+		BaselineCrossed:      networkPower.RawBytePower.GreaterThanEqual(baselinePower),
+		EffectiveNetworkTime: int64(effectiveNetworkTime),
+		SimpleMinted:         mintedSupply(big.Lsh(big.NewInt(int64(ts.Height())), math.Precision128), reward15.DefaultSimpleTotal).String(),
+		BaselineMinted:       mintedSupply(theta, reward15.DefaultBaselineTotal).String(),
+		SimpleReward:         simpleReward.String(),
+		BaselineReward:       big.Max(big.Sub(thisEpochReward, simpleReward), big.Zero()).String(),
+		SectorInitialPledge:  pledge.String(),
+	}, nil
+}
+
+// mintedSupply returns the amount of `total` minted by an exponential decay schedule when the time of the schedule
+// is `theta` epochs, in Q.128 format.
+func mintedSupply(theta, total big.Int) abi.TokenAmount {
+	thetaLam := big.Rsh(big.Mul(theta, reward15.Lambda), math.Precision128) // Q.128
+	remaining := big.NewFromGo(math.ExpNeg(thetaLam.Int))                   // Q.128
+	one := big.Lsh(big.NewInt(1), math.Precision128)                        // Q.128
+	return big.Rsh(big.Mul(total, big.Sub(one, remaining)), math.Precision128)
+}
+
+// simpleEpochReward returns the part of the reward of `epoch` minted by the simple schedule, computed as the reward
+// actor does.
+func simpleEpochReward(epoch abi.ChainEpoch) abi.TokenAmount {
+	simple := big.Mul(reward15.DefaultSimpleTotal, reward15.ExpLamSubOne) // Q.128
+	epochLam := big.Mul(big.NewInt(int64(epoch)), reward15.Lambda)        // Q.128
+	simple = big.Mul(simple, big.NewFromGo(math.ExpNeg(epochLam.Int)))    // Q.256
+	return big.Rsh(simple, 2*math.Precision128)
+}
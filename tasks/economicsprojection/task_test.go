@@ -0,0 +1,39 @@
+package economicsprojection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	reward15 "github.com/filecoin-project/go-state-types/builtin/v15/reward"
+	"github.com/filecoin-project/go-state-types/builtin/v15/util/math"
+)
+
+func TestSimpleSchedule(t *testing.T) {
+	minted := func(epoch abi.ChainEpoch) abi.TokenAmount {
+		return mintedSupply(big.Lsh(big.NewInt(int64(epoch)), math.Precision128), reward15.DefaultSimpleTotal)
+	}
+
+	require.Equal(t, big.Zero(), minted(0))
+
+	// the simple rewards of the epochs add up to the amount minted by the simple schedule.
+	for _, epoch := range []abi.ChainEpoch{1, 1_000, 2_000_000} {
+		diff := big.Sub(big.Sub(minted(epoch), minted(epoch-1)), simpleEpochReward(epoch))
+		require.True(t, abs(diff).LessThan(big.NewInt(1_000)), "epoch %d: diff %s", epoch, diff)
+	}
+
+	// half of the simple total is minted after six years.
+	half := big.Div(reward15.DefaultSimpleTotal, big.NewInt(2))
+	sixYears := abi.ChainEpoch(6 * 365 * 2880)
+	diff := big.Sub(minted(sixYears), half)
+	require.True(t, abs(diff).LessThan(big.Div(half, big.NewInt(1_000_000))), "diff %s", diff)
+}
+
+func abs(x big.Int) big.Int {
+	if x.LessThan(big.Zero()) {
+		return big.Sub(big.Zero(), x)
+	}
+	return x
+}
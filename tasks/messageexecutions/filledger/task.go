@@ -0,0 +1,328 @@
+package filledger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/lens/util"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/derived"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/filledger")
+
+// BalanceMismatch is reported when the ledger entries of an actor do not sum to the change of its balance.
+type BalanceMismatch struct {
+	Address       string
+	BalanceChange string
+	LedgerChange  string
+}
+
+func (m *BalanceMismatch) Error() string {
+	return fmt.Sprintf("ledger of %s changed by %s but its balance changed by %s", m.Address, m.LedgerChange, m.BalanceChange)
+}
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{node: node}
+}
+
+func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "fil_ledger"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	grp, grpCtx := errgroup.WithContext(ctx)
+	var mex []*lens.MessageExecution
+	grp.Go(func() error {
+		var err error
+		mex, err = t.node.MessageExecutions(grpCtx, current, executed)
+		if err != nil {
+			return fmt.Errorf("getting messages executions for tipset: %w", err)
+		}
+		return nil
+	})
+
+	var changes tasks.ActorStateChangeDiff
+	grp.Go(func() error {
+		var err error
+		changes, err = t.node.ActorStateChanges(grpCtx, current, executed)
+		if err != nil {
+			return fmt.Errorf("getting actor state changes: %w", err)
+		}
+		return nil
+	})
+
+	var getActorCode func(ctx context.Context, a address.Address) (cid.Cid, bool)
+	grp.Go(func() error {
+		var err error
+		getActorCode, err = util.MakeGetActorCodeFunc(grpCtx, t.node.Store(), current, executed)
+		if err != nil {
+			return fmt.Errorf("failed to make actor code query function: %w", err)
+		}
+		return nil
+	})
+
+	var oldTree, newTree *state.StateTree
+	grp.Go(func() error {
+		var err error
+		oldTree, err = state.LoadStateTree(t.node.Store(), executed.ParentState())
+		if err != nil {
+			return fmt.Errorf("loading executed state tree: %w", err)
+		}
+		newTree, err = state.LoadStateTree(t.node.Store(), current.ParentState())
+		if err != nil {
+			return fmt.Errorf("loading current state tree: %w", err)
+		}
+		return nil
+	})
+
+	if err := grp.Wait(); err != nil {
+		report.ErrorsDetected = err
+		return nil, report, nil
+	}
+
+	l := &ledger{
+		height:    int64(executed.Height()),
+		stateRoot: executed.ParentState().String(),
+		// actors created while executing the tipset are only found in the new state tree.
+		resolve: func(a address.Address) (address.Address, error) {
+			if id, err := newTree.LookupIDAddress(a); err == nil {
+				return id, nil
+			}
+			return oldTree.LookupIDAddress(a)
+		},
+		family: func(a address.Address) string {
+			code, ok := getActorCode(ctx, a)
+			if !ok {
+				return ""
+			}
+			_, family, err := util.ActorNameAndFamilyFromCode(code)
+			if err != nil {
+				return ""
+			}
+			return family
+		},
+		net: map[address.Address]big.Int{},
+	}
+
+	for _, exec := range mex {
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("context done: %w", ctx.Err())
+		default:
+		}
+		if err := l.addExecution(exec); err != nil {
+			report.ErrorsDetected = fmt.Errorf("building ledger of message %s: %w", exec.Cid, err)
+			return nil, report, nil
+		}
+	}
+
+	balanceChanges := map[address.Address]big.Int{}
+	for addr, change := range changes {
+		after := change.Actor.Balance
+		if change.ChangeType == tasks.ChangeTypeRemove {
+			after = big.Zero()
+		}
+		before := big.Zero()
+		if act, err := oldTree.GetActor(addr); err == nil {
+			before = act.Balance
+		}
+		balanceChanges[addr] = big.Sub(after, before)
+	}
+
+	if mismatches := reconcile(l.net, balanceChanges); len(mismatches) > 0 {
+		log.Warnw("ledger does not reconcile with balance changes", "height", current.Height(), "mismatches", len(mismatches))
+		report.ErrorsDetected = mismatches
+	}
+
+	return l.entries, report, nil
+}
+
+// reconcile returns a mismatch for every address whose net ledger change differs from its balance change, ordered by
+// address.
+func reconcile(ledger, balances map[address.Address]big.Int) []*BalanceMismatch {
+	addrs := map[address.Address]struct{}{}
+	for a := range ledger {
+		addrs[a] = struct{}{}
+	}
+	for a := range balances {
+		addrs[a] = struct{}{}
+	}
+
+	var out []*BalanceMismatch
+	for a := range addrs {
+		lc, ok := ledger[a]
+		if !ok {
+			lc = big.Zero()
+		}
+		bc, ok := balances[a]
+		if !ok {
+			bc = big.Zero()
+		}
+		if !lc.Equals(bc) {
+			out = append(out, &BalanceMismatch{Address: a.String(), BalanceChange: bc.String(), LedgerChange: lc.String()})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
+
+// ledger accumulates the entries of the messages executed in a tipset.
+type ledger struct {
+	height    int64
+	stateRoot string
+	// resolve returns the ID address of an address.
+	resolve func(address.Address) (address.Address, error)
+	// family returns the actor family of an address, empty if it is not known.
+	family func(address.Address) string
+
+	entries derived.FilLedgerEntryList
+	// net is the sum of the entries of each ID address.
+	net map[address.Address]big.Int
+}
+
+// addExecution adds the entries of the value transfers of a message and of the gas it paid.
+func (l *ledger) addExecution(exec *lens.MessageExecution) error {
+	if exec.Ret == nil {
+		return nil
+	}
+	if err := l.addTrace(exec.Cid, &exec.Ret.ExecutionTrace, true); err != nil {
+		return err
+	}
+
+	// implicit messages don't pay for gas. The miner penalty is not paid by the sender, it is deducted from the block
+	// reward of the miner and appears in the trace of the reward message.
+	if exec.Implicit || exec.Ret.GasCosts == nil || exec.Message == nil {
+		return nil
+	}
+	gas := exec.Ret.GasCosts
+	for _, fee := range []struct {
+		to     address.Address
+		amount abi.TokenAmount
+		reason string
+	}{
+		{builtin.BurntFundsActorAddr, gas.BaseFeeBurn, derived.LedgerReasonBaseFeeBurn},
+		{builtin.BurntFundsActorAddr, gas.OverEstimationBurn, derived.LedgerReasonOverEstimationBurn},
+		{builtin.RewardActorAddr, gas.MinerTip, derived.LedgerReasonMinerTip},
+	} {
+		if err := l.transfer(exec.Cid, exec.Message.From, fee.to, fee.amount, fee.reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTrace adds the entries of the value transferred by a call and its subcalls. The value of calls that failed, or
+// whose caller failed, is not transferred.
+func (l *ledger) addTrace(msg cid.Cid, et *types.ExecutionTrace, top bool) error {
+	if et.MsgRct.ExitCode.IsError() {
+		return nil
+	}
+
+	to := et.Msg.To
+	if et.InvokedActor != nil {
+		var err error
+		if to, err = address.NewIDAddress(uint64(et.InvokedActor.Id)); err != nil {
+			return err
+		}
+	}
+	if err := l.transfer(msg, et.Msg.From, to, et.Msg.Value, l.reason(et.Msg.From, to, top)); err != nil {
+		return err
+	}
+
+	for i := range et.Subcalls {
+		if err := l.addTrace(msg, &et.Subcalls[i], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *ledger) reason(from, to address.Address, top bool) string {
+	switch {
+	case to == builtin.BurntFundsActorAddr:
+		if l.family(from) == "storageminer" {
+			return derived.LedgerReasonPenalty
+		}
+		return derived.LedgerReasonBurn
+	case from == builtin.RewardActorAddr:
+		return derived.LedgerReasonMinerReward
+	case l.family(from) == "multisig":
+		return derived.LedgerReasonMultisigSend
+	case top:
+		return derived.LedgerReasonTransfer
+	default:
+		return derived.LedgerReasonInternalSend
+	}
+}
+
+// transfer adds a debit of `from` and a credit of `to`, nothing if `amount` is zero.
+func (l *ledger) transfer(msg cid.Cid, from, to address.Address, amount abi.TokenAmount, reason string) error {
+	if amount.Nil() || amount.IsZero() {
+		return nil
+	}
+	fromID, err := l.resolve(from)
+	if err != nil {
+		return fmt.Errorf("resolving sender %s: %w", from, err)
+	}
+	toID, err := l.resolve(to)
+	if err != nil {
+		return fmt.Errorf("resolving receiver %s: %w", to, err)
+	}
+
+	l.add(msg, fromID, toID, big.Sub(big.Zero(), amount), reason)
+	l.add(msg, toID, fromID, amount, reason)
+	return nil
+}
+
+func (l *ledger) add(msg cid.Cid, addr, counterparty address.Address, amount abi.TokenAmount, reason string) {
+	l.entries = append(l.entries, &derived.FilLedgerEntry{
+		Height:       l.height,
+		StateRoot:    l.stateRoot,
+		Index:        uint64(len(l.entries)),
+		Address:      addr.String(),
+		Counterparty: counterparty.String(),
+		Amount:       amount.String(),
+		Reason:       reason,
+		MessageCid:   msg.String(),
+	})
+	net, ok := l.net[addr]
+	if !ok {
+		net = big.Zero()
+	}
+	l.net[addr] = big.Add(net, amount)
+}
@@ -0,0 +1,111 @@
+package filledger
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/model/derived"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/vm"
+)
+
+func TestLedger(t *testing.T) {
+	id := func(n uint64) address.Address {
+		a, err := address.NewIDAddress(n)
+		require.NoError(t, err)
+		return a
+	}
+	var (
+		sender   = id(1000)
+		multisig = id(1001)
+		receiver = id(1002)
+		failed   = id(1003)
+	)
+	msgCid, err := cid.Decode("bafy2bzacebbpdegvr3i4cosewthysg5xkxpqfn2wfcz6mv2hmoktwbdxkax4s")
+	require.NoError(t, err)
+
+	l := &ledger{
+		height:    10,
+		stateRoot: "root",
+		resolve:   func(a address.Address) (address.Address, error) { return a, nil },
+		family: func(a address.Address) string {
+			if a == multisig {
+				return "multisig"
+			}
+			return "account"
+		},
+		net: map[address.Address]big.Int{},
+	}
+
+	exec := &lens.MessageExecution{
+		Cid:     msgCid,
+		Message: &types.Message{From: sender, To: multisig, Value: abi.NewTokenAmount(100)},
+		Ret: &vm.ApplyRet{
+			ExecutionTrace: types.ExecutionTrace{
+				Msg: types.MessageTrace{From: sender, To: multisig, Value: abi.NewTokenAmount(100)},
+				Subcalls: []types.ExecutionTrace{
+					{Msg: types.MessageTrace{From: multisig, To: receiver, Value: abi.NewTokenAmount(60)}},
+					{
+						// the value of a failed call and its subcalls is not transferred.
+						Msg:      types.MessageTrace{From: multisig, To: failed, Value: abi.NewTokenAmount(30)},
+						MsgRct:   types.ReturnTrace{ExitCode: exitcode.ErrForbidden},
+						Subcalls: []types.ExecutionTrace{{Msg: types.MessageTrace{From: failed, To: receiver, Value: abi.NewTokenAmount(5)}}},
+					},
+				},
+			},
+			GasCosts: &vm.GasOutputs{
+				BaseFeeBurn:        abi.NewTokenAmount(7),
+				OverEstimationBurn: abi.NewTokenAmount(0),
+				MinerPenalty:       abi.NewTokenAmount(3),
+				MinerTip:           abi.NewTokenAmount(2),
+			},
+		},
+	}
+	require.NoError(t, l.addExecution(exec))
+
+	type entry struct {
+		address, counterparty, amount, reason string
+	}
+	var got []entry
+	for i, e := range l.entries {
+		require.EqualValues(t, i, e.Index)
+		require.Equal(t, msgCid.String(), e.MessageCid)
+		got = append(got, entry{e.Address, e.Counterparty, e.Amount, e.Reason})
+	}
+	burnt, reward := builtin.BurntFundsActorAddr.String(), builtin.RewardActorAddr.String()
+	require.Equal(t, []entry{
+		{sender.String(), multisig.String(), "-100", derived.LedgerReasonTransfer},
+		{multisig.String(), sender.String(), "100", derived.LedgerReasonTransfer},
+		{multisig.String(), receiver.String(), "-60", derived.LedgerReasonMultisigSend},
+		{receiver.String(), multisig.String(), "60", derived.LedgerReasonMultisigSend},
+		{sender.String(), burnt, "-7", derived.LedgerReasonBaseFeeBurn},
+		{burnt, sender.String(), "7", derived.LedgerReasonBaseFeeBurn},
+		{sender.String(), reward, "-2", derived.LedgerReasonMinerTip},
+		{reward, sender.String(), "2", derived.LedgerReasonMinerTip},
+	}, got)
+
+	balances := map[address.Address]big.Int{
+		sender:                      abi.NewTokenAmount(-109),
+		multisig:                    abi.NewTokenAmount(40),
+		receiver:                    abi.NewTokenAmount(60),
+		builtin.BurntFundsActorAddr: abi.NewTokenAmount(7),
+		builtin.RewardActorAddr:     abi.NewTokenAmount(2),
+	}
+	require.Empty(t, reconcile(l.net, balances))
+
+	balances[receiver] = abi.NewTokenAmount(65)
+	balances[failed] = abi.NewTokenAmount(-5)
+	require.Equal(t, []*BalanceMismatch{
+		{Address: receiver.String(), BalanceChange: "65", LedgerChange: "60"},
+		{Address: failed.String(), BalanceChange: "-5", LedgerChange: "0"},
+	}, reconcile(l.net, balances))
+}
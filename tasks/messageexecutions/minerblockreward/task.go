@@ -0,0 +1,152 @@
+package minerblockreward
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lily/chain/actors/builtin/reward"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/derived"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+	miner7 "github.com/filecoin-project/specs-actors/v7/actors/builtin/miner"
+
+	"github.com/filecoin-project/lotus/build/buildconstants"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{node: node}
+}
+
+func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "miner_block_rewards"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	mex, err := t.node.MessageExecutions(ctx, current, executed)
+	if err != nil {
+		report.ErrorsDetected = fmt.Errorf("getting messages executions for tipset: %w", err)
+		return nil, report, nil
+	}
+
+	rewards, err := blockRewards(executed, mex)
+	if err != nil {
+		report.ErrorsDetected = err
+		return nil, report, nil
+	}
+
+	out := make(derived.MinerBlockRewardList, 0, len(rewards))
+	for _, r := range rewards {
+		locked := lockedReward(r.gross, executed.Height())
+		r.LockedReward = locked.String()
+		r.VestedReward = big.Sub(r.gross, locked).String()
+		out = append(out, r.MinerBlockReward)
+	}
+	return out, report, nil
+}
+
+// lockedReward returns the part of reward `gross`, paid to a miner at height `height`, that the miner actor locks in its
+// vesting funds. The miner actor locks a fixed share of each reward since network version 6 (FIP-0004), before it the
+// whole reward was locked. The share is computed from the reward rather than from the change of vesting funds, which
+// also drops by the funds that vest in the same epoch and by penalties and fee debt repaid from them.
+func lockedReward(gross big.Int, height abi.ChainEpoch) big.Int {
+	if height <= buildconstants.UpgradeKumquatHeight {
+		return gross
+	}
+	return big.Div(big.Mul(gross, miner7.LockedRewardFactorNum), miner7.LockedRewardFactorDenom)
+}
+
+type blockReward struct {
+	*derived.MinerBlockReward
+	gross big.Int
+}
+
+// blockRewards returns the reward paid to the miner of each block of `executed`. The reward of each block is paid by
+// an implicit message to the reward actor, applied in the order of the blocks of the tipset.
+func blockRewards(executed *types.TipSet, mex []*lens.MessageExecution) ([]*blockReward, error) {
+	var awards []*lens.MessageExecution
+	for _, exec := range mex {
+		if exec.Implicit && exec.Message.To == builtin.RewardActorAddr && exec.Message.Method == builtin.MethodsReward.AwardBlockReward {
+			awards = append(awards, exec)
+		}
+	}
+	if len(awards) == 0 {
+		return nil, nil
+	}
+	blocks := executed.Blocks()
+	if len(awards) != len(blocks) {
+		return nil, fmt.Errorf("tipset has %d blocks but %d block rewards were awarded", len(blocks), len(awards))
+	}
+
+	out := make([]*blockReward, 0, len(awards))
+	for i, exec := range awards {
+		blk := blocks[i]
+		var params reward.AwardBlockRewardParams
+		if err := params.UnmarshalCBOR(bytes.NewReader(exec.Message.Params)); err != nil {
+			return nil, fmt.Errorf("decoding block reward params of block %s: %w", blk.Cid(), err)
+		}
+		if blk.ElectionProof != nil && params.WinCount != blk.ElectionProof.WinCount {
+			return nil, fmt.Errorf("block %s won %d rewards but %d were awarded", blk.Cid(), blk.ElectionProof.WinCount, params.WinCount)
+		}
+
+		// the reward actor pays the miner when applying the rewards of the miner, nothing is paid if it fails.
+		gross := big.Zero()
+		if exec.Ret != nil && exec.Ret.ExitCode.IsSuccess() {
+			for _, sub := range exec.Ret.ExecutionTrace.Subcalls {
+				if sub.Msg.Method == builtin.MethodsMiner.ApplyRewards && sub.MsgRct.ExitCode.IsSuccess() {
+					gross = big.Add(gross, sub.Msg.Value)
+				}
+			}
+		}
+		tips := params.GasReward
+		if tips.Nil() {
+			tips = big.Zero()
+		}
+		penalty := params.Penalty
+		if penalty.Nil() {
+			penalty = big.Zero()
+		}
+
+		out = append(out, &blockReward{
+			MinerBlockReward: &derived.MinerBlockReward{
+				Height:      int64(blk.Height),
+				BlockCid:    blk.Cid().String(),
+				StateRoot:   exec.StateRoot.String(),
+				Miner:       blk.Miner.String(),
+				WinCount:    params.WinCount,
+				GrossReward: gross.String(),
+				BlockReward: big.Max(big.Zero(), big.Sub(gross, tips)).String(),
+				Tips:        tips.String(),
+				Penalty:     penalty.String(),
+			},
+			gross: gross,
+		})
+	}
+	return out, nil
+}
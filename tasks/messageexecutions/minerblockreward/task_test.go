@@ -0,0 +1,76 @@
+package minerblockreward
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lily/chain/actors/builtin/reward"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/testutil"
+	tutils "github.com/filecoin-project/specs-actors/support/testing"
+
+	"github.com/filecoin-project/lotus/build/buildconstants"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/vm"
+)
+
+func TestBlockRewards(t *testing.T) {
+	miner := tutils.NewIDAddr(t, 1000)
+	bh := testutil.FakeBlockHeader(t, 10, testutil.RandomCid())
+	bh.Miner = miner
+	bh.ElectionProof = &types.ElectionProof{WinCount: 2}
+	ts, err := types.NewTipSet([]*types.BlockHeader{bh})
+	require.NoError(t, err)
+
+	params := &reward.AwardBlockRewardParams{
+		Miner:     miner,
+		Penalty:   abi.NewTokenAmount(0),
+		GasReward: abi.NewTokenAmount(30),
+		WinCount:  2,
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, params.MarshalCBOR(buf))
+
+	mex := []*lens.MessageExecution{
+		// the cron message is not a block reward.
+		{Implicit: true, Message: &types.Message{To: builtin.CronActorAddr, Method: builtin.MethodsCron.EpochTick}},
+		{
+			Implicit:  true,
+			StateRoot: testutil.RandomCid(),
+			Message:   &types.Message{To: builtin.RewardActorAddr, Method: builtin.MethodsReward.AwardBlockReward, Params: buf.Bytes()},
+			Ret: &vm.ApplyRet{ExecutionTrace: types.ExecutionTrace{
+				Subcalls: []types.ExecutionTrace{{
+					Msg: types.MessageTrace{From: builtin.RewardActorAddr, To: miner, Method: builtin.MethodsMiner.ApplyRewards, Value: abi.NewTokenAmount(1030)},
+				}},
+			}},
+		},
+	}
+
+	rewards, err := blockRewards(ts, mex)
+	require.NoError(t, err)
+	require.Len(t, rewards, 1)
+	r := rewards[0]
+	require.Equal(t, bh.Cid().String(), r.BlockCid)
+	require.Equal(t, miner.String(), r.Miner)
+	require.EqualValues(t, 2, r.WinCount)
+	require.Equal(t, "1030", r.GrossReward)
+	require.Equal(t, "1000", r.BlockReward)
+	require.Equal(t, "30", r.Tips)
+	require.Equal(t, "0", r.Penalty)
+
+	// every block of the tipset must be awarded a reward.
+	_, err = blockRewards(ts, append(mex, mex[1]))
+	require.Error(t, err)
+}
+
+func TestLockedReward(t *testing.T) {
+	gross := abi.NewTokenAmount(1000)
+	// the whole reward is locked until network version 6, three quarters of it after.
+	require.Equal(t, "1000", lockedReward(gross, buildconstants.UpgradeKumquatHeight).String())
+	require.Equal(t, "750", lockedReward(gross, buildconstants.UpgradeKumquatHeight+1).String())
+	require.Equal(t, "0", lockedReward(abi.NewTokenAmount(0), buildconstants.UpgradeKumquatHeight+1).String())
+}
@@ -0,0 +1,132 @@
+package builtinactorevent
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lily/model"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+const (
+	// dagCborCodec is the codec of the event entries decoded by lily.
+	dagCborCodec = 0x51
+
+	encodingID     = "id"
+	encodingCid    = "cid"
+	encodingBigInt = "bigint"
+)
+
+// eventTypeOf returns the value of the $type entry of an event, the empty string if it has none.
+func eventTypeOf(event *types.ActorEvent) string {
+	for _, e := range event.Entries {
+		if e.Codec != dagCborCodec || e.Key != "$type" {
+			continue
+		}
+		var t string
+		if err := cbor.Unmarshal(e.Value, &t); err != nil {
+			return ""
+		}
+		return t
+	}
+	return ""
+}
+
+// decode returns the events of the types stored in the table decoded into its model. `events` are all the events
+// emitted when executing `executed`, so that the index of an event is the same in every table.
+func (et *EventTable) decode(executed *types.TipSet, events []*types.ActorEvent) (model.Persistable, []error) {
+	var errs []error
+	list := reflect.MakeSlice(et.listType, 0, 0)
+	for idx, event := range events {
+		typ := eventTypeOf(event)
+		if tablesByEventType[typ] != et {
+			continue
+		}
+
+		v := reflect.New(et.listType.Elem().Elem())
+		s := v.Elem()
+		s.FieldByName("Height").SetInt(int64(executed.Height()))
+		s.FieldByName("MessageCid").SetString(event.MsgCid.String())
+		s.FieldByName("EventIdx").SetInt(int64(idx))
+		s.FieldByName("Emitter").SetString(event.Emitter.String())
+		s.FieldByName("EventType").SetString(typ)
+
+		if err := et.decodeEntries(event.Entries, s); err != nil {
+			errs = append(errs, fmt.Errorf("decoding %s event %d of message %s: %w", typ, idx, event.MsgCid, err))
+			continue
+		}
+		list = reflect.Append(list, v)
+	}
+	return list.Interface().(model.Persistable), errs
+}
+
+// decodeEntries sets the fields of `s` to the values of the entries they are tagged with. Entries without a field are
+// ignored.
+func (et *EventTable) decodeEntries(entries []types.EventEntry, s reflect.Value) error {
+	for _, e := range entries {
+		if e.Codec != dagCborCodec {
+			continue
+		}
+		ef, ok := et.fields[e.Key]
+		if !ok || bytes.Equal(e.Value, cbg.CborNull) {
+			continue
+		}
+
+		f := s.Field(ef.index)
+		ft := f.Type()
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		v, err := decodeValue(ef.encoding, ft, e.Value)
+		if err != nil {
+			return fmt.Errorf("entry %s: %w", e.Key, err)
+		}
+		if f.Kind() == reflect.Slice {
+			f.Set(reflect.Append(f, v))
+		} else {
+			f.Set(v)
+		}
+	}
+	return nil
+}
+
+func decodeValue(encoding string, typ reflect.Type, raw []byte) (reflect.Value, error) {
+	switch encoding {
+	case encodingID:
+		var id uint64
+		if err := cbor.Unmarshal(raw, &id); err != nil {
+			return reflect.Value{}, err
+		}
+		addr, err := address.NewIDAddress(id)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(addr.String()).Convert(typ), nil
+	case encodingCid:
+		c, err := cbg.ReadCid(bytes.NewReader(raw))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(c.String()).Convert(typ), nil
+	case encodingBigInt:
+		var b big.Int
+		if err := b.UnmarshalCBOR(bytes.NewReader(raw)); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b.String()).Convert(typ), nil
+	}
+
+	v := reflect.New(typ)
+	if err := cbor.Unmarshal(raw, v.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return v.Elem(), nil
+}
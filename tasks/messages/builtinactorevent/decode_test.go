@@ -0,0 +1,144 @@
+package builtinactorevent
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lily/model/actors/builtinactor"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func entry(t *testing.T, key string, v interface{}) types.EventEntry {
+	var buf bytes.Buffer
+	switch v := v.(type) {
+	case cid.Cid:
+		require.NoError(t, cbg.WriteCid(&buf, v))
+	case big.Int:
+		require.NoError(t, v.MarshalCBOR(&buf))
+	case nil:
+		buf.Write(cbg.CborNull)
+	default:
+		b, err := cbor.Marshal(v)
+		require.NoError(t, err)
+		buf.Write(b)
+	}
+	return types.EventEntry{Key: key, Codec: dagCborCodec, Value: buf.Bytes()}
+}
+
+func TestDecode(t *testing.T) {
+	msg := cid.MustParse("bafy2bzacedrunl6pmqwxtmhr5cyjiy3p4qx5qqffjdwjzouyyzrirrycrabwe")
+	piece1 := cid.MustParse("baga6ea4seaqao7s73y24kcutaosvacpdjgfe5pw76ooefnyqw4ynr3d2y6x2mpq")
+	piece2 := cid.MustParse("baga6ea4seaqjtovkwk4myyzj56eztkh5pzsk5upksan6f5outesy62bsvl4dsha")
+	miner, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	ts, err := types.NewTipSet([]*types.BlockHeader{{
+		Miner:                 miner,
+		Height:                10,
+		ParentStateRoot:       msg,
+		ParentMessageReceipts: msg,
+		Messages:              msg,
+		Ticket:                &types.Ticket{VRFProof: []byte{1}},
+		ParentBaseFee:         abi.NewTokenAmount(100),
+	}})
+	require.NoError(t, err)
+
+	events := []*types.ActorEvent{
+		{Emitter: miner, MsgCid: msg, Entries: []types.EventEntry{
+			entry(t, "$type", "sector-activated"),
+			entry(t, "sector", 7),
+			entry(t, "unsealed-cid", nil),
+			entry(t, "piece-cid", piece1),
+			entry(t, "piece-size", 2048),
+			entry(t, "piece-cid", piece2),
+			entry(t, "piece-size", 4096),
+		}},
+		{Emitter: verifregAddress(t), MsgCid: msg, Entries: []types.EventEntry{
+			entry(t, "$type", "unknown-event"),
+		}},
+		{Emitter: verifregAddress(t), MsgCid: msg, Entries: []types.EventEntry{
+			entry(t, "$type", "verifier-balance"),
+			entry(t, "verifier", 1234),
+			entry(t, "balance", big.NewInt(1_000_000)),
+		}},
+		{Emitter: verifregAddress(t), MsgCid: msg, Entries: []types.EventEntry{
+			entry(t, "$type", "claim"),
+			entry(t, "id", 5),
+			entry(t, "client", 1234),
+			entry(t, "provider", 1000),
+			entry(t, "piece-cid", piece1),
+			entry(t, "piece-size", 2048),
+			entry(t, "term-min", 518400),
+			entry(t, "term-max", 5256000),
+			entry(t, "term-start", 10),
+			entry(t, "sector", 7),
+		}},
+	}
+
+	sectors, errs := tablesByName["builtin_sector_events"].decode(ts, events)
+	require.Empty(t, errs)
+	require.Equal(t, builtinactor.SectorEvents{{
+		Height:     10,
+		MessageCid: msg.String(),
+		EventIdx:   0,
+		Emitter:    miner.String(),
+		EventType:  "sector-activated",
+		Sector:     7,
+		PieceCids:  []string{piece1.String(), piece2.String()},
+		PieceSizes: []uint64{2048, 4096},
+	}}, sectors)
+
+	balances, errs := tablesByName["builtin_verifier_balance_events"].decode(ts, events)
+	require.Empty(t, errs)
+	require.Equal(t, builtinactor.VerifierBalanceEvents{{
+		Height:     10,
+		MessageCid: msg.String(),
+		EventIdx:   2,
+		Emitter:    verifregAddress(t).String(),
+		EventType:  "verifier-balance",
+		Verifier:   "f01234",
+		Balance:    "1000000",
+	}}, balances)
+
+	claims, errs := tablesByName["builtin_claim_events"].decode(ts, events)
+	require.Empty(t, errs)
+	require.Equal(t, builtinactor.ClaimEvents{{
+		Height:     10,
+		MessageCid: msg.String(),
+		EventIdx:   3,
+		Emitter:    verifregAddress(t).String(),
+		EventType:  "claim",
+		ClaimID:    5,
+		Client:     "f01234",
+		Provider:   "f01000",
+		PieceCid:   piece1.String(),
+		PieceSize:  2048,
+		TermMin:    518400,
+		TermMax:    5256000,
+		TermStart:  10,
+		Sector:     7,
+	}}, claims)
+
+	deals, errs := tablesByName["builtin_deal_events"].decode(ts, events)
+	require.Empty(t, errs)
+	require.Empty(t, deals)
+
+	_, typed := tablesByEventType[eventTypeOf(events[1])]
+	require.False(t, typed)
+}
+
+func verifregAddress(t *testing.T) address.Address {
+	addr, err := address.NewIDAddress(6)
+	require.NoError(t, err)
+	return addr
+}
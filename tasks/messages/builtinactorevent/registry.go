@@ -0,0 +1,144 @@
+package builtinactorevent
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/actors/builtinactor"
+)
+
+// EventTable maps one or more builtin actor event types to the model of the table their events are decoded into.
+type EventTable struct {
+	// Name is the name of the table and of the task persisting it.
+	Name string
+	// EventTypes are the values of the $type entry of the events stored in the table.
+	EventTypes []string
+	// List is an empty list of the model of the table. The model must have the Height, MessageCid, EventIdx, Emitter
+	// and EventType fields and an `event` tag on each field decoded from an event entry.
+	List model.Persistable
+
+	listType reflect.Type
+	fields   map[string]eventField
+}
+
+// EventTables are the builtin actor events decoded into typed tables. Events of any other type are stored in the
+// builtin_actor_events table. Supporting the events of a new network version only requires adding their model here,
+// to the schema and to the table tasks.
+var EventTables = []*EventTable{
+	{
+		Name:       "builtin_verifier_balance_events",
+		EventTypes: []string{"verifier-balance"},
+		List:       builtinactor.VerifierBalanceEvents{},
+	},
+	{
+		Name:       "builtin_allocation_events",
+		EventTypes: []string{"allocation", "allocation-removed"},
+		List:       builtinactor.AllocationEvents{},
+	},
+	{
+		Name:       "builtin_claim_events",
+		EventTypes: []string{"claim", "claim-updated", "claim-removed"},
+		List:       builtinactor.ClaimEvents{},
+	},
+	{
+		Name:       "builtin_deal_events",
+		EventTypes: []string{"deal-published", "deal-activated", "deal-terminated", "deal-completed"},
+		List:       builtinactor.DealEvents{},
+	},
+	{
+		Name:       "builtin_sector_events",
+		EventTypes: []string{"sector-precommitted", "sector-activated", "sector-updated", "sector-terminated"},
+		List:       builtinactor.SectorEvents{},
+	},
+}
+
+var (
+	tablesByName      = map[string]*EventTable{}
+	tablesByEventType = map[string]*EventTable{}
+)
+
+func init() {
+	for _, et := range EventTables {
+		if err := et.init(); err != nil {
+			panic(fmt.Sprintf("invalid builtin actor event table %s: %v", et.Name, err))
+		}
+		if _, exists := tablesByName[et.Name]; exists {
+			panic(fmt.Sprintf("duplicate builtin actor event table: %s", et.Name))
+		}
+		tablesByName[et.Name] = et
+		for _, eventType := range et.EventTypes {
+			if _, exists := tablesByEventType[eventType]; exists {
+				panic(fmt.Sprintf("builtin actor event type %s registered for more than one table", eventType))
+			}
+			tablesByEventType[eventType] = et
+		}
+	}
+}
+
+// LookupEventTable returns the typed table with the given name.
+func LookupEventTable(name string) (*EventTable, bool) {
+	et, ok := tablesByName[name]
+	return et, ok
+}
+
+// eventField is a field of a model decoded from an event entry.
+type eventField struct {
+	index    int
+	encoding string
+}
+
+var headerFields = map[string]reflect.Kind{
+	"Height":     reflect.Int64,
+	"MessageCid": reflect.String,
+	"EventIdx":   reflect.Int64,
+	"Emitter":    reflect.String,
+	"EventType":  reflect.String,
+}
+
+func (et *EventTable) init() error {
+	et.listType = reflect.TypeOf(et.List)
+	if et.listType.Kind() != reflect.Slice || et.listType.Elem().Kind() != reflect.Ptr || et.listType.Elem().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("list %s is not a slice of struct pointers", et.listType)
+	}
+	typ := et.listType.Elem().Elem()
+
+	for name, kind := range headerFields {
+		f, ok := typ.FieldByName(name)
+		if !ok || f.Type.Kind() != kind {
+			return fmt.Errorf("model %s has no %s field of kind %s", typ, name, kind)
+		}
+	}
+
+	et.fields = map[string]eventField{}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag, ok := f.Tag.Lookup("event")
+		if !ok {
+			continue
+		}
+		key, encoding, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		switch encoding {
+		case "":
+			if ft.Kind() != reflect.Int64 && ft.Kind() != reflect.Uint64 && ft.Kind() != reflect.String {
+				return fmt.Errorf("field %s has unsupported type %s", f.Name, f.Type)
+			}
+		case encodingID, encodingCid, encodingBigInt:
+			if ft.Kind() != reflect.String {
+				return fmt.Errorf("field %s with %s encoding is not a string", f.Name, encoding)
+			}
+		default:
+			return fmt.Errorf("field %s has unknown encoding %s", f.Name, encoding)
+		}
+		if _, exists := et.fields[key]; exists {
+			return fmt.Errorf("entry %s is decoded into more than one field", key)
+		}
+		et.fields[key] = eventField{index: i, encoding: encoding}
+	}
+	return nil
+}
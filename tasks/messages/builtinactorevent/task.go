@@ -20,39 +20,32 @@ import (
 
 var log = logging.Logger("lily/tasks/builtinactorevent")
 
+// Task persists builtin actor events as json in the builtin_actor_events table.
 type Task struct {
 	node tasks.DataSource
+	// typed holds the event types persisted by the typed tasks of the same job, which are not persisted by this task.
+	typed map[string]bool
 }
 
-func NewTask(node tasks.DataSource) *Task {
-	return &Task{
+// NewTask returns a task persisting builtin actor events as json. Events of the types persisted by the typed tasks
+// named in `jobTasks`, the tasks of the same job, are left to those tasks.
+func NewTask(node tasks.DataSource, jobTasks ...string) *Task {
+	t := &Task{
 		node: node,
 	}
-}
-
-var (
-	fields map[string][]types.ActorEventBlock
-)
-
-func init() {
-	targetEvents := []string{
-		"verifier-balance",
-		"allocation",
-		"allocation-removed",
-		"claim",
-		"claim-updated",
-		"claim-removed",
-		"deal-published",
-		"deal-activated",
-		"deal-terminated",
-		"deal-completed",
-		"sector-precommitted",
-		"sector-activated",
-		"sector-updated",
-		"sector-terminated",
+	for _, name := range jobTasks {
+		et, ok := LookupEventTable(name)
+		if !ok {
+			continue
+		}
+		if t.typed == nil {
+			t.typed = make(map[string]bool)
+		}
+		for _, eventType := range et.EventTypes {
+			t.typed[eventType] = true
+		}
 	}
-
-	fields = util.GenFilterFields(targetEvents)
+	return t
 }
 
 func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
@@ -91,11 +84,23 @@ func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, execut
 		errs = append(errs, err)
 	}
 
-	var (
-		builtInActorResult = make(builtinactor.BuiltInActorEvents, 0)
-	)
+	builtInActorResult := t.builtInActorEvents(executed, events)
+
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+
+	return builtInActorResult, report, nil
+}
 
+// builtInActorEvents returns the events of `executed` that are not persisted by a typed task of the same job. The
+// EventIdx of an event is its index among all events of the tipset, as it is for the events of the typed tables.
+func (t *Task) builtInActorEvents(executed *types.TipSet, events []*types.ActorEvent) builtinactor.BuiltInActorEvents {
+	out := make(builtinactor.BuiltInActorEvents, 0)
 	for evtIdx, event := range events {
+		if t.typed[eventTypeOf(event)] {
+			continue
+		}
 		eventType, actorEvent, eventsSlice := util.HandleEventEntries(event)
 
 		obj := builtinactor.BuiltInActorEvent{
@@ -116,13 +121,8 @@ func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, execut
 			obj.EventPayload = string(payload)
 		}
 		if obj.EventType != "" {
-			builtInActorResult = append(builtInActorResult, &obj)
+			out = append(out, &obj)
 		}
 	}
-
-	if len(errs) > 0 {
-		report.ErrorsDetected = fmt.Errorf("%v", errs)
-	}
-
-	return builtInActorResult, report, nil
+	return out
 }
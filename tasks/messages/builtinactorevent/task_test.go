@@ -0,0 +1,59 @@
+package builtinactorevent
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestTaskLeavesEventsToTypedTasksOfTheJob(t *testing.T) {
+	msg := cid.MustParse("bafy2bzacedrunl6pmqwxtmhr5cyjiy3p4qx5qqffjdwjzouyyzrirrycrabwe")
+	miner, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	ts, err := types.NewTipSet([]*types.BlockHeader{{
+		Miner:                 miner,
+		Height:                10,
+		ParentStateRoot:       msg,
+		ParentMessageReceipts: msg,
+		Messages:              msg,
+		Ticket:                &types.Ticket{VRFProof: []byte{1}},
+		ParentBaseFee:         abi.NewTokenAmount(100),
+	}})
+	require.NoError(t, err)
+
+	events := []*types.ActorEvent{
+		{Emitter: miner, MsgCid: msg, Entries: []types.EventEntry{
+			entry(t, "$type", "sector-activated"),
+			entry(t, "sector", 7),
+		}},
+		{Emitter: verifregAddress(t), MsgCid: msg, Entries: []types.EventEntry{
+			entry(t, "$type", "claim"),
+			entry(t, "id", 5),
+		}},
+		{Emitter: verifregAddress(t), MsgCid: msg, Entries: []types.EventEntry{
+			entry(t, "$type", "verifier-balance"),
+			entry(t, "verifier", 1234),
+		}},
+	}
+
+	eventIdxs := func(task *Task) map[string]int64 {
+		out := map[string]int64{}
+		for _, evt := range task.builtInActorEvents(ts, events) {
+			out[evt.EventType] = evt.EventIdx
+		}
+		return out
+	}
+
+	// without typed tasks in the job every event is persisted
+	require.Equal(t, map[string]int64{"sector-activated": 0, "claim": 1, "verifier-balance": 2}, eventIdxs(NewTask(nil)))
+	require.Equal(t, map[string]int64{"sector-activated": 0, "claim": 1, "verifier-balance": 2}, eventIdxs(NewTask(nil, "builtin_actor_event", "messages")))
+
+	// events of the typed tasks of the job are skipped, the others keep their index among all events of the tipset
+	require.Equal(t, map[string]int64{"sector-activated": 0, "verifier-balance": 2}, eventIdxs(NewTask(nil, "builtin_actor_event", "builtin_claim_events")))
+}
@@ -0,0 +1,68 @@
+package builtinactorevent
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/lily/model"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// TypedTask persists the builtin actor events of the types of an EventTable decoded into the model of the table.
+type TypedTask struct {
+	node  tasks.DataSource
+	table *EventTable
+}
+
+// NewTypedTask returns a task persisting the events of the EventTable named `table`.
+func NewTypedTask(node tasks.DataSource, table string) *TypedTask {
+	et, ok := LookupEventTable(table)
+	if !ok {
+		panic(fmt.Sprintf("unknown builtin actor event table: %s", table))
+	}
+	return &TypedTask{
+		node:  node,
+		table: et,
+	}
+}
+
+func (t *TypedTask) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", t.table.Name),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	tsKey := executed.Key()
+	events, err := t.node.GetActorEventsRaw(ctx, &types.ActorEventFilter{
+		TipSetKey: &tsKey,
+	})
+	if err != nil {
+		log.Errorf("GetActorEventsRaw[pTs: %v, pHeight: %v, cTs: %v, cHeight: %v] err: %v", executed.Key().String(), executed.Height(), current.Key().String(), current.Height(), err)
+		report.ErrorsDetected = fmt.Errorf("getting actor events: %w", err)
+		return nil, report, nil
+	}
+
+	out, errs := t.table.decode(executed, events)
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+	return out, report, nil
+}
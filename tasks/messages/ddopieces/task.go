@@ -0,0 +1,296 @@
+package ddopieces
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lily/chain/actors/builtin/market"
+	"github.com/filecoin-project/lily/lens/util"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/actors/builtinactor"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/ddopieces")
+
+const (
+	sectorActivated = "sector-activated"
+	sectorUpdated   = "sector-updated"
+	claimEvent      = "claim"
+	dealActivated   = "deal-activated"
+)
+
+var fields = util.GenFilterFields([]string{sectorActivated, sectorUpdated, claimEvent, dealActivated})
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "ddo_pieces"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	tsKey := executed.Key()
+	raw, err := t.node.GetActorEventsRaw(ctx, &types.ActorEventFilter{
+		TipSetKey: &tsKey,
+		Fields:    fields,
+	})
+	if err != nil {
+		log.Errorf("GetActorEventsRaw[pTs: %v, pHeight: %v, cTs: %v, cHeight: %v] err: %v", executed.Key().String(), executed.Height(), current.Key().String(), current.Height(), err)
+		report.ErrorsDetected = fmt.Errorf("getting actor events: %w", err)
+		return nil, report, nil
+	}
+
+	events := make([]*event, 0, len(raw))
+	for _, e := range raw {
+		eventType, payload, entries := util.HandleEventEntries(e)
+		events = append(events, &event{
+			eventType: eventType,
+			emitter:   e.Emitter,
+			msgCid:    e.MsgCid,
+			entries:   entries,
+			payload:   payload,
+		})
+	}
+
+	// the proposals of deals activated by the tipset are read from the state resulting from its execution.
+	var proposals market.DealProposals
+	proposal := func(id abi.DealID) (*market.DealProposal, bool, error) {
+		if proposals == nil {
+			act, err := t.node.Actor(ctx, market.Address, current.Key())
+			if err != nil {
+				return nil, false, fmt.Errorf("loading market actor: %w", err)
+			}
+			state, err := market.Load(t.node.Store(), act)
+			if err != nil {
+				return nil, false, fmt.Errorf("loading market state: %w", err)
+			}
+			if proposals, err = state.Proposals(); err != nil {
+				return nil, false, fmt.Errorf("loading market deal proposals: %w", err)
+			}
+		}
+		return proposals.Get(id)
+	}
+
+	out, errs := ddoPieces(executed, events, proposal)
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+	return out, report, nil
+}
+
+// event is a decoded builtin actor event.
+type event struct {
+	eventType string
+	emitter   address.Address
+	msgCid    cid.Cid
+	// entries are the entries of the event in the order they were emitted.
+	entries []*util.KVEvent
+	// payload maps the keys of the entries of the event to their values.
+	payload map[string]interface{}
+}
+
+type claim struct {
+	id     uint64
+	client string
+	used   bool
+}
+
+type deal struct {
+	id       uint64
+	client   string
+	pieceCID string
+	verified bool
+	used     bool
+}
+
+type proposalFunc func(id abi.DealID) (*market.DealProposal, bool, error)
+
+// ddoPieces returns a row for each piece of the sector-activated and sector-updated events emitted when executing
+// `executed`. Each piece is matched with the verified registry claim made for the same provider, sector and piece,
+// and with the market deal activated by the same message for the same provider and piece.
+func ddoPieces(executed *types.TipSet, events []*event, proposal proposalFunc) (builtinactor.DDOPieces, []error) {
+	var errs []error
+
+	claims := map[string][]*claim{}
+	deals := map[string][]*deal{}
+	for _, e := range events {
+		switch e.eventType {
+		case claimEvent:
+			id, err1 := payloadUint(e, "id")
+			provider, err2 := payloadID(e, "provider")
+			client, err3 := payloadID(e, "client")
+			sector, err4 := payloadUint(e, "sector")
+			if err := firstErr(err1, err2, err3, err4); err != nil {
+				errs = append(errs, fmt.Errorf("decoding claim event of message %s: %w", e.msgCid, err))
+				continue
+			}
+			key := pieceKey(provider, sector, payloadString(e, "piece-cid"))
+			claims[key] = append(claims[key], &claim{id: id, client: client})
+		case dealActivated:
+			id, err1 := payloadUint(e, "id")
+			provider, err2 := payloadID(e, "provider")
+			client, err3 := payloadID(e, "client")
+			if err := firstErr(err1, err2, err3); err != nil {
+				errs = append(errs, fmt.Errorf("decoding deal-activated event of message %s: %w", e.msgCid, err))
+				continue
+			}
+			dp, found, err := proposal(abi.DealID(id))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("getting proposal of deal %d: %w", id, err))
+				continue
+			}
+			if !found {
+				continue
+			}
+			key := e.msgCid.String() + "/" + provider
+			deals[key] = append(deals[key], &deal{id: id, client: client, pieceCID: dp.PieceCID.String(), verified: dp.VerifiedDeal})
+		}
+	}
+
+	out := builtinactor.DDOPieces{}
+	seen := map[string]struct{}{}
+	for _, e := range events {
+		if e.eventType != sectorActivated && e.eventType != sectorUpdated {
+			continue
+		}
+		miner := e.emitter.String()
+		sector, err := payloadUint(e, "sector")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("decoding %s event of message %s: %w", e.eventType, e.msgCid, err))
+			continue
+		}
+
+		// the pieces of the sector are emitted as consecutive piece-cid and piece-size entries.
+		var pieces []*builtinactor.DDOPiece
+		for _, kv := range e.entries {
+			switch kv.Key {
+			case "piece-cid":
+				pieces = append(pieces, &builtinactor.DDOPiece{
+					Height:      int64(executed.Height()),
+					Miner:       miner,
+					SectorID:    sector,
+					PieceCID:    kv.Value,
+					StateRoot:   executed.ParentState().String(),
+					MessageCid:  e.msgCid.String(),
+					EventType:   e.eventType,
+					UnsealedCid: payloadString(e, "unsealed-cid"),
+				})
+			case "piece-size":
+				if len(pieces) == 0 {
+					continue
+				}
+				size, err := strconv.ParseUint(kv.Value, 10, 64)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("decoding piece size of %s event of message %s: %w", e.eventType, e.msgCid, err))
+					continue
+				}
+				pieces[len(pieces)-1].PieceSize = size
+			}
+		}
+
+		for _, p := range pieces {
+			key := pieceKey(miner, sector, p.PieceCID)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			for _, c := range claims[key] {
+				if !c.used {
+					c.used = true
+					id := c.id
+					p.ClaimID = &id
+					p.Client = c.client
+					p.Verified = true
+					break
+				}
+			}
+			for _, d := range deals[e.msgCid.String()+"/"+miner] {
+				if !d.used && d.pieceCID == p.PieceCID {
+					d.used = true
+					id := d.id
+					p.DealID = &id
+					p.Verified = p.Verified || d.verified
+					if p.Client == "" {
+						p.Client = d.client
+					}
+					break
+				}
+			}
+			out = append(out, p)
+		}
+	}
+	return out, errs
+}
+
+func pieceKey(miner string, sector uint64, pieceCID string) string {
+	return fmt.Sprintf("%s/%d/%s", miner, sector, pieceCID)
+}
+
+func payloadString(e *event, key string) string {
+	v, _ := e.payload[key].(string)
+	return v
+}
+
+func payloadUint(e *event, key string) (uint64, error) {
+	v, ok := e.payload[key].(string)
+	if !ok {
+		return 0, fmt.Errorf("missing %s", key)
+	}
+	return strconv.ParseUint(v, 10, 64)
+}
+
+// payloadID returns the ID address of the actor ID held by `key`.
+func payloadID(e *event, key string) (string, error) {
+	id, err := payloadUint(e, key)
+	if err != nil {
+		return "", err
+	}
+	addr, err := address.NewIDAddress(id)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package ddopieces
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/chain/actors/builtin/market"
+	"github.com/filecoin-project/lily/lens/util"
+	"github.com/filecoin-project/lily/testutil"
+	tutils "github.com/filecoin-project/specs-actors/support/testing"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func newEvent(t *testing.T, eventType string, emitter uint64, entries ...string) *event {
+	e := &event{
+		eventType: eventType,
+		emitter:   tutils.NewIDAddr(t, emitter),
+		msgCid:    testutil.RandomCid(),
+		payload:   map[string]interface{}{},
+	}
+	for i := 0; i < len(entries); i += 2 {
+		e.entries = append(e.entries, &util.KVEvent{Key: entries[i], Value: entries[i+1]})
+		e.payload[entries[i]] = entries[i+1]
+	}
+	return e
+}
+
+func TestDDOPieces(t *testing.T) {
+	bh := testutil.FakeBlockHeader(t, 100, testutil.RandomCid())
+	executed, err := types.NewTipSet([]*types.BlockHeader{bh})
+	require.NoError(t, err)
+
+	verifiedPiece, dealPiece, plainPiece := testutil.RandomCid(), testutil.RandomCid(), testutil.RandomCid()
+
+	activated := newEvent(t, sectorActivated, 1000,
+		"sector", "7",
+		"unsealed-cid", testutil.RandomCid().String(),
+		"piece-cid", verifiedPiece.String(), "piece-size", "2048",
+		"piece-cid", dealPiece.String(), "piece-size", "1024",
+	)
+	claimed := newEvent(t, claimEvent, 6,
+		"id", "55", "client", "2000", "provider", "1000", "piece-cid", verifiedPiece.String(), "piece-size", "2048", "sector", "7",
+	)
+	dealt := newEvent(t, dealActivated, 5, "id", "0", "client", "3000", "provider", "1000")
+	dealt.msgCid = activated.msgCid
+	updated := newEvent(t, sectorUpdated, 1001,
+		"sector", "9",
+		"unsealed-cid", testutil.RandomCid().String(),
+		"piece-cid", plainPiece.String(), "piece-size", "4096",
+	)
+
+	proposal := func(id abi.DealID) (*market.DealProposal, bool, error) {
+		require.EqualValues(t, 0, id)
+		return &market.DealProposal{PieceCID: dealPiece}, true, nil
+	}
+
+	out, errs := ddoPieces(executed, []*event{activated, claimed, dealt, updated}, proposal)
+	require.Empty(t, errs)
+	require.Len(t, out, 3)
+
+	v := out[0]
+	require.EqualValues(t, 100, v.Height)
+	require.Equal(t, "f01000", v.Miner)
+	require.EqualValues(t, 7, v.SectorID)
+	require.Equal(t, verifiedPiece.String(), v.PieceCID)
+	require.EqualValues(t, 2048, v.PieceSize)
+	require.True(t, v.Verified)
+	require.EqualValues(t, 55, *v.ClaimID)
+	require.Nil(t, v.DealID)
+	require.Equal(t, "f02000", v.Client)
+
+	d := out[1]
+	require.Equal(t, dealPiece.String(), d.PieceCID)
+	require.False(t, d.Verified)
+	require.Nil(t, d.ClaimID)
+	require.EqualValues(t, 0, *d.DealID)
+	require.Equal(t, "f03000", d.Client)
+
+	p := out[2]
+	require.Equal(t, sectorUpdated, p.EventType)
+	require.Equal(t, "f01001", p.Miner)
+	require.EqualValues(t, 9, p.SectorID)
+	require.EqualValues(t, 4096, p.PieceSize)
+	require.False(t, p.Verified)
+	require.Nil(t, p.ClaimID)
+	require.Nil(t, p.DealID)
+	require.Empty(t, p.Client)
+}
@@ -0,0 +1,203 @@
+package gasfeepercentiles
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/lily/chain/actors/builtin"
+	"github.com/filecoin-project/lily/lens"
+	"github.com/filecoin-project/lily/lens/util"
+	"github.com/filecoin-project/lily/model"
+	derivedmodel "github.com/filecoin-project/lily/model/derived"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+func (t *Task) ProcessTipSets(ctx context.Context, current *types.TipSet, executed *types.TipSet) (model.Persistable, *visormodel.ProcessingReport, error) {
+	ctx, span := otel.Tracer("").Start(ctx, "ProcessTipSets")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("executed", executed.String()),
+			attribute.Int64("executed_height", int64(executed.Height())),
+			attribute.String("processor", "gas_fee_percentiles"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	grp, grpCtx := errgroup.WithContext(ctx)
+
+	var getActorCodeFn func(ctx context.Context, address address.Address) (cid.Cid, bool)
+	grp.Go(func() error {
+		var err error
+		getActorCodeFn, err = util.MakeGetActorCodeFunc(grpCtx, t.node.Store(), current, executed)
+		if err != nil {
+			return fmt.Errorf("getting actor code lookup function: %w", err)
+		}
+		return nil
+	})
+
+	var blkMsgRec []*lens.BlockMessageReceipts
+	grp.Go(func() error {
+		var err error
+		blkMsgRec, err = t.node.TipSetMessageReceipts(grpCtx, current, executed)
+		if err != nil {
+			return fmt.Errorf("getting messages and receipts: %w", err)
+		}
+		return nil
+	})
+
+	if err := grp.Wait(); err != nil {
+		report.ErrorsDetected = err
+		return nil, report, nil
+	}
+
+	// the messages of every block of the executed tipset pay the same base fee.
+	baseFee := executed.Blocks()[0].ParentBaseFee
+	all := &prices{}
+	groups := map[groupKey]*prices{}
+	exeMsgSeen := map[cid.Cid]bool{}
+	for _, msgrec := range blkMsgRec {
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("context done: %w", ctx.Err())
+		default:
+		}
+
+		itr, err := msgrec.Iterator()
+		if err != nil {
+			return nil, nil, err
+		}
+		for itr.HasNext() {
+			m, _, _ := itr.Next()
+			if exeMsgSeen[m.Cid()] {
+				continue
+			}
+			exeMsgSeen[m.Cid()] = true
+
+			msg := m.VMMessage()
+			toActorCode, found := getActorCodeFn(ctx, msg.To)
+			if !found {
+				toActorCode = cid.Undef
+			}
+			key := groupKey{family: builtin.ActorFamily(builtin.ActorNameByCode(toActorCode)), method: int64(msg.Method)}
+			if groups[key] == nil {
+				groups[key] = &prices{}
+			}
+			groups[key].add(msg, baseFee)
+			all.add(msg, baseFee)
+		}
+	}
+
+	if all.count() == 0 {
+		return derivedmodel.GasFeePercentilesList{}, report, nil
+	}
+
+	keys := make([]groupKey, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].family != keys[j].family {
+			return keys[i].family < keys[j].family
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	height, stateRoot := int64(executed.Height()), executed.ParentState().String()
+	out := make(derivedmodel.GasFeePercentilesList, 0, len(groups)+1)
+	out = append(out, all.percentiles(height, stateRoot, derivedmodel.GasFeePercentilesAllFamily, derivedmodel.GasFeePercentilesAllMethod, baseFee))
+	for _, k := range keys {
+		out = append(out, groups[k].percentiles(height, stateRoot, k.family, k.method, baseFee))
+	}
+	return out, report, nil
+}
+
+type groupKey struct {
+	family string
+	method int64
+}
+
+// prices collects the gas prices of a group of messages.
+type prices struct {
+	effective    []big.Int
+	premium      []big.Int
+	feeCap       []big.Int
+	belowBaseFee int64
+}
+
+func (p *prices) add(msg *types.Message, baseFee abi.TokenAmount) {
+	p.effective = append(p.effective, big.Min(msg.GasFeeCap, big.Add(baseFee, msg.GasPremium)))
+	p.premium = append(p.premium, msg.GasPremium)
+	p.feeCap = append(p.feeCap, msg.GasFeeCap)
+	if msg.GasFeeCap.LessThan(baseFee) {
+		p.belowBaseFee++
+	}
+}
+
+func (p *prices) count() int {
+	return len(p.feeCap)
+}
+
+func (p *prices) percentiles(height int64, stateRoot string, family string, method int64, baseFee abi.TokenAmount) *derivedmodel.GasFeePercentiles {
+	for _, s := range [][]big.Int{p.effective, p.premium, p.feeCap} {
+		sort.Slice(s, func(i, j int) bool { return s[i].LessThan(s[j]) })
+	}
+	return &derivedmodel.GasFeePercentiles{
+		Height:               height,
+		StateRoot:            stateRoot,
+		ActorFamily:          family,
+		Method:               method,
+		BaseFee:              baseFee.String(),
+		MessageCount:         int64(p.count()),
+		BelowBaseFeeCount:    p.belowBaseFee,
+		EffectiveGasPriceP10: percentile(p.effective, 10).String(),
+		EffectiveGasPriceP50: percentile(p.effective, 50).String(),
+		EffectiveGasPriceP90: percentile(p.effective, 90).String(),
+		EffectiveGasPriceP99: percentile(p.effective, 99).String(),
+		GasPremiumP10:        percentile(p.premium, 10).String(),
+		GasPremiumP50:        percentile(p.premium, 50).String(),
+		GasPremiumP90:        percentile(p.premium, 90).String(),
+		GasPremiumP99:        percentile(p.premium, 99).String(),
+		GasFeeCapP10:         percentile(p.feeCap, 10).String(),
+		GasFeeCapP50:         percentile(p.feeCap, 50).String(),
+		GasFeeCapP90:         percentile(p.feeCap, 90).String(),
+		GasFeeCapP99:         percentile(p.feeCap, 99).String(),
+	}
+}
+
+// percentile returns the nearest-rank percentile `p` of the non-empty ascending values `sorted`.
+func percentile(sorted []big.Int, p int) big.Int {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,47 @@
+package gasfeepercentiles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestPercentile(t *testing.T) {
+	var values []big.Int
+	for i := int64(1); i <= 200; i++ {
+		values = append(values, big.NewInt(i))
+	}
+	require.EqualValues(t, 20, percentile(values, 10).Int64())
+	require.EqualValues(t, 100, percentile(values, 50).Int64())
+	require.EqualValues(t, 180, percentile(values, 90).Int64())
+	require.EqualValues(t, 198, percentile(values, 99).Int64())
+
+	require.EqualValues(t, 7, percentile([]big.Int{big.NewInt(7)}, 10).Int64())
+	require.EqualValues(t, 7, percentile([]big.Int{big.NewInt(7)}, 99).Int64())
+}
+
+func TestPrices(t *testing.T) {
+	baseFee := abi.NewTokenAmount(100)
+	p := &prices{}
+	for _, m := range []struct{ feeCap, premium int64 }{
+		{feeCap: 150, premium: 10}, // pays base fee plus premium
+		{feeCap: 105, premium: 10}, // capped by the fee cap
+		{feeCap: 90, premium: 10},  // below the base fee
+	} {
+		p.add(&types.Message{GasFeeCap: abi.NewTokenAmount(m.feeCap), GasPremium: abi.NewTokenAmount(m.premium)}, baseFee)
+	}
+
+	got := p.percentiles(10, "root", "account", 0, baseFee)
+	require.EqualValues(t, 3, got.MessageCount)
+	require.EqualValues(t, 1, got.BelowBaseFeeCount)
+	require.Equal(t, "90", got.EffectiveGasPriceP10)
+	require.Equal(t, "105", got.EffectiveGasPriceP50)
+	require.Equal(t, "110", got.EffectiveGasPriceP99)
+	require.Equal(t, "150", got.GasFeeCapP99)
+	require.Equal(t, "10", got.GasPremiumP50)
+}
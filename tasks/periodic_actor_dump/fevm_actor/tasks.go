@@ -98,21 +98,23 @@ func (p *Task) ProcessPeriodicActorDump(ctx context.Context, current *types.TipS
 
 	}
 
-	for _, actor := range append(actors[manifest.EthAccountKey], actors[manifest.PlaceholderKey]...) {
-		ethAddress, err := ethtypes.EthAddressFromFilecoinAddress(*actor.DelegatedAddress)
-		if err != nil {
-			log.Errorf("Error at getting eth address: [actor cid: %v] err: %v", actor.Code.String(), err)
-			errs = append(errs, err)
-			continue
+	for _, family := range []string{manifest.EthAccountKey, manifest.PlaceholderKey} {
+		for _, actor := range actors[family] {
+			ethAddress, err := ethtypes.EthAddressFromFilecoinAddress(*actor.DelegatedAddress)
+			if err != nil {
+				log.Errorf("Error at getting eth address: [actor cid: %v] err: %v", actor.Code.String(), err)
+				errs = append(errs, err)
+				continue
+			}
+			out = append(out, &actordumps.FEVMActorDump{
+				Height:     int64(current.Height()),
+				ActorID:    actor.DelegatedAddress.String(),
+				ActorName:  builtin.ActorNameByCode(actor.Code),
+				EthAddress: ethAddress.String(),
+				Balance:    actor.Balance.String(),
+				Nonce:      actor.Nonce,
+			})
 		}
-		out = append(out, &actordumps.FEVMActorDump{
-			Height:     int64(current.Height()),
-			ActorID:    actor.DelegatedAddress.String(),
-			ActorName:  builtin.ActorNameByCode(actor.Code),
-			EthAddress: ethAddress.String(),
-			Balance:    actor.Balance.String(),
-			Nonce:      actor.Nonce,
-		})
 	}
 
 	if len(errs) > 0 {
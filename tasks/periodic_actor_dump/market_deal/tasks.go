@@ -0,0 +1,132 @@
+package marketdealdump
+
+import (
+	"context"
+	"fmt"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/manifest"
+
+	"github.com/filecoin-project/lily/chain/actors/builtin/market"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/actordumps"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/marketdealdump")
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+func (p *Task) ProcessPeriodicActorDump(ctx context.Context, current *types.TipSet, actors tasks.ActorStatesByType) (model.Persistable, *visormodel.ProcessingReport, error) {
+	_, span := otel.Tracer("").Start(ctx, "ProcessPeriodicActorDump")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("processor", "market_deal_dump"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	out := make(actordumps.MarketDealDumpList, 0)
+	errs := []error{}
+	for _, actor := range actors[manifest.MarketKey] {
+		marketState, err := market.Load(p.node.Store(), actor)
+		if err != nil {
+			log.Errorf("Error at loading market state: [actor cid: %v] err: %v", actor.Code.String(), err)
+			errs = append(errs, err)
+			continue
+		}
+
+		dumps, err := p.dumpDeals(ctx, current, marketState)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = append(out, dumps...)
+	}
+
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+
+	return out, report, nil
+}
+
+// dumpDeals returns a dump of every deal proposal of `marketState` joined with the state of the deal, deals that are
+// not yet activated have no state and are dumped with sentinel epochs of -1.
+func (p *Task) dumpDeals(ctx context.Context, current *types.TipSet, marketState market.State) (actordumps.MarketDealDumpList, error) {
+	proposals, err := marketState.Proposals()
+	if err != nil {
+		return nil, fmt.Errorf("loading market deal proposals: %w", err)
+	}
+	states, err := marketState.States()
+	if err != nil {
+		return nil, fmt.Errorf("loading market deal states: %w", err)
+	}
+
+	out := make(actordumps.MarketDealDumpList, 0)
+	err = proposals.ForEach(func(id abi.DealID, dp market.DealProposal) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		dump := &actordumps.MarketDealDump{
+			Height:               int64(current.Height()),
+			DealID:               uint64(id),
+			StateRoot:            current.ParentState().String(),
+			PieceCID:             dp.PieceCID.String(),
+			PaddedPieceSize:      uint64(dp.PieceSize),
+			UnpaddedPieceSize:    uint64(dp.PieceSize.Unpadded()),
+			IsVerified:           dp.VerifiedDeal,
+			ClientID:             dp.Client.String(),
+			ProviderID:           dp.Provider.String(),
+			StartEpoch:           int64(dp.StartEpoch),
+			EndEpoch:             int64(dp.EndEpoch),
+			StoragePricePerEpoch: dp.StoragePricePerEpoch.String(),
+			ProviderCollateral:   dp.ProviderCollateral.String(),
+			ClientCollateral:     dp.ClientCollateral.String(),
+			SectorStartEpoch:     -1,
+			LastUpdateEpoch:      -1,
+			SlashEpoch:           -1,
+		}
+
+		ds, found, err := states.Get(id)
+		if err != nil {
+			return fmt.Errorf("loading state of deal %d: %w", id, err)
+		}
+		if found {
+			dump.SectorID = uint64(ds.SectorNumber())
+			dump.SectorStartEpoch = int64(ds.SectorStartEpoch())
+			dump.LastUpdateEpoch = int64(ds.LastUpdatedEpoch())
+			dump.SlashEpoch = int64(ds.SlashEpoch())
+		}
+
+		out = append(out, dump)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,136 @@
+package multisigactordump
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/manifest"
+
+	"github.com/filecoin-project/lily/chain/actors/builtin/multisig"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/actordumps"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/multisigactordump")
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+func (p *Task) ProcessPeriodicActorDump(ctx context.Context, current *types.TipSet, actors tasks.ActorStatesByType) (model.Persistable, *visormodel.ProcessingReport, error) {
+	_, span := otel.Tracer("").Start(ctx, "ProcessPeriodicActorDump")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("processor", "multisig_actor_dump"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	out := make(actordumps.MultisigActorDumpList, 0)
+	errs := []error{}
+	for addr, actor := range actors[manifest.MultisigKey] {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		dump, err := p.dumpMultisig(current, addr, actor)
+		if err != nil {
+			log.Errorf("Error at dumping multisig state: [actor: %v] err: %v", addr.String(), err)
+			errs = append(errs, err)
+			continue
+		}
+		out = append(out, dump)
+	}
+
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+
+	return out, report, nil
+}
+
+func (p *Task) dumpMultisig(current *types.TipSet, addr address.Address, actor *types.ActorV5) (*actordumps.MultisigActorDump, error) {
+	msigState, err := multisig.Load(p.node.Store(), actor)
+	if err != nil {
+		return nil, fmt.Errorf("loading multisig state: %w", err)
+	}
+
+	signers, err := msigState.Signers()
+	if err != nil {
+		return nil, fmt.Errorf("loading multisig signers: %w", err)
+	}
+	signerIDs := make([]string, len(signers))
+	for i, s := range signers {
+		signerIDs[i] = s.String()
+	}
+	signersJSON, err := json.Marshal(signerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, err := msigState.Threshold()
+	if err != nil {
+		return nil, fmt.Errorf("loading multisig threshold: %w", err)
+	}
+	initialBalance, err := msigState.InitialBalance()
+	if err != nil {
+		return nil, fmt.Errorf("loading multisig initial balance: %w", err)
+	}
+	lockedBalance, err := msigState.LockedBalance(current.Height())
+	if err != nil {
+		return nil, fmt.Errorf("loading multisig locked balance: %w", err)
+	}
+	startEpoch, err := msigState.StartEpoch()
+	if err != nil {
+		return nil, fmt.Errorf("loading multisig start epoch: %w", err)
+	}
+	unlockDuration, err := msigState.UnlockDuration()
+	if err != nil {
+		return nil, fmt.Errorf("loading multisig unlock duration: %w", err)
+	}
+
+	var pending int64
+	if err := msigState.ForEachPendingTxn(func(_ int64, _ multisig.Transaction) error {
+		pending++
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("loading multisig pending transactions: %w", err)
+	}
+
+	return &actordumps.MultisigActorDump{
+		Height:              int64(current.Height()),
+		MultisigID:          addr.String(),
+		StateRoot:           current.ParentState().String(),
+		Balance:             actor.Balance.String(),
+		Signers:             string(signersJSON),
+		Threshold:           threshold,
+		InitialBalance:      initialBalance.String(),
+		LockedBalance:       lockedBalance.String(),
+		StartEpoch:          int64(startEpoch),
+		UnlockDuration:      int64(unlockDuration),
+		PendingTransactions: pending,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package verifiedregistrydump
+
+import (
+	"context"
+	"fmt"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/manifest"
+
+	"github.com/filecoin-project/lily/chain/actors/builtin/verifreg"
+	"github.com/filecoin-project/lily/model"
+	"github.com/filecoin-project/lily/model/actordumps"
+	visormodel "github.com/filecoin-project/lily/model/visor"
+	"github.com/filecoin-project/lily/tasks"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/verifiedregistrydump")
+
+type Task struct {
+	node tasks.DataSource
+}
+
+func NewTask(node tasks.DataSource) *Task {
+	return &Task{
+		node: node,
+	}
+}
+
+func (p *Task) ProcessPeriodicActorDump(ctx context.Context, current *types.TipSet, actors tasks.ActorStatesByType) (model.Persistable, *visormodel.ProcessingReport, error) {
+	_, span := otel.Tracer("").Start(ctx, "ProcessPeriodicActorDump")
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("current", current.String()),
+			attribute.Int64("current_height", int64(current.Height())),
+			attribute.String("processor", "verified_registry_dump"),
+		)
+	}
+	defer span.End()
+
+	report := &visormodel.ProcessingReport{
+		Height:    int64(current.Height()),
+		StateRoot: current.ParentState().String(),
+	}
+
+	out := make(actordumps.VerifiedRegistryDumpList, 0)
+	errs := []error{}
+	for _, actor := range actors[manifest.VerifregKey] {
+		verifregState, err := verifreg.Load(p.node.Store(), actor)
+		if err != nil {
+			log.Errorf("Error at loading verified registry state: [actor cid: %v] err: %v", actor.Code.String(), err)
+			errs = append(errs, err)
+			continue
+		}
+
+		dump := func(kind string) func(addr address.Address, dcap abi.StoragePower) error {
+			return func(addr address.Address, dcap abi.StoragePower) error {
+				out = append(out, &actordumps.VerifiedRegistryDump{
+					Height:    int64(current.Height()),
+					Address:   addr.String(),
+					Kind:      kind,
+					StateRoot: current.ParentState().String(),
+					DataCap:   dcap.String(),
+				})
+				return nil
+			}
+		}
+
+		if err := verifregState.ForEachVerifier(dump(actordumps.VerifiedRegistryKindVerifier)); err != nil {
+			errs = append(errs, err)
+		}
+
+		// since actors v9 the DataCap of clients is held by the datacap actor, see the data_cap_balance_dumps task.
+		if verifregState.ActorVersion() < actorstypes.Version9 {
+			if err := verifregState.ForEachClient(dump(actordumps.VerifiedRegistryKindClient)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		report.ErrorsDetected = fmt.Errorf("%v", errs)
+	}
+
+	return out, report, nil
+}
@@ -0,0 +1,203 @@
+package blockpropagation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/chain/gossip"
+	"github.com/filecoin-project/lily/model"
+	observed "github.com/filecoin-project/lily/model/surveyed"
+
+	"github.com/filecoin-project/lotus/build/buildconstants"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/blockpropagation")
+
+type API interface {
+	ID(ctx context.Context) (peer.ID, error)
+	ChainHead(context.Context) (*types.TipSet, error)
+	ChainGetGenesis(context.Context) (*types.TipSet, error)
+	ChainGetTipSetByHeight(context.Context, abi.ChainEpoch, types.TipSetKey) (*types.TipSet, error)
+	BlockGossip(ctx context.Context) (<-chan *gossip.Block, error)
+}
+
+func NewTask(api API) *Task {
+	return &Task{
+		api: api,
+	}
+}
+
+// Task observes blocks as they are received over gossipsub and, once their epoch is final, reports each block with
+// whether it was included in the canonical chain. Blocks are only observed while the surveyer is running, blocks
+// that have not reached finality when the task is closed are not reported.
+type Task struct {
+	api API
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	pending map[string]*observed.BlockPropagation
+}
+
+func (t *Task) Process(ctx context.Context) (model.Persistable, error) {
+	if err := t.subscribe(ctx); err != nil {
+		return nil, err
+	}
+
+	head, err := t.api.ChainHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting chain head: %w", err)
+	}
+	finalized := head.Height() - policy.ChainFinality
+
+	// group the blocks of final epochs by height so the canonical tipset of each height is loaded once.
+	byHeight := map[int64][]*observed.BlockPropagation{}
+	t.mu.Lock()
+	for _, bp := range t.pending {
+		if bp.Height <= int64(finalized) {
+			byHeight[bp.Height] = append(byHeight[bp.Height], bp)
+		}
+	}
+	t.mu.Unlock()
+
+	heights := make([]int64, 0, len(byHeight))
+	for h := range byHeight {
+		heights = append(heights, h)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+
+	var out observed.BlockPropagationList
+	for _, h := range heights {
+		ts, err := t.api.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(h), head.Key())
+		if err != nil {
+			return nil, fmt.Errorf("getting canonical tipset at height %d: %w", h, err)
+		}
+		// a null round returns the tipset before it, none of the blocks observed at the height are canonical.
+		canonical := map[string]struct{}{}
+		if int64(ts.Height()) == h {
+			for _, c := range ts.Cids() {
+				canonical[c.String()] = struct{}{}
+			}
+		}
+
+		t.mu.Lock()
+		for _, bp := range byHeight[h] {
+			_, bp.Canonical = canonical[bp.Cid]
+			out = append(out, bp)
+			delete(t.pending, bp.Cid)
+		}
+		t.mu.Unlock()
+	}
+
+	log.Infow("block propagation survey complete", "blocks", len(out), "heights", len(heights))
+	return out, nil
+}
+
+// Close stops observing blocks. The task resumes observing blocks the next time it is processed.
+func (t *Task) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+	t.pending = nil
+	return nil
+}
+
+// subscribe starts observing gossiped blocks if the task is not already doing so.
+func (t *Task) subscribe(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		return nil
+	}
+
+	pid, err := t.api.ID(ctx)
+	if err != nil {
+		return fmt.Errorf("get peer id: %w", err)
+	}
+	genesis, err := t.api.ChainGetGenesis(ctx)
+	if err != nil {
+		return fmt.Errorf("getting genesis: %w", err)
+	}
+
+	// the subscription outlives the context of a single survey, it is canceled when the task is closed.
+	subCtx, cancel := context.WithCancel(context.Background())
+	blocks, err := t.api.BlockGossip(subCtx)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("subscribing to gossiped blocks: %w", err)
+	}
+
+	t.cancel = cancel
+	if t.pending == nil {
+		t.pending = map[string]*observed.BlockPropagation{}
+	}
+	go t.observe(subCtx, blocks, pid, genesis.MinTimestamp())
+	return nil
+}
+
+func (t *Task) observe(ctx context.Context, blocks <-chan *gossip.Block, pid peer.ID, genesisTime uint64) {
+	for {
+		var blk *gossip.Block
+		var ok bool
+		select {
+		case blk, ok = <-blocks:
+		case <-ctx.Done():
+			return
+		}
+		if !ok {
+			if ctx.Err() != nil {
+				return
+			}
+			// resubscribe on the next survey, keeping the blocks observed so far.
+			log.Errorw("gossiped block subscription closed")
+			t.mu.Lock()
+			if t.cancel != nil {
+				t.cancel()
+				t.cancel = nil
+			}
+			t.mu.Unlock()
+			return
+		}
+		t.record(propagation(blk, pid, genesisTime))
+	}
+}
+
+// record adds `bp` to the blocks pending finality unless its block was already observed.
+func (t *Task) record(bp *observed.BlockPropagation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// pending is nil once the task has been closed.
+	if t.pending == nil {
+		return
+	}
+	if _, ok := t.pending[bp.Cid]; !ok {
+		t.pending[bp.Cid] = bp
+	}
+}
+
+// propagation returns the observation by peer `pid` of gossiped block `blk`, its offset is the time elapsed between
+// the expected start of the block's epoch and the block being received.
+func propagation(blk *gossip.Block, pid peer.ID, genesisTime uint64) *observed.BlockPropagation {
+	epochStart := time.Unix(int64(genesisTime+uint64(blk.Header.Height)*buildconstants.BlockDelaySecs), 0)
+	return &observed.BlockPropagation{
+		Height:         int64(blk.Header.Height),
+		Cid:            blk.Header.Cid().String(),
+		SurveyerPeerID: pid.String(),
+		Miner:          blk.Header.Miner.String(),
+		RelayPeerID:    blk.ReceivedFrom.String(),
+		FirstSeen:      blk.ReceivedAt,
+		EpochStart:     epochStart,
+		OffsetMs:       blk.ReceivedAt.Sub(epochStart).Milliseconds(),
+	}
+}
@@ -0,0 +1,156 @@
+package blockpropagation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/chain/gossip"
+	observed "github.com/filecoin-project/lily/model/surveyed"
+
+	"github.com/filecoin-project/lotus/build/buildconstants"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+const testGenesisTime = 1598306400
+
+func testBlock(t *testing.T, height abi.ChainEpoch, miner uint64) *types.BlockHeader {
+	c := cid.MustParse("bafy2bzacedrunl6pmqwxtmhr5cyjiy3p4qx5qqffjdwjzouyyzrirrycrabwe")
+	addr, err := address.NewIDAddress(miner)
+	require.NoError(t, err)
+	return &types.BlockHeader{
+		Miner:                 addr,
+		Height:                height,
+		ParentStateRoot:       c,
+		ParentMessageReceipts: c,
+		Messages:              c,
+		Ticket:                &types.Ticket{VRFProof: []byte{byte(miner)}},
+		ParentBaseFee:         abi.NewTokenAmount(100),
+		Timestamp:             testGenesisTime + uint64(height)*buildconstants.BlockDelaySecs,
+	}
+}
+
+func testTipSet(t *testing.T, blks ...*types.BlockHeader) *types.TipSet {
+	ts, err := types.NewTipSet(blks)
+	require.NoError(t, err)
+	return ts
+}
+
+func TestPropagation(t *testing.T) {
+	blk := testBlock(t, 10, 1000)
+	epochStart := time.Unix(testGenesisTime+10*int64(buildconstants.BlockDelaySecs), 0)
+
+	testCases := []struct {
+		name   string
+		seen   time.Time
+		offset int64
+	}{
+		{name: "at epoch start", seen: epochStart, offset: 0},
+		{name: "late", seen: epochStart.Add(2500 * time.Millisecond), offset: 2500},
+		{name: "early", seen: epochStart.Add(-300 * time.Millisecond), offset: -300},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gossiped := &gossip.Block{Header: blk, ReceivedFrom: peer.ID("relay"), ReceivedAt: tc.seen}
+			bp := propagation(gossiped, peer.ID("surveyer"), testGenesisTime)
+			require.Equal(t, &observed.BlockPropagation{
+				Height:         10,
+				Cid:            blk.Cid().String(),
+				SurveyerPeerID: peer.ID("surveyer").String(),
+				Miner:          "f01000",
+				RelayPeerID:    peer.ID("relay").String(),
+				FirstSeen:      tc.seen,
+				EpochStart:     epochStart,
+				OffsetMs:       tc.offset,
+			}, bp)
+		})
+	}
+}
+
+type testAPI struct {
+	head      *types.TipSet
+	genesis   *types.TipSet
+	canonical map[abi.ChainEpoch]*types.TipSet
+	blocks    chan *gossip.Block
+}
+
+func (a *testAPI) ID(context.Context) (peer.ID, error) { return peer.ID("surveyer"), nil }
+
+func (a *testAPI) ChainHead(context.Context) (*types.TipSet, error) { return a.head, nil }
+
+func (a *testAPI) ChainGetGenesis(context.Context) (*types.TipSet, error) { return a.genesis, nil }
+
+// ChainGetTipSetByHeight returns the tipset at `h`, or the tipset before it if `h` is a null round, like lotus does.
+func (a *testAPI) ChainGetTipSetByHeight(_ context.Context, h abi.ChainEpoch, _ types.TipSetKey) (*types.TipSet, error) {
+	for ; h > 0; h-- {
+		if ts, ok := a.canonical[h]; ok {
+			return ts, nil
+		}
+	}
+	return a.genesis, nil
+}
+
+func (a *testAPI) BlockGossip(context.Context) (<-chan *gossip.Block, error) {
+	return a.blocks, nil
+}
+
+func TestProcessReportsFinalBlocks(t *testing.T) {
+	canonical := testBlock(t, 10, 1000)
+	orphan := testBlock(t, 10, 1001)
+	nullRound := testBlock(t, 11, 1000)
+	recent := testBlock(t, 20, 1000)
+
+	head := testBlock(t, 19+policy.ChainFinality, 1000)
+	api := &testAPI{
+		head:    testTipSet(t, head),
+		genesis: testTipSet(t, testBlock(t, 0, 1000)),
+		canonical: map[abi.ChainEpoch]*types.TipSet{
+			10: testTipSet(t, canonical),
+			20: testTipSet(t, recent),
+		},
+		blocks: make(chan *gossip.Block),
+	}
+	task := NewTask(api)
+	defer func() { require.NoError(t, task.Close()) }()
+
+	// the first survey subscribes to gossiped blocks
+	out, err := task.Process(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, out)
+
+	// blocks received more than once are reported once
+	for _, blk := range []*types.BlockHeader{canonical, orphan, canonical, nullRound, recent} {
+		api.blocks <- &gossip.Block{Header: blk, ReceivedFrom: peer.ID("relay"), ReceivedAt: time.Now()}
+	}
+	require.Eventually(t, func() bool {
+		task.mu.Lock()
+		defer task.mu.Unlock()
+		return len(task.pending) == 4
+	}, 5*time.Second, time.Millisecond)
+
+	// only blocks of final epochs are reported
+	out, err = task.Process(context.Background())
+	require.NoError(t, err)
+	got := map[string]bool{}
+	for _, bp := range out.(observed.BlockPropagationList) {
+		got[bp.Cid] = bp.Canonical
+	}
+	require.Equal(t, map[string]bool{
+		canonical.Cid().String(): true,
+		orphan.Cid().String():    false,
+		nullRound.Cid().String(): false,
+	}, got)
+
+	// reported blocks are no longer pending
+	task.mu.Lock()
+	require.Len(t, task.pending, 1)
+	require.Contains(t, task.pending, recent.Cid().String())
+	task.mu.Unlock()
+}
@@ -0,0 +1,402 @@
+package consensusfaults
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v15/miner"
+	"github.com/filecoin-project/lily/model"
+	observed "github.com/filecoin-project/lily/model/surveyed"
+
+	lapi "github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/consensusfaults")
+
+// lookback is the number of scanned heights whose block headers are kept to be compared with the block headers of
+// later heights. Time offset and parent grinding faults involve blocks mined on the same tipset, which are only
+// separated by null rounds.
+const lookback = 20
+
+type API interface {
+	ID(ctx context.Context) (peer.ID, error)
+	ChainHead(context.Context) (*types.TipSet, error)
+	ChainGetTipSetByHeight(context.Context, abi.ChainEpoch, types.TipSetKey) (*types.TipSet, error)
+	ChainGetBlockMessages(context.Context, cid.Cid) (*lapi.BlockMessages, error)
+	SyncIncomingBlocks(ctx context.Context) (<-chan *types.BlockHeader, error)
+}
+
+func NewTask(api API) *Task {
+	return &Task{
+		api: api,
+	}
+}
+
+// Task observes the block headers received by the surveyer, including those that are never synced, and once their
+// epoch is final compares them with the block headers of the canonical chain to detect double fork, time offset and
+// parent grinding consensus faults. Faults reported to miner actors by ReportConsensusFault messages included in the
+// canonical chain are reported alongside. Blocks are only observed while the surveyer is running.
+type Task struct {
+	api API
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	pid    string
+	// blocks holds the block headers of each height that has not been scanned, and of the last lookback scanned heights.
+	blocks map[int64]map[cid.Cid]*block
+	// scanned is the height up to which the observed block headers have been compared.
+	scanned int64
+}
+
+type block struct {
+	header    *types.BlockHeader
+	canonical bool
+}
+
+func (t *Task) Process(ctx context.Context) (model.Persistable, error) {
+	if err := t.subscribe(ctx); err != nil {
+		return nil, err
+	}
+
+	head, err := t.api.ChainHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting chain head: %w", err)
+	}
+	finalized := int64(head.Height() - policy.ChainFinality)
+
+	t.mu.Lock()
+	from := t.scanned + 1
+	t.mu.Unlock()
+
+	// scanned only advances once every height has been compared so that a failed survey is retried in full.
+	now := time.Now()
+	var out observed.ConsensusFaultObservationList
+	for h := from; h <= finalized; h++ {
+		ts, err := t.api.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(h), head.Key())
+		if err != nil {
+			return nil, fmt.Errorf("getting canonical tipset at height %d: %w", h, err)
+		}
+		// a null round returns the tipset before it, which has already been scanned.
+		if int64(ts.Height()) == h {
+			t.addCanonical(ts)
+			reported, err := t.reportedFaults(ctx, head, ts, now)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, reported...)
+		}
+
+		t.mu.Lock()
+		out = append(out, t.detect(h, now)...)
+		t.mu.Unlock()
+	}
+
+	t.mu.Lock()
+	t.scanned = max(t.scanned, finalized)
+	for h := range t.blocks {
+		if h <= t.scanned-lookback {
+			delete(t.blocks, h)
+		}
+	}
+	t.mu.Unlock()
+
+	log.Infow("consensus faults survey complete", "faults", len(out), "scanned", finalized)
+	return out, nil
+}
+
+// Close stops observing blocks. The task resumes observing blocks the next time it is processed.
+func (t *Task) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+	t.blocks = nil
+	return nil
+}
+
+// detect returns the faults committed by the blocks at height `h` with the blocks at the same or earlier heights.
+// The caller must hold the lock.
+func (t *Task) detect(h int64, now time.Time) observed.ConsensusFaultObservationList {
+	var out observed.ConsensusFaultObservationList
+	current := sortedBlocks(t.blocks[h])
+	for i, b2 := range current {
+		for _, b1 := range current[:i] {
+			if fault := classify(b1.header, b2.header, nil); fault != "" {
+				out = append(out, t.observation(b1, b2, nil, fault, now))
+			}
+		}
+		for prev := h - 1; prev > h-lookback; prev-- {
+			earlier := sortedBlocks(t.blocks[prev])
+			for _, b1 := range earlier {
+				if fault := classify(b1.header, b2.header, nil); fault != "" {
+					out = append(out, t.observation(b1, b2, nil, fault, now))
+					continue
+				}
+				for _, extra := range earlier {
+					if fault := classify(b1.header, b2.header, extra.header); fault != "" {
+						out = append(out, t.observation(b1, b2, extra, fault, now))
+						break
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (t *Task) observation(b1, b2, extra *block, fault string, now time.Time) *observed.ConsensusFaultObservation {
+	obs := &observed.ConsensusFaultObservation{
+		Height:          int64(b1.header.Height),
+		Block1Cid:       b1.header.Cid().String(),
+		Block2Cid:       b2.header.Cid().String(),
+		Source:          observed.ConsensusFaultObserved,
+		SurveyerPeerID:  t.pid,
+		FaultType:       fault,
+		Miner:           b1.header.Miner.String(),
+		Block2Height:    int64(b2.header.Height),
+		Block1Canonical: b1.canonical,
+		Block2Canonical: b2.canonical,
+		ObservedAt:      now,
+	}
+	if extra != nil {
+		obs.BlockExtraCid = extra.header.Cid().String()
+	}
+	return obs
+}
+
+// reportedFaults returns the faults reported by the ReportConsensusFault messages included in `ts`. Messages whose
+// block headers do not form a consensus fault are ignored, they fail to execute.
+func (t *Task) reportedFaults(ctx context.Context, head, ts *types.TipSet, now time.Time) (observed.ConsensusFaultObservationList, error) {
+	var out observed.ConsensusFaultObservationList
+	seen := map[cid.Cid]struct{}{}
+	for _, blk := range ts.Blocks() {
+		msgs, err := t.api.ChainGetBlockMessages(ctx, blk.Cid())
+		if err != nil {
+			return nil, fmt.Errorf("getting messages of block %s: %w", blk.Cid(), err)
+		}
+		// Cids holds the CIDs of the bls messages followed by those of the secpk messages.
+		for i, c := range msgs.Cids {
+			var msg *types.Message
+			if i < len(msgs.BlsMessages) {
+				msg = msgs.BlsMessages[i]
+			} else {
+				msg = &msgs.SecpkMessages[i-len(msgs.BlsMessages)].Message
+			}
+			if msg.Method != builtin.MethodsMiner.ReportConsensusFault {
+				continue
+			}
+			if _, ok := seen[c]; ok {
+				continue
+			}
+			seen[c] = struct{}{}
+
+			obs, err := t.reportedFault(ctx, head, msg, now)
+			if err != nil {
+				log.Debugw("ignoring consensus fault report", "message", c, "error", err)
+				continue
+			}
+			if obs == nil {
+				continue
+			}
+			obs.ReportMessageCid = c.String()
+			obs.ReportHeight = int64(ts.Height())
+			out = append(out, obs)
+		}
+	}
+	return out, nil
+}
+
+func (t *Task) reportedFault(ctx context.Context, head *types.TipSet, msg *types.Message, now time.Time) (*observed.ConsensusFaultObservation, error) {
+	var params minertypes.ReportConsensusFaultParams
+	if err := params.UnmarshalCBOR(bytes.NewReader(msg.Params)); err != nil {
+		return nil, fmt.Errorf("decoding params: %w", err)
+	}
+	b1, err := types.DecodeBlock(params.BlockHeader1)
+	if err != nil {
+		return nil, fmt.Errorf("decoding first block header: %w", err)
+	}
+	b2, err := types.DecodeBlock(params.BlockHeader2)
+	if err != nil {
+		return nil, fmt.Errorf("decoding second block header: %w", err)
+	}
+	var extra *types.BlockHeader
+	if len(params.BlockHeaderExtra) > 0 {
+		if extra, err = types.DecodeBlock(params.BlockHeaderExtra); err != nil {
+			return nil, fmt.Errorf("decoding extra block header: %w", err)
+		}
+	}
+
+	fault := classify(b1, b2, extra)
+	if fault == "" {
+		return nil, nil
+	}
+
+	blk1, err := t.canonicalBlock(ctx, head, b1)
+	if err != nil {
+		return nil, err
+	}
+	blk2, err := t.canonicalBlock(ctx, head, b2)
+	if err != nil {
+		return nil, err
+	}
+	var blkExtra *block
+	if fault == observed.ConsensusFaultParentGrinding {
+		blkExtra = &block{header: extra}
+	}
+	obs := t.observation(blk1, blk2, blkExtra, fault, now)
+	obs.Source = observed.ConsensusFaultReported
+	obs.Reporter = msg.From.String()
+	return obs, nil
+}
+
+// canonicalBlock returns `bh` along with whether it is included in the canonical chain of `head`.
+func (t *Task) canonicalBlock(ctx context.Context, head *types.TipSet, bh *types.BlockHeader) (*block, error) {
+	ts, err := t.api.ChainGetTipSetByHeight(ctx, bh.Height, head.Key())
+	if err != nil {
+		return nil, fmt.Errorf("getting canonical tipset at height %d: %w", bh.Height, err)
+	}
+	return &block{
+		header:    bh,
+		canonical: ts.Height() == bh.Height && types.CidArrsContains(ts.Cids(), bh.Cid()),
+	}, nil
+}
+
+// classify returns the type of the consensus fault committed by the miner of `b1` and `b2`, or an empty string if
+// the blocks do not form a fault. `extra` is only considered for parent grinding faults and may be nil, it is the
+// block at the height of `b1` with the same parents that `b2` was mined on instead of `b1`.
+func classify(b1, b2, extra *types.BlockHeader) string {
+	if b1.Miner != b2.Miner || b1.Cid() == b2.Cid() {
+		return ""
+	}
+	if b1.Height == b2.Height {
+		return observed.ConsensusFaultDoubleFork
+	}
+	if types.CidArrsEqual(b1.Parents, b2.Parents) {
+		return observed.ConsensusFaultTimeOffset
+	}
+	if extra != nil && b1.Height == extra.Height && b1.Cid() != extra.Cid() && types.CidArrsEqual(b1.Parents, extra.Parents) &&
+		types.CidArrsContains(b2.Parents, extra.Cid()) && !types.CidArrsContains(b2.Parents, b1.Cid()) {
+		return observed.ConsensusFaultParentGrinding
+	}
+	return ""
+}
+
+func sortedBlocks(blocks map[cid.Cid]*block) []*block {
+	out := make([]*block, 0, len(blocks))
+	for _, b := range blocks {
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].header.Cid().KeyString() < out[j].header.Cid().KeyString() })
+	return out
+}
+
+// addCanonical adds the blocks of the canonical tipset `ts`, which may not have been observed.
+func (t *Task) addCanonical(ts *types.TipSet) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, bh := range ts.Blocks() {
+		t.addLocked(bh).canonical = true
+	}
+}
+
+// add records an observed block header, headers of heights that have already been scanned are ignored.
+func (t *Task) add(bh *types.BlockHeader) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// blocks is nil once the task has been closed.
+	if t.blocks == nil || int64(bh.Height) <= t.scanned {
+		return
+	}
+	t.addLocked(bh)
+}
+
+func (t *Task) addLocked(bh *types.BlockHeader) *block {
+	h := int64(bh.Height)
+	if t.blocks[h] == nil {
+		t.blocks[h] = map[cid.Cid]*block{}
+	}
+	b, ok := t.blocks[h][bh.Cid()]
+	if !ok {
+		b = &block{header: bh}
+		t.blocks[h][bh.Cid()] = b
+	}
+	return b
+}
+
+// subscribe starts observing incoming block headers if the task is not already doing so.
+func (t *Task) subscribe(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		return nil
+	}
+
+	pid, err := t.api.ID(ctx)
+	if err != nil {
+		return fmt.Errorf("get peer id: %w", err)
+	}
+	head, err := t.api.ChainHead(ctx)
+	if err != nil {
+		return fmt.Errorf("getting chain head: %w", err)
+	}
+
+	// the subscription outlives the context of a single survey, it is canceled when the task is closed.
+	subCtx, cancel := context.WithCancel(context.Background())
+	incoming, err := t.api.SyncIncomingBlocks(subCtx)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("subscribing to incoming blocks: %w", err)
+	}
+	t.cancel = cancel
+	t.pid = pid.String()
+	if t.blocks == nil {
+		// blocks observed from now on are mined after the current head.
+		t.blocks = map[int64]map[cid.Cid]*block{}
+		t.scanned = int64(head.Height())
+	}
+	go t.observe(subCtx, incoming)
+	return nil
+}
+
+func (t *Task) observe(ctx context.Context, incoming <-chan *types.BlockHeader) {
+	for {
+		var (
+			bh *types.BlockHeader
+			ok bool
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case bh, ok = <-incoming:
+		}
+		if !ok {
+			if ctx.Err() != nil {
+				return
+			}
+			// resubscribe on the next survey, keeping the blocks observed so far.
+			log.Errorw("incoming blocks subscription closed")
+			t.mu.Lock()
+			if t.cancel != nil {
+				t.cancel()
+				t.cancel = nil
+			}
+			t.mu.Unlock()
+			return
+		}
+		t.add(bh)
+	}
+}
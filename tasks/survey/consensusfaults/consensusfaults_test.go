@@ -0,0 +1,179 @@
+package consensusfaults
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v15/miner"
+	observed "github.com/filecoin-project/lily/model/surveyed"
+	"github.com/filecoin-project/lily/testutil"
+	tutils "github.com/filecoin-project/specs-actors/support/testing"
+
+	lapi "github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+type fakeAPI struct {
+	t        *testing.T
+	head     int64
+	blocks   map[int64]*types.TipSet
+	msgs     map[cid.Cid]*lapi.BlockMessages
+	incoming chan *types.BlockHeader
+	failAt   int64
+}
+
+func (f *fakeAPI) ID(context.Context) (peer.ID, error) { return "surveyer", nil }
+
+func (f *fakeAPI) ChainHead(context.Context) (*types.TipSet, error) { return f.tipset(f.head), nil }
+
+func (f *fakeAPI) ChainGetTipSetByHeight(_ context.Context, h abi.ChainEpoch, _ types.TipSetKey) (*types.TipSet, error) {
+	if f.failAt != 0 && int64(h) == f.failAt {
+		return nil, errors.New("tipset unavailable")
+	}
+	return f.tipset(int64(h)), nil
+}
+
+func (f *fakeAPI) ChainGetBlockMessages(_ context.Context, c cid.Cid) (*lapi.BlockMessages, error) {
+	if m, ok := f.msgs[c]; ok {
+		return m, nil
+	}
+	return &lapi.BlockMessages{}, nil
+}
+
+func (f *fakeAPI) SyncIncomingBlocks(context.Context) (<-chan *types.BlockHeader, error) {
+	return f.incoming, nil
+}
+
+// tipset returns the canonical tipset at height `h`, made of a single block mined by a miner unique to the height.
+func (f *fakeAPI) tipset(h int64) *types.TipSet {
+	if ts, ok := f.blocks[h]; ok {
+		return ts
+	}
+	ts, err := types.NewTipSet([]*types.BlockHeader{f.header(h, uint64(2000+h), testutil.RandomCid())})
+	require.NoError(f.t, err)
+	f.blocks[h] = ts
+	return ts
+}
+
+func (f *fakeAPI) header(h int64, miner uint64, parents ...cid.Cid) *types.BlockHeader {
+	bh := testutil.FakeBlockHeader(f.t, h, testutil.RandomCid())
+	bh.Miner = tutils.NewIDAddr(f.t, miner)
+	bh.Parents = parents
+	return bh
+}
+
+func TestConsensusFaults(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeAPI{
+		t:        t,
+		head:     10,
+		blocks:   map[int64]*types.TipSet{},
+		msgs:     map[cid.Cid]*lapi.BlockMessages{},
+		incoming: make(chan *types.BlockHeader),
+	}
+	task := NewTask(api)
+
+	out, err := task.Process(ctx)
+	require.NoError(t, err)
+	require.Empty(t, out)
+
+	// double fork: a second block from the miner of the canonical block at height 12.
+	canonical12 := api.tipset(12).Blocks()[0]
+	doubleFork := api.header(12, 2012, testutil.RandomCid())
+	task.add(doubleFork)
+
+	// time offset: a block at height 15 mined on the same parents as the canonical block at height 13.
+	canonical13 := api.tipset(13).Blocks()[0]
+	timeOffset := api.header(15, 2013, canonical13.Parents...)
+	task.add(timeOffset)
+
+	// parent grinding: the miner of the canonical block at height 16 mines at height 17 on a sibling of its own block.
+	canonical16 := api.tipset(16).Blocks()[0]
+	sibling := api.header(16, 99, canonical16.Parents...)
+	grinding := api.header(17, 2016, sibling.Cid())
+	task.add(sibling)
+	task.add(grinding)
+
+	// a double fork at height 11 reported by a message included at height 14.
+	reported1, reported2 := api.header(11, 77, testutil.RandomCid()), api.header(11, 77, testutil.RandomCid())
+	h1, err := reported1.Serialize()
+	require.NoError(t, err)
+	h2, err := reported2.Serialize()
+	require.NoError(t, err)
+	buf := new(bytes.Buffer)
+	require.NoError(t, (&minertypes.ReportConsensusFaultParams{BlockHeader1: h1, BlockHeader2: h2}).MarshalCBOR(buf))
+	report := &types.Message{
+		From:   tutils.NewIDAddr(t, 500),
+		To:     tutils.NewIDAddr(t, 77),
+		Method: builtin.MethodsMiner.ReportConsensusFault,
+		Params: buf.Bytes(),
+	}
+	api.msgs[api.tipset(14).Cids()[0]] = &lapi.BlockMessages{
+		BlsMessages: []*types.Message{report},
+		Cids:        []cid.Cid{report.Cid()},
+	}
+
+	// nothing is reported until the blocks are final.
+	api.head = 11 + int64(policy.ChainFinality)
+	out, err = task.Process(ctx)
+	require.NoError(t, err)
+	require.Empty(t, out)
+
+	// a survey that fails after the faults were compared reports them when it is retried.
+	api.head = 17 + int64(policy.ChainFinality)
+	api.failAt = 17
+	_, err = task.Process(ctx)
+	require.Error(t, err)
+
+	api.failAt = 0
+	out, err = task.Process(ctx)
+	require.NoError(t, err)
+	faults := out.(observed.ConsensusFaultObservationList)
+	require.Len(t, faults, 4)
+
+	byType := map[string]*observed.ConsensusFaultObservation{}
+	for _, f := range faults {
+		require.Equal(t, peer.ID("surveyer").String(), f.SurveyerPeerID)
+		byType[f.Source+"/"+f.FaultType] = f
+	}
+
+	df := byType[observed.ConsensusFaultObserved+"/"+observed.ConsensusFaultDoubleFork]
+	require.NotNil(t, df)
+	require.EqualValues(t, 12, df.Height)
+	require.ElementsMatch(t, []string{canonical12.Cid().String(), doubleFork.Cid().String()}, []string{df.Block1Cid, df.Block2Cid})
+	require.True(t, df.Block1Canonical != df.Block2Canonical)
+
+	to := byType[observed.ConsensusFaultObserved+"/"+observed.ConsensusFaultTimeOffset]
+	require.NotNil(t, to)
+	require.Equal(t, canonical13.Cid().String(), to.Block1Cid)
+	require.Equal(t, timeOffset.Cid().String(), to.Block2Cid)
+	require.EqualValues(t, 15, to.Block2Height)
+	require.True(t, to.Block1Canonical)
+	require.False(t, to.Block2Canonical)
+
+	pg := byType[observed.ConsensusFaultObserved+"/"+observed.ConsensusFaultParentGrinding]
+	require.NotNil(t, pg)
+	require.Equal(t, canonical16.Cid().String(), pg.Block1Cid)
+	require.Equal(t, grinding.Cid().String(), pg.Block2Cid)
+	require.Equal(t, sibling.Cid().String(), pg.BlockExtraCid)
+
+	rep := byType[observed.ConsensusFaultReported+"/"+observed.ConsensusFaultDoubleFork]
+	require.NotNil(t, rep)
+	require.EqualValues(t, 11, rep.Height)
+	require.Equal(t, reported1.Cid().String(), rep.Block1Cid)
+	require.Equal(t, report.Cid().String(), rep.ReportMessageCid)
+	require.EqualValues(t, 14, rep.ReportHeight)
+	require.Equal(t, report.From.String(), rep.Reporter)
+	require.False(t, rep.Block1Canonical)
+
+	require.NoError(t, task.Close())
+}
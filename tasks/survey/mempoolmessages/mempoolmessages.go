@@ -0,0 +1,288 @@
+package mempoolmessages
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lily/model"
+	observed "github.com/filecoin-project/lily/model/surveyed"
+
+	lapi "github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build/buildconstants"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/mempoolmessages")
+
+type API interface {
+	ID(ctx context.Context) (peer.ID, error)
+	ChainHead(context.Context) (*types.TipSet, error)
+	ChainGetGenesis(context.Context) (*types.TipSet, error)
+	ChainGetTipSetByHeight(context.Context, abi.ChainEpoch, types.TipSetKey) (*types.TipSet, error)
+	ChainGetBlockMessages(context.Context, cid.Cid) (*lapi.BlockMessages, error)
+	MpoolSub(context.Context) (<-chan lapi.MpoolUpdate, error)
+}
+
+func NewTask(api API) *Task {
+	return &Task{
+		api: api,
+	}
+}
+
+// Task observes messages as they are added to the mempool of the surveyer and, once the epoch they were included in
+// is final, reports each message with the height it was included at. A message is replaced if a different message
+// from the same sender with the same nonce was included instead, and dropped if neither was included within finality
+// of the message being first seen. Messages are only observed while the surveyer is running, messages that have not
+// been reported when the task is closed are not reported.
+type Task struct {
+	api API
+
+	mu          sync.Mutex
+	cancel      context.CancelFunc
+	pid         string
+	genesisTime uint64
+	pending     map[string]*observed.MempoolMessage
+	// scanned is the height up to which the messages of the canonical chain have been joined with pending messages.
+	scanned int64
+}
+
+func (t *Task) Process(ctx context.Context) (model.Persistable, error) {
+	if err := t.subscribe(ctx); err != nil {
+		return nil, err
+	}
+
+	head, err := t.api.ChainHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting chain head: %w", err)
+	}
+	finalized := int64(head.Height() - policy.ChainFinality)
+
+	t.mu.Lock()
+	from := t.scanned + 1
+	t.mu.Unlock()
+
+	// pending messages are only updated once every height has been scanned so that a failed survey is retried in full.
+	var out observed.MempoolMessageList
+	joined := map[string]struct{}{}
+	for h := from; h <= finalized; h++ {
+		ts, err := t.api.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(h), head.Key())
+		if err != nil {
+			return nil, fmt.Errorf("getting canonical tipset at height %d: %w", h, err)
+		}
+		// a null round returns the tipset before it, which has already been scanned.
+		if int64(ts.Height()) == h {
+			included, err := t.includedMessages(ctx, ts)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, t.join(ts, included, joined)...)
+		}
+	}
+
+	t.mu.Lock()
+	for c := range joined {
+		delete(t.pending, c)
+	}
+	t.scanned = max(t.scanned, finalized)
+	// messages not included within finality of being first seen are dropped.
+	for c, msg := range t.pending {
+		if msg.FirstSeenHeight+int64(policy.ChainFinality) <= finalized {
+			msg.Status = observed.MempoolMessageDropped
+			out = append(out, msg)
+			delete(t.pending, c)
+		}
+	}
+	pending := len(t.pending)
+	t.mu.Unlock()
+
+	log.Infow("mempool messages survey complete", "messages", len(out), "pending", pending, "scanned", finalized)
+	return out, nil
+}
+
+// Close stops observing messages. The task resumes observing messages the next time it is processed.
+func (t *Task) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		t.cancel()
+		t.cancel = nil
+	}
+	t.pending = nil
+	return nil
+}
+
+// includedMessages returns the CID of the message included in `ts` for each sender and nonce. Messages included in
+// more than one block of the tipset are only executed the first time they are included.
+func (t *Task) includedMessages(ctx context.Context, ts *types.TipSet) (map[string]cid.Cid, error) {
+	out := map[string]cid.Cid{}
+	for _, blk := range ts.Blocks() {
+		msgs, err := t.api.ChainGetBlockMessages(ctx, blk.Cid())
+		if err != nil {
+			return nil, fmt.Errorf("getting messages of block %s: %w", blk.Cid(), err)
+		}
+		// Cids holds the CIDs of the bls messages followed by those of the secpk messages.
+		for i, c := range msgs.Cids {
+			var msg *types.Message
+			if i < len(msgs.BlsMessages) {
+				msg = msgs.BlsMessages[i]
+			} else {
+				msg = &msgs.SecpkMessages[i-len(msgs.BlsMessages)].Message
+			}
+			if _, ok := out[senderNonce(msg)]; !ok {
+				out[senderNonce(msg)] = c
+			}
+		}
+	}
+	return out, nil
+}
+
+// join reports the pending messages included in `ts`, or replaced by a message included in `ts`, that are not in
+// `joined`, adding them to it. The pending messages are left unchanged, the messages reported are copies.
+func (t *Task) join(ts *types.TipSet, included map[string]cid.Cid, joined map[string]struct{}) observed.MempoolMessageList {
+	height := int64(ts.Height())
+	baseFee := ts.Blocks()[0].ParentBaseFee
+	epochStart := time.Unix(int64(t.genesisTime+uint64(height)*buildconstants.BlockDelaySecs), 0)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out observed.MempoolMessageList
+	for c, pending := range t.pending {
+		if _, ok := joined[c]; ok {
+			continue
+		}
+		inc, ok := included[fmt.Sprintf("%s/%d", pending.From, pending.Nonce)]
+		if !ok {
+			continue
+		}
+		msg := *pending
+		msg.Status = observed.MempoolMessageIncluded
+		if inc.String() != c {
+			msg.Status = observed.MempoolMessageReplaced
+		}
+		msg.IncludedCid = inc.String()
+		msg.InclusionHeight = height
+		msg.InclusionLatencyMs = epochStart.Sub(msg.FirstSeen).Milliseconds()
+		msg.InclusionBaseFee = baseFee.String()
+		if baseFee.Sign() > 0 {
+			feeCap, _ := new(big.Float).SetString(msg.GasFeeCap)
+			msg.FeeCapBaseFeeRatio, _ = new(big.Float).Quo(feeCap, new(big.Float).SetInt(baseFee.Int)).Float64()
+		}
+		out = append(out, &msg)
+		joined[c] = struct{}{}
+	}
+	return out
+}
+
+func senderNonce(msg *types.Message) string {
+	return fmt.Sprintf("%s/%d", msg.From, msg.Nonce)
+}
+
+// subscribe starts observing mempool messages if the task is not already doing so.
+func (t *Task) subscribe(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		return nil
+	}
+
+	pid, err := t.api.ID(ctx)
+	if err != nil {
+		return fmt.Errorf("get peer id: %w", err)
+	}
+	genesis, err := t.api.ChainGetGenesis(ctx)
+	if err != nil {
+		return fmt.Errorf("getting genesis: %w", err)
+	}
+	head, err := t.api.ChainHead(ctx)
+	if err != nil {
+		return fmt.Errorf("getting chain head: %w", err)
+	}
+
+	// the subscription outlives the context of a single survey, it is canceled when the task is closed.
+	subCtx, cancel := context.WithCancel(context.Background())
+	updates, err := t.api.MpoolSub(subCtx)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("subscribing to mempool: %w", err)
+	}
+	t.cancel = cancel
+	t.pid = pid.String()
+	t.genesisTime = genesis.MinTimestamp()
+	if t.pending == nil {
+		// messages observed from now on are included after the current head.
+		t.pending = map[string]*observed.MempoolMessage{}
+		t.scanned = int64(head.Height())
+	}
+	go t.observe(subCtx, updates)
+	return nil
+}
+
+// observe records the messages added to the mempool. Messages removed from the mempool are not recorded, the chain is
+// the source of truth of whether they were included.
+func (t *Task) observe(ctx context.Context, updates <-chan lapi.MpoolUpdate) {
+	for {
+		var (
+			u  lapi.MpoolUpdate
+			ok bool
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok = <-updates:
+		}
+		if !ok {
+			if ctx.Err() != nil {
+				return
+			}
+			// resubscribe on the next survey, keeping the messages observed so far.
+			log.Errorw("mempool subscription closed")
+			t.mu.Lock()
+			if t.cancel != nil {
+				t.cancel()
+				t.cancel = nil
+			}
+			t.mu.Unlock()
+			return
+		}
+		if u.Type != lapi.MpoolAdd || u.Message == nil {
+			continue
+		}
+		t.add(u.Message, time.Now())
+	}
+}
+
+func (t *Task) add(sm *types.SignedMessage, seen time.Time) {
+	c := sm.Cid().String()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// pending is nil once the task has been closed.
+	if t.pending == nil {
+		return
+	}
+	if _, ok := t.pending[c]; ok {
+		return
+	}
+	msg := sm.Message
+	t.pending[c] = &observed.MempoolMessage{
+		Cid:             c,
+		SurveyerPeerID:  t.pid,
+		From:            msg.From.String(),
+		Nonce:           msg.Nonce,
+		GasLimit:        msg.GasLimit,
+		GasFeeCap:       msg.GasFeeCap.String(),
+		GasPremium:      msg.GasPremium.String(),
+		FirstSeen:       seen,
+		FirstSeenHeight: (seen.Unix() - int64(t.genesisTime)) / int64(buildconstants.BlockDelaySecs),
+	}
+}
@@ -0,0 +1,153 @@
+package mempoolmessages
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+	observed "github.com/filecoin-project/lily/model/surveyed"
+	"github.com/filecoin-project/lily/testutil"
+	tutils "github.com/filecoin-project/specs-actors/support/testing"
+
+	lapi "github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+type fakeAPI struct {
+	t       *testing.T
+	head    int64
+	blocks  map[int64]*types.TipSet
+	msgs    map[cid.Cid]*lapi.BlockMessages
+	updates chan lapi.MpoolUpdate
+	failAt  int64
+}
+
+func (f *fakeAPI) ID(context.Context) (peer.ID, error) { return "surveyer", nil }
+
+func (f *fakeAPI) ChainHead(context.Context) (*types.TipSet, error) { return f.tipset(f.head), nil }
+
+func (f *fakeAPI) ChainGetGenesis(context.Context) (*types.TipSet, error) { return f.tipset(0), nil }
+
+func (f *fakeAPI) ChainGetTipSetByHeight(_ context.Context, h abi.ChainEpoch, _ types.TipSetKey) (*types.TipSet, error) {
+	if f.failAt != 0 && int64(h) == f.failAt {
+		return nil, errors.New("tipset unavailable")
+	}
+	return f.tipset(int64(h)), nil
+}
+
+func (f *fakeAPI) ChainGetBlockMessages(_ context.Context, c cid.Cid) (*lapi.BlockMessages, error) {
+	if m, ok := f.msgs[c]; ok {
+		return m, nil
+	}
+	return &lapi.BlockMessages{}, nil
+}
+
+func (f *fakeAPI) MpoolSub(context.Context) (<-chan lapi.MpoolUpdate, error) { return f.updates, nil }
+
+func (f *fakeAPI) tipset(h int64) *types.TipSet {
+	if ts, ok := f.blocks[h]; ok {
+		return ts
+	}
+	bh := testutil.FakeBlockHeader(f.t, h, testutil.RandomCid())
+	bh.ParentBaseFee = abi.NewTokenAmount(100)
+	ts, err := types.NewTipSet([]*types.BlockHeader{bh})
+	require.NoError(f.t, err)
+	f.blocks[h] = ts
+	return ts
+}
+
+func TestMempoolMessages(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeAPI{
+		t:       t,
+		head:    10,
+		blocks:  map[int64]*types.TipSet{},
+		msgs:    map[cid.Cid]*lapi.BlockMessages{},
+		updates: make(chan lapi.MpoolUpdate),
+	}
+	task := NewTask(api)
+
+	out, err := task.Process(ctx)
+	require.NoError(t, err)
+	require.Empty(t, out)
+
+	signed := func(nonce uint64, premium int64) *types.SignedMessage {
+		return &types.SignedMessage{
+			Message: types.Message{
+				From:       tutils.NewIDAddr(t, 1000),
+				To:         tutils.NewIDAddr(t, 1001),
+				Nonce:      nonce,
+				Value:      abi.NewTokenAmount(1),
+				GasLimit:   1000,
+				GasFeeCap:  abi.NewTokenAmount(250),
+				GasPremium: abi.NewTokenAmount(premium),
+			},
+			Signature: crypto.Signature{Type: crypto.SigTypeSecp256k1},
+		}
+	}
+	included, replaced, replacement, dropped := signed(1, 1), signed(2, 1), signed(2, 5), signed(3, 1)
+	seen := time.Unix(0, 0)
+	for _, sm := range []*types.SignedMessage{included, replaced, dropped} {
+		task.add(sm, seen)
+	}
+
+	// include the message and the replacement at height 12.
+	ts := api.tipset(12)
+	api.msgs[ts.Cids()[0]] = &lapi.BlockMessages{
+		SecpkMessages: []*types.SignedMessage{included, replacement},
+		Cids:          []cid.Cid{included.Cid(), replacement.Cid()},
+	}
+
+	// nothing is reported until the inclusion height is final.
+	api.head = 11 + int64(policy.ChainFinality)
+	out, err = task.Process(ctx)
+	require.NoError(t, err)
+	require.Empty(t, out)
+
+	// a survey that fails after the inclusion height was scanned reports the messages when it is retried.
+	api.head = 13 + int64(policy.ChainFinality)
+	api.failAt = 13
+	_, err = task.Process(ctx)
+	require.Error(t, err)
+
+	api.failAt = 0
+	out, err = task.Process(ctx)
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	byCid := map[string]*observed.MempoolMessage{}
+	for _, m := range out.(observed.MempoolMessageList) {
+		byCid[m.Cid] = m
+	}
+
+	inc := byCid[included.Cid().String()]
+	require.Equal(t, observed.MempoolMessageIncluded, inc.Status)
+	require.Equal(t, included.Cid().String(), inc.IncludedCid)
+	require.EqualValues(t, 12, inc.InclusionHeight)
+	require.Equal(t, "100", inc.InclusionBaseFee)
+	require.Equal(t, 2.5, inc.FeeCapBaseFeeRatio)
+	require.Equal(t, peer.ID("surveyer").String(), inc.SurveyerPeerID)
+
+	rep := byCid[replaced.Cid().String()]
+	require.Equal(t, observed.MempoolMessageReplaced, rep.Status)
+	require.Equal(t, replacement.Cid().String(), rep.IncludedCid)
+
+	// the remaining message is dropped once finality has passed since it was first seen.
+	api.head = 2*int64(policy.ChainFinality) + 13
+	out, err = task.Process(ctx)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	drop := out.(observed.MempoolMessageList)[0]
+	require.Equal(t, dropped.Cid().String(), drop.Cid)
+	require.Equal(t, observed.MempoolMessageDropped, drop.Status)
+	require.Zero(t, drop.InclusionHeight)
+
+	require.NoError(t, task.Close())
+}
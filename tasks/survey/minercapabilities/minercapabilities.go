@@ -0,0 +1,290 @@
+package minercapabilities
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gammazero/workerpool"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/multiformats/go-multiaddr"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lily/model"
+	observed "github.com/filecoin-project/lily/model/surveyed"
+
+	lapi "github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("lily/tasks/minercap")
+
+const (
+	// TransportsProtocolID is the protocol miners use to advertise the transports they serve retrievals over.
+	TransportsProtocolID = protocol.ID("/fil/retrieval/transports/1.0.0")
+
+	retrievalProtocolPrefix = "/fil/retrieval/"
+	dealProtocolPrefix      = "/fil/storage/mk/"
+	askProtocolPrefix       = "/fil/storage/ask/"
+
+	// maxTransportsResponseSize bounds the size of a transports response read from a miner.
+	maxTransportsResponseSize = 1 << 20
+)
+
+var (
+	workerPoolSizeEnv = "LILY_SURVEY_MINER_CAPABILITIES_WORKERS"
+	workerPoolSize    = 50
+
+	fetchTimeoutEnv = "LILY_SURVEY_MINER_CAPABILITIES_TIMEOUT_SECONDS"
+	fetchTimeout    = 30
+)
+
+func init() {
+	if s := os.Getenv(workerPoolSizeEnv); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err == nil {
+			workerPoolSize = int(v)
+		}
+	}
+	if s := os.Getenv(fetchTimeoutEnv); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err == nil {
+			fetchTimeout = int(v)
+		}
+	}
+}
+
+type API interface {
+	Host() host.Host
+	ChainHead(context.Context) (*types.TipSet, error)
+	StateMinerInfo(ctx context.Context, addr address.Address, tsk types.TipSetKey) (lapi.MinerInfo, error)
+	StateListMiners(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error)
+	StateMinerPower(context.Context, address.Address, types.TipSetKey) (*lapi.MinerPower, error)
+}
+
+func NewTask(api API) *Task {
+	return &Task{api: api}
+}
+
+// Task surveys the retrieval transports and the retrieval, storage deal and storage ask protocol versions of every miner
+// with min power. Unlike the minerprotocols task an observation is recorded for every miner surveyed, including the
+// reason a miner could not be surveyed.
+type Task struct {
+	api API
+}
+
+func (t *Task) Process(ctx context.Context) (model.Persistable, error) {
+	headTs, err := t.api.ChainHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting chain head: %w", err)
+	}
+
+	miners, err := t.api.StateListMiners(ctx, headTs.Key())
+	if err != nil {
+		return nil, fmt.Errorf("listing miners: %w", err)
+	}
+
+	start := time.Now()
+	queriedCount := 0
+	results := make(chan *observed.MinerCapability, workerPoolSize)
+	pool := workerpool.New(workerPoolSize)
+
+	for _, miner := range miners {
+		select {
+		case <-ctx.Done():
+			pool.Stop()
+			return nil, ctx.Err()
+		default:
+		}
+		miner := miner
+
+		mpower, err := t.api.StateMinerPower(ctx, miner, headTs.Key())
+		if err != nil {
+			return nil, fmt.Errorf("getting miner %s power: %w", miner, err)
+		}
+		// don't process miners without min power
+		if !mpower.HasMinPower {
+			continue
+		}
+
+		minerInfo, err := t.api.StateMinerInfo(ctx, miner, headTs.Key())
+		if err != nil {
+			return nil, fmt.Errorf("getting miner %s info: %w", miner, err)
+		}
+
+		queriedCount++
+		pool.Submit(func() {
+			fetchCtx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(fetchTimeout))
+			defer cancel()
+			results <- surveyMiner(fetchCtx, t.api.Host(), miner, minerInfo, start)
+		})
+	}
+
+	// wait for all workers to complete then close the results channel
+	go func() {
+		pool.StopWait()
+		close(results)
+	}()
+
+	out := make(observed.MinerCapabilityList, 0, queriedCount)
+	reachable := 0
+	for res := range results {
+		if res.Reachable {
+			reachable++
+		}
+		out = append(out, res)
+	}
+	log.Infow("miner capability survey complete", "duration", time.Since(start), "queried", queriedCount, "reachable", reachable)
+	return out, nil
+}
+
+func (t *Task) Close() error {
+	return nil
+}
+
+// surveyMiner connects to the miner and records the protocols and retrieval transports it supports. Failures are
+// recorded in the returned observation rather than returned.
+func surveyMiner(ctx context.Context, h host.Host, addr address.Address, minerInfo lapi.MinerInfo, start time.Time) *observed.MinerCapability {
+	obs := &observed.MinerCapability{
+		ObservedAt: start,
+		MinerID:    addr.String(),
+	}
+
+	if minerInfo.PeerId == nil {
+		obs.FailureReason = "no peer id set on-chain"
+		return obs
+	}
+	obs.PeerID = minerInfo.PeerId.String()
+
+	addrInfo, err := getMinerAddrInfo(*minerInfo.PeerId, minerInfo)
+	if err != nil {
+		obs.FailureReason = err.Error()
+		return obs
+	}
+
+	connectStart := time.Now()
+	if err := h.Connect(ctx, *addrInfo); err != nil {
+		obs.FailureReason = fmt.Sprintf("connecting: %s", err)
+		return obs
+	}
+	obs.ConnectLatencyMs = time.Since(connectStart).Milliseconds()
+	obs.Reachable = true
+
+	protos, err := h.Peerstore().GetProtocols(addrInfo.ID)
+	if err != nil {
+		obs.FailureReason = fmt.Sprintf("getting protocols: %s", err)
+		return obs
+	}
+
+	supportsTransports := false
+	for _, p := range protos {
+		ps := string(p)
+		switch {
+		case p == TransportsProtocolID:
+			supportsTransports = true
+			obs.RetrievalProtocols = append(obs.RetrievalProtocols, ps)
+		case strings.HasPrefix(ps, retrievalProtocolPrefix):
+			obs.RetrievalProtocols = append(obs.RetrievalProtocols, ps)
+		case strings.HasPrefix(ps, dealProtocolPrefix):
+			obs.DealProtocols = append(obs.DealProtocols, ps)
+		case strings.HasPrefix(ps, askProtocolPrefix):
+			obs.AskProtocols = append(obs.AskProtocols, ps)
+		}
+	}
+
+	if !supportsTransports {
+		return obs
+	}
+
+	transportsStart := time.Now()
+	transports, err := queryTransports(ctx, h, addrInfo.ID)
+	if err != nil {
+		obs.FailureReason = fmt.Sprintf("querying retrieval transports: %s", err)
+		return obs
+	}
+	obs.TransportsLatencyMs = time.Since(transportsStart).Milliseconds()
+	obs.RetrievalTransports = transports
+	return obs
+}
+
+// transportsResponse is the response to a query of the retrieval transports protocol.
+type transportsResponse struct {
+	Protocols []struct {
+		Name      string
+		Addresses [][]byte
+	}
+}
+
+// queryTransports queries the retrieval transports served by peer `p`.
+func queryTransports(ctx context.Context, h host.Host, p peer.ID) ([]observed.RetrievalTransport, error) {
+	s, err := h.NewStream(ctx, p, TransportsProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("opening stream: %w", err)
+	}
+	defer s.Close() // nolint: errcheck
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := s.SetReadDeadline(deadline); err != nil {
+			return nil, fmt.Errorf("setting read deadline: %w", err)
+		}
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(s, maxTransportsResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return decodeTransports(p, raw)
+}
+
+// decodeTransports decodes a CBOR encoded response of the retrieval transports protocol sent by peer `p`. Addresses
+// that are not valid multiaddrs are skipped.
+func decodeTransports(p peer.ID, raw []byte) ([]observed.RetrievalTransport, error) {
+	var resp transportsResponse
+	if err := cbor.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	out := make([]observed.RetrievalTransport, 0, len(resp.Protocols))
+	for _, proto := range resp.Protocols {
+		rt := observed.RetrievalTransport{
+			Name:      proto.Name,
+			Addresses: make([]string, 0, len(proto.Addresses)),
+		}
+		for _, a := range proto.Addresses {
+			ma, err := multiaddr.NewMultiaddrBytes(a)
+			if err != nil {
+				log.Debugw("miner advertised invalid transport address", "peer", p, "transport", proto.Name, "error", err)
+				continue
+			}
+			rt.Addresses = append(rt.Addresses, ma.String())
+		}
+		out = append(out, rt)
+	}
+	return out, nil
+}
+
+func getMinerAddrInfo(id peer.ID, info lapi.MinerInfo) (*peer.AddrInfo, error) {
+	var maddrs []multiaddr.Multiaddr
+	for _, m := range info.Multiaddrs {
+		ma, err := multiaddr.NewMultiaddrBytes(m)
+		if err != nil {
+			return nil, fmt.Errorf("miner had invalid multiaddrs in their info: %w", err)
+		}
+		maddrs = append(maddrs, ma)
+	}
+	if len(maddrs) == 0 {
+		return nil, fmt.Errorf("miner has no multiaddrs set on-chain")
+	}
+	return &peer.AddrInfo{
+		ID:    id,
+		Addrs: maddrs,
+	}, nil
+}
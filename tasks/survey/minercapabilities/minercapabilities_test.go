@@ -0,0 +1,40 @@
+package minercapabilities
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+
+	observed "github.com/filecoin-project/lily/model/surveyed"
+)
+
+func TestDecodeTransports(t *testing.T) {
+	// {"Protocols": [
+	//   {"Name": "http", "Addresses": [/ip4/1.2.3.4/tcp/80/http, <invalid>]},
+	//   {"Name": "libp2p", "Addresses": [/ip4/127.0.0.1/tcp/4001]},
+	// ]}
+	raw, err := hex.DecodeString("a16950726f746f636f6c7382a2644e616d65646874747069416464726573736573824a0401020304060050e00341ffa2644e616d65666c6962703270694164647265737365738148047f000001060fa1")
+	require.NoError(t, err)
+
+	transports, err := decodeTransports(peer.ID("miner"), raw)
+	require.NoError(t, err)
+	require.Equal(t, []observed.RetrievalTransport{
+		{Name: "http", Addresses: []string{"/ip4/1.2.3.4/tcp/80/http"}},
+		{Name: "libp2p", Addresses: []string{"/ip4/127.0.0.1/tcp/4001"}},
+	}, transports)
+
+	// no transports
+	transports, err = decodeTransports(peer.ID("miner"), []byte{0xa1, 0x69, 'P', 'r', 'o', 't', 'o', 'c', 'o', 'l', 's', 0x80})
+	require.NoError(t, err)
+	require.Empty(t, transports)
+
+	// truncated response
+	_, err = decodeTransports(peer.ID("miner"), raw[:len(raw)-4])
+	require.Error(t, err)
+
+	// not a transports response
+	_, err = decodeTransports(peer.ID("miner"), []byte{0x82, 0x01, 0x02})
+	require.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package peertopology
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	observed "github.com/filecoin-project/lily/model/surveyed"
+)
+
+// geoIPRecord holds the fields of the GeoLite2 ASN and Country databases recorded for a peer.
+type geoIPRecord struct {
+	ASN            uint64 `maxminddb:"autonomous_system_number"`
+	ASOrganization string `maxminddb:"autonomous_system_organization"`
+	Country        struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// loadGeoIP opens the MaxMind DB files of the task the first time the task is processed.
+func (t *Task) loadGeoIP() error {
+	if t.loaded {
+		return t.geoIPErr
+	}
+	t.loaded = true
+
+	for _, path := range t.geoIPPaths {
+		r, err := maxminddb.Open(path)
+		if err != nil {
+			t.geoIPErr = fmt.Errorf("opening geoip database %s: %w", path, err)
+			return t.geoIPErr
+		}
+		t.geoIP = append(t.geoIP, r)
+	}
+	return nil
+}
+
+// closeGeoIP closes the MaxMind DB files of the task, they are opened again if the task is processed.
+func (t *Task) closeGeoIP() error {
+	var firstErr error
+	for _, r := range t.geoIP {
+		if err := r.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	t.geoIP = nil
+	t.geoIPErr = nil
+	t.loaded = false
+	return firstErr
+}
+
+// locate sets the ASN and country of `obs` from the first GeoIP database with a record containing each.
+func (t *Task) locate(ip net.IP, obs *observed.PeerTopology) {
+	for _, db := range t.geoIP {
+		var rec geoIPRecord
+		if err := db.Lookup(ip, &rec); err != nil {
+			log.Debugw("failed to lookup ip", "ip", ip, "error", err)
+			continue
+		}
+		if rec.ASN != 0 && obs.ASN == 0 {
+			obs.ASN = int64(rec.ASN)
+			obs.ASOrganization = rec.ASOrganization
+		}
+		if rec.Country.ISOCode != "" && obs.Country == "" {
+			obs.Country = rec.Country.ISOCode
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package peertopology
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	observed "github.com/filecoin-project/lily/model/surveyed"
+)
+
+const (
+	// mmdbMetadataMarker separates the search tree and data section of a MaxMind DB file from its metadata.
+	mmdbMetadataMarker = "\xAB\xCD\xEFMaxMind.com"
+	// mmdbDataSectionSeparatorSize is the number of zero bytes between the search tree and the data section.
+	mmdbDataSectionSeparatorSize = 16
+)
+
+// testMMDB builds an IPv4 database with 24 bit records containing a single record for 1.0.0.0/8.
+func testMMDB() []byte {
+	const nodeCount = 8
+	org := strings.Repeat("x", 40)
+
+	var data []byte
+	// "AU" at offset 0, referenced by a pointer from the record.
+	data = append(data, 2<<5|2, 'A', 'U')
+	recordOffset := len(data)
+	data = append(data, 7<<5|3)
+	data = append(data, mmdbTestString("autonomous_system_number")...)
+	data = append(data, 6<<5|2, 0x34, 0x17)
+	data = append(data, mmdbTestString("autonomous_system_organization")...)
+	data = append(data, mmdbTestString(org)...)
+	data = append(data, mmdbTestString("country")...)
+	data = append(data, 7<<5|1)
+	data = append(data, mmdbTestString("iso_code")...)
+	data = append(data, 1<<5, 0x00)
+
+	// 1.0.0.0/8 is seven zero bits followed by a one bit.
+	var tree []byte
+	for i := 0; i < nodeCount; i++ {
+		left, right := uint32(i+1), uint32(nodeCount)
+		if i == nodeCount-1 {
+			left, right = nodeCount, uint32(nodeCount+mmdbDataSectionSeparatorSize+recordOffset)
+		}
+		tree = append(tree, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+	}
+
+	var buf []byte
+	buf = append(buf, tree...)
+	buf = append(buf, make([]byte, mmdbDataSectionSeparatorSize)...)
+	buf = append(buf, data...)
+	buf = append(buf, mmdbMetadataMarker...)
+	buf = append(buf, 7<<5|3)
+	buf = append(buf, mmdbTestString("node_count")...)
+	buf = append(buf, 6<<5|1, nodeCount)
+	buf = append(buf, mmdbTestString("record_size")...)
+	buf = append(buf, 5<<5|1, 24)
+	buf = append(buf, mmdbTestString("ip_version")...)
+	buf = append(buf, 5<<5|1, 4)
+	return buf
+}
+
+func mmdbTestString(s string) []byte {
+	if len(s) < 29 {
+		return append([]byte{2<<5 | byte(len(s))}, s...)
+	}
+	return append([]byte{2<<5 | 29, byte(len(s) - 29)}, s...)
+}
+
+func TestLocate(t *testing.T) {
+	r, err := maxminddb.FromBytes(testMMDB())
+	require.NoError(t, err)
+	task := &Task{geoIP: []*maxminddb.Reader{r}}
+
+	obs := &observed.PeerTopology{}
+	task.locate(net.ParseIP("1.2.3.4"), obs)
+	assert.Equal(t, int64(13335), obs.ASN)
+	assert.Equal(t, strings.Repeat("x", 40), obs.ASOrganization)
+	assert.Equal(t, "AU", obs.Country)
+
+	// addresses without a record are left unlocated
+	for _, ip := range []string{"2.2.3.4", "2001:db8::1"} {
+		obs := &observed.PeerTopology{}
+		task.locate(net.ParseIP(ip), obs)
+		assert.Equal(t, &observed.PeerTopology{}, obs, ip)
+	}
+
+	_, err = maxminddb.FromBytes([]byte("not a database"))
+	assert.Error(t, err)
+}
+
+func TestLoadGeoIP(t *testing.T) {
+	task := NewTask(nil, []string{t.TempDir() + "/missing.mmdb"})
+	require.Error(t, task.loadGeoIP())
+	// the error is reported until the task is closed
+	require.Error(t, task.loadGeoIP())
+	require.NoError(t, task.Close())
+
+	require.NoError(t, NewTask(nil, nil).loadGeoIP())
+}
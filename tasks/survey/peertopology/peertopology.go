@@ -0,0 +1,117 @@
+package peertopology
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/filecoin-project/lily/model"
+	observed "github.com/filecoin-project/lily/model/surveyed"
+
+	lapi "github.com/filecoin-project/lotus/api"
+)
+
+var log = logging.Logger("lily/tasks/peertopology")
+
+type API interface {
+	ID(ctx context.Context) (peer.ID, error)
+	Host() host.Host
+	NetPeers(context.Context) ([]peer.AddrInfo, error)
+	NetPeerInfo(context.Context, peer.ID) (*lapi.ExtendedPeerInfo, error)
+}
+
+// NewTask returns a task recording the peers connected to the surveyer. `geoIPPaths` are MaxMind DB files, such as
+// GeoLite2-ASN.mmdb and GeoLite2-Country.mmdb, used to resolve the ASN and country of observed peers. Without them
+// the ASN and country of peers are not recorded.
+func NewTask(api API, geoIPPaths []string) *Task {
+	return &Task{
+		api:        api,
+		geoIPPaths: geoIPPaths,
+	}
+}
+
+// Task records the addresses, location and connection of every peer connected to the surveyer.
+type Task struct {
+	api API
+
+	geoIPPaths []string
+	geoIP      []*maxminddb.Reader
+	geoIPErr   error
+	loaded     bool
+}
+
+func (t *Task) Process(ctx context.Context) (model.Persistable, error) {
+	if err := t.loadGeoIP(); err != nil {
+		return nil, err
+	}
+
+	pid, err := t.api.ID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get peer id: %w", err)
+	}
+
+	peers, err := t.api.NetPeers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get peers: %w", err)
+	}
+
+	start := time.Now()
+	h := t.api.Host()
+	seen := make(map[peer.ID]struct{}, len(peers))
+	out := make(observed.PeerTopologyList, 0, len(peers))
+
+	for _, p := range peers {
+		if _, ok := seen[p.ID]; ok {
+			continue
+		}
+		seen[p.ID] = struct{}{}
+
+		obs := &observed.PeerTopology{
+			ObservedAt:     start,
+			SurveyerPeerID: pid.String(),
+			PeerID:         p.ID.String(),
+		}
+
+		info, err := t.api.NetPeerInfo(ctx, p.ID)
+		if err != nil {
+			log.Debugw("failed to get peer info", "peer", p.ID, "error", err)
+		} else {
+			obs.Agent = info.Agent
+			obs.Multiaddrs = info.Addrs
+		}
+
+		// prefer the address we are connected to the peer on, falling back to the addresses known for the peer.
+		var remote multiaddr.Multiaddr
+		if conns := h.Network().ConnsToPeer(p.ID); len(conns) > 0 {
+			obs.Direction = strings.ToLower(conns[0].Stat().Direction.String())
+			remote = conns[0].RemoteMultiaddr()
+		} else if len(p.Addrs) > 0 {
+			remote = p.Addrs[0]
+		}
+		if remote != nil {
+			if ip, err := manet.ToIP(remote); err == nil {
+				obs.IP = ip.String()
+				t.locate(ip, obs)
+			}
+		}
+
+		obs.LatencyMs = h.Peerstore().LatencyEWMA(p.ID).Milliseconds()
+
+		out = append(out, obs)
+	}
+
+	log.Infow("peer topology survey complete", "duration", time.Since(start), "peers", len(out))
+	return out, nil
+}
+
+func (t *Task) Close() error {
+	return t.closeGeoIP()
+}